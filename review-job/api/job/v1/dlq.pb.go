@@ -0,0 +1,280 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v4.25.3
+// source: job/v1/dlq.proto
+
+package v1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetDeadLetterStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeadLetterStatsRequest) Reset() {
+	*x = GetDeadLetterStatsRequest{}
+	mi := &file_job_v1_dlq_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeadLetterStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeadLetterStatsRequest) ProtoMessage() {}
+
+func (x *GetDeadLetterStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_dlq_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeadLetterStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetDeadLetterStatsRequest) Descriptor() ([]byte, []int) {
+	return file_job_v1_dlq_proto_rawDescGZIP(), []int{0}
+}
+
+type GetDeadLetterStatsReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 死信 topic，未配置时为空，其余字段都为 0
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// topic 中现存的消息数
+	Total int64 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// 还没有被 replay-dlq 重放的消息数
+	Pending int64 `protobuf:"varint,3,opt,name=pending,proto3" json:"pending,omitempty"`
+	// 本进程启动以来按原因（unmarshal、convert、rejected）写入的消息数
+	Published     map[string]int64                     `protobuf:"bytes,4,rep,name=published,proto3" json:"published,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Partitions    []*GetDeadLetterStatsReply_Partition `protobuf:"bytes,5,rep,name=partitions,proto3" json:"partitions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeadLetterStatsReply) Reset() {
+	*x = GetDeadLetterStatsReply{}
+	mi := &file_job_v1_dlq_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeadLetterStatsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeadLetterStatsReply) ProtoMessage() {}
+
+func (x *GetDeadLetterStatsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_dlq_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeadLetterStatsReply.ProtoReflect.Descriptor instead.
+func (*GetDeadLetterStatsReply) Descriptor() ([]byte, []int) {
+	return file_job_v1_dlq_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetDeadLetterStatsReply) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *GetDeadLetterStatsReply) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetDeadLetterStatsReply) GetPending() int64 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *GetDeadLetterStatsReply) GetPublished() map[string]int64 {
+	if x != nil {
+		return x.Published
+	}
+	return nil
+}
+
+func (x *GetDeadLetterStatsReply) GetPartitions() []*GetDeadLetterStatsReply_Partition {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+// 每个分区的消息数量
+type GetDeadLetterStatsReply_Partition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Partition     int32                  `protobuf:"varint,1,opt,name=partition,proto3" json:"partition,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Pending       int64                  `protobuf:"varint,3,opt,name=pending,proto3" json:"pending,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeadLetterStatsReply_Partition) Reset() {
+	*x = GetDeadLetterStatsReply_Partition{}
+	mi := &file_job_v1_dlq_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeadLetterStatsReply_Partition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeadLetterStatsReply_Partition) ProtoMessage() {}
+
+func (x *GetDeadLetterStatsReply_Partition) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_dlq_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeadLetterStatsReply_Partition.ProtoReflect.Descriptor instead.
+func (*GetDeadLetterStatsReply_Partition) Descriptor() ([]byte, []int) {
+	return file_job_v1_dlq_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *GetDeadLetterStatsReply_Partition) GetPartition() int32 {
+	if x != nil {
+		return x.Partition
+	}
+	return 0
+}
+
+func (x *GetDeadLetterStatsReply_Partition) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetDeadLetterStatsReply_Partition) GetPending() int64 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+var File_job_v1_dlq_proto protoreflect.FileDescriptor
+
+const file_job_v1_dlq_proto_rawDesc = "" +
+	"\n" +
+	"\x10job/v1/dlq.proto\x12\x06job.v1\x1a\x1cgoogle/api/annotations.proto\"\x1b\n" +
+	"\x19GetDeadLetterStatsRequest\"\x91\x03\n" +
+	"\x17GetDeadLetterStatsReply\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x18\n" +
+	"\apending\x18\x03 \x01(\x03R\apending\x12L\n" +
+	"\tpublished\x18\x04 \x03(\v2..job.v1.GetDeadLetterStatsReply.PublishedEntryR\tpublished\x12I\n" +
+	"\n" +
+	"partitions\x18\x05 \x03(\v2).job.v1.GetDeadLetterStatsReply.PartitionR\n" +
+	"partitions\x1aY\n" +
+	"\tPartition\x12\x1c\n" +
+	"\tpartition\x18\x01 \x01(\x05R\tpartition\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x18\n" +
+	"\apending\x18\x03 \x01(\x03R\apending\x1a<\n" +
+	"\x0ePublishedEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x012}\n" +
+	"\n" +
+	"DeadLetter\x12o\n" +
+	"\x12GetDeadLetterStats\x12!.job.v1.GetDeadLetterStatsRequest\x1a\x1f.job.v1.GetDeadLetterStatsReply\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/dlq/statsB\x1aZ\x18review-job/api/job/v1;v1b\x06proto3"
+
+var (
+	file_job_v1_dlq_proto_rawDescOnce sync.Once
+	file_job_v1_dlq_proto_rawDescData []byte
+)
+
+func file_job_v1_dlq_proto_rawDescGZIP() []byte {
+	file_job_v1_dlq_proto_rawDescOnce.Do(func() {
+		file_job_v1_dlq_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_job_v1_dlq_proto_rawDesc), len(file_job_v1_dlq_proto_rawDesc)))
+	})
+	return file_job_v1_dlq_proto_rawDescData
+}
+
+var file_job_v1_dlq_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_job_v1_dlq_proto_goTypes = []any{
+	(*GetDeadLetterStatsRequest)(nil),         // 0: job.v1.GetDeadLetterStatsRequest
+	(*GetDeadLetterStatsReply)(nil),           // 1: job.v1.GetDeadLetterStatsReply
+	(*GetDeadLetterStatsReply_Partition)(nil), // 2: job.v1.GetDeadLetterStatsReply.Partition
+	nil, // 3: job.v1.GetDeadLetterStatsReply.PublishedEntry
+}
+var file_job_v1_dlq_proto_depIdxs = []int32{
+	3, // 0: job.v1.GetDeadLetterStatsReply.published:type_name -> job.v1.GetDeadLetterStatsReply.PublishedEntry
+	2, // 1: job.v1.GetDeadLetterStatsReply.partitions:type_name -> job.v1.GetDeadLetterStatsReply.Partition
+	0, // 2: job.v1.DeadLetter.GetDeadLetterStats:input_type -> job.v1.GetDeadLetterStatsRequest
+	1, // 3: job.v1.DeadLetter.GetDeadLetterStats:output_type -> job.v1.GetDeadLetterStatsReply
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_job_v1_dlq_proto_init() }
+func file_job_v1_dlq_proto_init() {
+	if File_job_v1_dlq_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_job_v1_dlq_proto_rawDesc), len(file_job_v1_dlq_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_job_v1_dlq_proto_goTypes,
+		DependencyIndexes: file_job_v1_dlq_proto_depIdxs,
+		MessageInfos:      file_job_v1_dlq_proto_msgTypes,
+	}.Build()
+	File_job_v1_dlq_proto = out.File
+	file_job_v1_dlq_proto_goTypes = nil
+	file_job_v1_dlq_proto_depIdxs = nil
+}
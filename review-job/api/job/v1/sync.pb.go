@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v4.25.3
+// source: job/v1/sync.proto
+
+package v1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetSyncStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSyncStatsRequest) Reset() {
+	*x = GetSyncStatsRequest{}
+	mi := &file_job_v1_sync_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSyncStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSyncStatsRequest) ProtoMessage() {}
+
+func (x *GetSyncStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_sync_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSyncStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetSyncStatsRequest) Descriptor() ([]byte, []int) {
+	return file_job_v1_sync_proto_rawDescGZIP(), []int{0}
+}
+
+type GetSyncStatsReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 有处理规则的表，按表名统计转换为写入操作的行数
+	Routed map[string]int64 `protobuf:"bytes,1,rep,name=routed,proto3" json:"routed,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// 没有处理规则的表，按表名统计被忽略的行数
+	Ignored map[string]int64 `protobuf:"bytes,2,rep,name=ignored,proto3" json:"ignored,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// 按表名统计收到的 DDL 次数，DDL 不作为数据写入索引
+	Ddl map[string]int64 `protobuf:"bytes,3,rep,name=ddl,proto3" json:"ddl,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// 最近一次检查时表结构与文档结构不一致的表
+	DriftedTables []string `protobuf:"bytes,4,rep,name=drifted_tables,json=driftedTables,proto3" json:"drifted_tables,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSyncStatsReply) Reset() {
+	*x = GetSyncStatsReply{}
+	mi := &file_job_v1_sync_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSyncStatsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSyncStatsReply) ProtoMessage() {}
+
+func (x *GetSyncStatsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_sync_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSyncStatsReply.ProtoReflect.Descriptor instead.
+func (*GetSyncStatsReply) Descriptor() ([]byte, []int) {
+	return file_job_v1_sync_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetSyncStatsReply) GetRouted() map[string]int64 {
+	if x != nil {
+		return x.Routed
+	}
+	return nil
+}
+
+func (x *GetSyncStatsReply) GetIgnored() map[string]int64 {
+	if x != nil {
+		return x.Ignored
+	}
+	return nil
+}
+
+func (x *GetSyncStatsReply) GetDdl() map[string]int64 {
+	if x != nil {
+		return x.Ddl
+	}
+	return nil
+}
+
+func (x *GetSyncStatsReply) GetDriftedTables() []string {
+	if x != nil {
+		return x.DriftedTables
+	}
+	return nil
+}
+
+var File_job_v1_sync_proto protoreflect.FileDescriptor
+
+const file_job_v1_sync_proto_rawDesc = "" +
+	"\n" +
+	"\x11job/v1/sync.proto\x12\x06job.v1\x1a\x1cgoogle/api/annotations.proto\"\x15\n" +
+	"\x13GetSyncStatsRequest\"\xa0\x03\n" +
+	"\x11GetSyncStatsReply\x12=\n" +
+	"\x06routed\x18\x01 \x03(\v2%.job.v1.GetSyncStatsReply.RoutedEntryR\x06routed\x12@\n" +
+	"\aignored\x18\x02 \x03(\v2&.job.v1.GetSyncStatsReply.IgnoredEntryR\aignored\x124\n" +
+	"\x03ddl\x18\x03 \x03(\v2\".job.v1.GetSyncStatsReply.DdlEntryR\x03ddl\x12%\n" +
+	"\x0edrifted_tables\x18\x04 \x03(\tR\rdriftedTables\x1a9\n" +
+	"\vRoutedEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1a:\n" +
+	"\fIgnoredEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1a6\n" +
+	"\bDdlEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x012f\n" +
+	"\x04Sync\x12^\n" +
+	"\fGetSyncStats\x12\x1b.job.v1.GetSyncStatsRequest\x1a\x19.job.v1.GetSyncStatsReply\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/sync/statsB\x1aZ\x18review-job/api/job/v1;v1b\x06proto3"
+
+var (
+	file_job_v1_sync_proto_rawDescOnce sync.Once
+	file_job_v1_sync_proto_rawDescData []byte
+)
+
+func file_job_v1_sync_proto_rawDescGZIP() []byte {
+	file_job_v1_sync_proto_rawDescOnce.Do(func() {
+		file_job_v1_sync_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_job_v1_sync_proto_rawDesc), len(file_job_v1_sync_proto_rawDesc)))
+	})
+	return file_job_v1_sync_proto_rawDescData
+}
+
+var file_job_v1_sync_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_job_v1_sync_proto_goTypes = []any{
+	(*GetSyncStatsRequest)(nil), // 0: job.v1.GetSyncStatsRequest
+	(*GetSyncStatsReply)(nil),   // 1: job.v1.GetSyncStatsReply
+	nil,                         // 2: job.v1.GetSyncStatsReply.RoutedEntry
+	nil,                         // 3: job.v1.GetSyncStatsReply.IgnoredEntry
+	nil,                         // 4: job.v1.GetSyncStatsReply.DdlEntry
+}
+var file_job_v1_sync_proto_depIdxs = []int32{
+	2, // 0: job.v1.GetSyncStatsReply.routed:type_name -> job.v1.GetSyncStatsReply.RoutedEntry
+	3, // 1: job.v1.GetSyncStatsReply.ignored:type_name -> job.v1.GetSyncStatsReply.IgnoredEntry
+	4, // 2: job.v1.GetSyncStatsReply.ddl:type_name -> job.v1.GetSyncStatsReply.DdlEntry
+	0, // 3: job.v1.Sync.GetSyncStats:input_type -> job.v1.GetSyncStatsRequest
+	1, // 4: job.v1.Sync.GetSyncStats:output_type -> job.v1.GetSyncStatsReply
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_job_v1_sync_proto_init() }
+func file_job_v1_sync_proto_init() {
+	if File_job_v1_sync_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_job_v1_sync_proto_rawDesc), len(file_job_v1_sync_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_job_v1_sync_proto_goTypes,
+		DependencyIndexes: file_job_v1_sync_proto_depIdxs,
+		MessageInfos:      file_job_v1_sync_proto_msgTypes,
+	}.Build()
+	File_job_v1_sync_proto = out.File
+	file_job_v1_sync_proto_goTypes = nil
+	file_job_v1_sync_proto_depIdxs = nil
+}
@@ -0,0 +1,71 @@
+// Code generated by protoc-gen-go-http. DO NOT EDIT.
+// versions:
+// protoc-gen-go-http v2.1.3
+
+package v1
+
+import (
+	context "context"
+	http "github.com/go-kratos/kratos/v2/transport/http"
+	binding "github.com/go-kratos/kratos/v2/transport/http/binding"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the kratos package it is being compiled against.
+var _ = new(context.Context)
+var _ = binding.EncodeURL
+
+const _ = http.SupportPackageIsVersion1
+
+type SyncHTTPServer interface {
+	GetSyncStats(context.Context, *GetSyncStatsRequest) (*GetSyncStatsReply, error)
+}
+
+func RegisterSyncHTTPServer(s *http.Server, srv SyncHTTPServer) {
+	r := s.Route("/")
+	r.GET("/v1/sync/stats", _Sync_GetSyncStats0_HTTP_Handler(srv))
+}
+
+func _Sync_GetSyncStats0_HTTP_Handler(srv SyncHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in GetSyncStatsRequest
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, "/job.v1.Sync/GetSyncStats")
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetSyncStats(ctx, req.(*GetSyncStatsRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*GetSyncStatsReply)
+		return ctx.Result(200, reply)
+	}
+}
+
+type SyncHTTPClient interface {
+	GetSyncStats(ctx context.Context, req *GetSyncStatsRequest, opts ...http.CallOption) (rsp *GetSyncStatsReply, err error)
+}
+
+type SyncHTTPClientImpl struct {
+	cc *http.Client
+}
+
+func NewSyncHTTPClient(client *http.Client) SyncHTTPClient {
+	return &SyncHTTPClientImpl{client}
+}
+
+func (c *SyncHTTPClientImpl) GetSyncStats(ctx context.Context, in *GetSyncStatsRequest, opts ...http.CallOption) (*GetSyncStatsReply, error) {
+	var out GetSyncStatsReply
+	pattern := "/v1/sync/stats"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation("/job.v1.Sync/GetSyncStats"))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, err
+}
@@ -0,0 +1,588 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v4.25.3
+// source: job/v1/verify.proto
+
+package v1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StartVerifyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 主键范围，开启分表时用于每张分表，为 0 时取表中的最小、最大值
+	MinId int64 `protobuf:"varint,1,opt,name=min_id,json=minId,proto3" json:"min_id,omitempty"`
+	MaxId int64 `protobuf:"varint,2,opt,name=max_id,json=maxId,proto3" json:"max_id,omitempty"`
+	// 抽样比例，范围内每个主键被抽中的概率；0 或 1 表示全量核对
+	Sample float64 `protobuf:"fixed64,3,opt,name=sample,proto3" json:"sample,omitempty"`
+	// 按 MySQL 中的数据修复不一致的文档
+	Repair        bool `protobuf:"varint,4,opt,name=repair,proto3" json:"repair,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartVerifyRequest) Reset() {
+	*x = StartVerifyRequest{}
+	mi := &file_job_v1_verify_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartVerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartVerifyRequest) ProtoMessage() {}
+
+func (x *StartVerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_verify_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartVerifyRequest.ProtoReflect.Descriptor instead.
+func (*StartVerifyRequest) Descriptor() ([]byte, []int) {
+	return file_job_v1_verify_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StartVerifyRequest) GetMinId() int64 {
+	if x != nil {
+		return x.MinId
+	}
+	return 0
+}
+
+func (x *StartVerifyRequest) GetMaxId() int64 {
+	if x != nil {
+		return x.MaxId
+	}
+	return 0
+}
+
+func (x *StartVerifyRequest) GetSample() float64 {
+	if x != nil {
+		return x.Sample
+	}
+	return 0
+}
+
+func (x *StartVerifyRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+type GetVerifyReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVerifyReportRequest) Reset() {
+	*x = GetVerifyReportRequest{}
+	mi := &file_job_v1_verify_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVerifyReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVerifyReportRequest) ProtoMessage() {}
+
+func (x *GetVerifyReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_verify_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVerifyReportRequest.ProtoReflect.Descriptor instead.
+func (*GetVerifyReportRequest) Descriptor() ([]byte, []int) {
+	return file_job_v1_verify_proto_rawDescGZIP(), []int{1}
+}
+
+// ReviewState 参与比对的字段
+type ReviewState struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Version  int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Status   int32                  `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"`
+	UpdateAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=update_at,json=updateAt,proto3" json:"update_at,omitempty"`
+	// 行已被逻辑删除
+	Deleted       bool `protobuf:"varint,4,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewState) Reset() {
+	*x = ReviewState{}
+	mi := &file_job_v1_verify_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewState) ProtoMessage() {}
+
+func (x *ReviewState) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_verify_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewState.ProtoReflect.Descriptor instead.
+func (*ReviewState) Descriptor() ([]byte, []int) {
+	return file_job_v1_verify_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ReviewState) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ReviewState) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *ReviewState) GetUpdateAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateAt
+	}
+	return nil
+}
+
+func (x *ReviewState) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type Mismatch struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// missing：行存在，索引中没有文档；stale：version、status 或 update_at 不一致；extra：行已删除，索引中仍有文档
+	Kind     string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Id       int64  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	ReviewId int64  `protobuf:"varint,3,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
+	// 行不存在时为空
+	Row *ReviewState `protobuf:"bytes,4,opt,name=row,proto3" json:"row,omitempty"`
+	// 文档不存在时为空
+	Doc      *ReviewState `protobuf:"bytes,5,opt,name=doc,proto3" json:"doc,omitempty"`
+	Repaired bool         `protobuf:"varint,6,opt,name=repaired,proto3" json:"repaired,omitempty"`
+	// 行所在的物理表，id 只在这张表内唯一
+	Table         string `protobuf:"bytes,7,opt,name=table,proto3" json:"table,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mismatch) Reset() {
+	*x = Mismatch{}
+	mi := &file_job_v1_verify_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mismatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mismatch) ProtoMessage() {}
+
+func (x *Mismatch) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_verify_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mismatch.ProtoReflect.Descriptor instead.
+func (*Mismatch) Descriptor() ([]byte, []int) {
+	return file_job_v1_verify_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Mismatch) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Mismatch) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Mismatch) GetReviewId() int64 {
+	if x != nil {
+		return x.ReviewId
+	}
+	return 0
+}
+
+func (x *Mismatch) GetRow() *ReviewState {
+	if x != nil {
+		return x.Row
+	}
+	return nil
+}
+
+func (x *Mismatch) GetDoc() *ReviewState {
+	if x != nil {
+		return x.Doc
+	}
+	return nil
+}
+
+func (x *Mismatch) GetRepaired() bool {
+	if x != nil {
+		return x.Repaired
+	}
+	return false
+}
+
+func (x *Mismatch) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+type VerifyReport struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// idle、running、done、failed
+	State  string  `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	MinId  int64   `protobuf:"varint,2,opt,name=min_id,json=minId,proto3" json:"min_id,omitempty"`
+	MaxId  int64   `protobuf:"varint,3,opt,name=max_id,json=maxId,proto3" json:"max_id,omitempty"`
+	Sample float64 `protobuf:"fixed64,4,opt,name=sample,proto3" json:"sample,omitempty"`
+	Repair bool    `protobuf:"varint,5,opt,name=repair,proto3" json:"repair,omitempty"`
+	// 已核对的行数，以及 update_at 在最近一段时间内、binlog 可能还没有同步而跳过的行数
+	Checked  int64 `protobuf:"varint,6,opt,name=checked,proto3" json:"checked,omitempty"`
+	Skipped  int64 `protobuf:"varint,7,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Missing  int64 `protobuf:"varint,8,opt,name=missing,proto3" json:"missing,omitempty"`
+	Stale    int64 `protobuf:"varint,9,opt,name=stale,proto3" json:"stale,omitempty"`
+	Extra    int64 `protobuf:"varint,10,opt,name=extra,proto3" json:"extra,omitempty"`
+	Repaired int64 `protobuf:"varint,11,opt,name=repaired,proto3" json:"repaired,omitempty"`
+	// 最多保留前 100 条不一致
+	Mismatches []*Mismatch `protobuf:"bytes,12,rep,name=mismatches,proto3" json:"mismatches,omitempty"`
+	// 当前核对到的主键，开启分表时为 table 中的主键
+	Cursor   int64                  `protobuf:"varint,13,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Error    string                 `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`
+	StartAt  *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
+	FinishAt *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=finish_at,json=finishAt,proto3" json:"finish_at,omitempty"`
+	// 当前核对的物理表，分表按编号依次核对
+	Table         string `protobuf:"bytes,17,opt,name=table,proto3" json:"table,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyReport) Reset() {
+	*x = VerifyReport{}
+	mi := &file_job_v1_verify_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyReport) ProtoMessage() {}
+
+func (x *VerifyReport) ProtoReflect() protoreflect.Message {
+	mi := &file_job_v1_verify_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyReport.ProtoReflect.Descriptor instead.
+func (*VerifyReport) Descriptor() ([]byte, []int) {
+	return file_job_v1_verify_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VerifyReport) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *VerifyReport) GetMinId() int64 {
+	if x != nil {
+		return x.MinId
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetMaxId() int64 {
+	if x != nil {
+		return x.MaxId
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetSample() float64 {
+	if x != nil {
+		return x.Sample
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+func (x *VerifyReport) GetChecked() int64 {
+	if x != nil {
+		return x.Checked
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetSkipped() int64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetMissing() int64 {
+	if x != nil {
+		return x.Missing
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetStale() int64 {
+	if x != nil {
+		return x.Stale
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetExtra() int64 {
+	if x != nil {
+		return x.Extra
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetRepaired() int64 {
+	if x != nil {
+		return x.Repaired
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetMismatches() []*Mismatch {
+	if x != nil {
+		return x.Mismatches
+	}
+	return nil
+}
+
+func (x *VerifyReport) GetCursor() int64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *VerifyReport) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *VerifyReport) GetStartAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartAt
+	}
+	return nil
+}
+
+func (x *VerifyReport) GetFinishAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishAt
+	}
+	return nil
+}
+
+func (x *VerifyReport) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+var File_job_v1_verify_proto protoreflect.FileDescriptor
+
+const file_job_v1_verify_proto_rawDesc = "" +
+	"\n" +
+	"\x13job/v1/verify.proto\x12\x06job.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"r\n" +
+	"\x12StartVerifyRequest\x12\x15\n" +
+	"\x06min_id\x18\x01 \x01(\x03R\x05minId\x12\x15\n" +
+	"\x06max_id\x18\x02 \x01(\x03R\x05maxId\x12\x16\n" +
+	"\x06sample\x18\x03 \x01(\x01R\x06sample\x12\x16\n" +
+	"\x06repair\x18\x04 \x01(\bR\x06repair\"\x18\n" +
+	"\x16GetVerifyReportRequest\"\x92\x01\n" +
+	"\vReviewState\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\x05R\x06status\x127\n" +
+	"\tupdate_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bupdateAt\x12\x18\n" +
+	"\adeleted\x18\x04 \x01(\bR\adeleted\"\xcb\x01\n" +
+	"\bMismatch\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x1b\n" +
+	"\treview_id\x18\x03 \x01(\x03R\breviewId\x12%\n" +
+	"\x03row\x18\x04 \x01(\v2\x13.job.v1.ReviewStateR\x03row\x12%\n" +
+	"\x03doc\x18\x05 \x01(\v2\x13.job.v1.ReviewStateR\x03doc\x12\x1a\n" +
+	"\brepaired\x18\x06 \x01(\bR\brepaired\x12\x14\n" +
+	"\x05table\x18\a \x01(\tR\x05table\"\xfe\x03\n" +
+	"\fVerifyReport\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12\x15\n" +
+	"\x06min_id\x18\x02 \x01(\x03R\x05minId\x12\x15\n" +
+	"\x06max_id\x18\x03 \x01(\x03R\x05maxId\x12\x16\n" +
+	"\x06sample\x18\x04 \x01(\x01R\x06sample\x12\x16\n" +
+	"\x06repair\x18\x05 \x01(\bR\x06repair\x12\x18\n" +
+	"\achecked\x18\x06 \x01(\x03R\achecked\x12\x18\n" +
+	"\askipped\x18\a \x01(\x03R\askipped\x12\x18\n" +
+	"\amissing\x18\b \x01(\x03R\amissing\x12\x14\n" +
+	"\x05stale\x18\t \x01(\x03R\x05stale\x12\x14\n" +
+	"\x05extra\x18\n" +
+	" \x01(\x03R\x05extra\x12\x1a\n" +
+	"\brepaired\x18\v \x01(\x03R\brepaired\x120\n" +
+	"\n" +
+	"mismatches\x18\f \x03(\v2\x10.job.v1.MismatchR\n" +
+	"mismatches\x12\x16\n" +
+	"\x06cursor\x18\r \x01(\x03R\x06cursor\x12\x14\n" +
+	"\x05error\x18\x0e \x01(\tR\x05error\x125\n" +
+	"\bstart_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\astartAt\x127\n" +
+	"\tfinish_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\bfinishAt\x12\x14\n" +
+	"\x05table\x18\x11 \x01(\tR\x05table2\xbd\x01\n" +
+	"\x06Verify\x12V\n" +
+	"\vStartVerify\x12\x1a.job.v1.StartVerifyRequest\x1a\x14.job.v1.VerifyReport\"\x15\x82\xd3\xe4\x93\x02\x0f:\x01*\"\n" +
+	"/v1/verify\x12[\n" +
+	"\x0fGetVerifyReport\x12\x1e.job.v1.GetVerifyReportRequest\x1a\x14.job.v1.VerifyReport\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/verifyB\x1aZ\x18review-job/api/job/v1;v1b\x06proto3"
+
+var (
+	file_job_v1_verify_proto_rawDescOnce sync.Once
+	file_job_v1_verify_proto_rawDescData []byte
+)
+
+func file_job_v1_verify_proto_rawDescGZIP() []byte {
+	file_job_v1_verify_proto_rawDescOnce.Do(func() {
+		file_job_v1_verify_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_job_v1_verify_proto_rawDesc), len(file_job_v1_verify_proto_rawDesc)))
+	})
+	return file_job_v1_verify_proto_rawDescData
+}
+
+var file_job_v1_verify_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_job_v1_verify_proto_goTypes = []any{
+	(*StartVerifyRequest)(nil),     // 0: job.v1.StartVerifyRequest
+	(*GetVerifyReportRequest)(nil), // 1: job.v1.GetVerifyReportRequest
+	(*ReviewState)(nil),            // 2: job.v1.ReviewState
+	(*Mismatch)(nil),               // 3: job.v1.Mismatch
+	(*VerifyReport)(nil),           // 4: job.v1.VerifyReport
+	(*timestamppb.Timestamp)(nil),  // 5: google.protobuf.Timestamp
+}
+var file_job_v1_verify_proto_depIdxs = []int32{
+	5, // 0: job.v1.ReviewState.update_at:type_name -> google.protobuf.Timestamp
+	2, // 1: job.v1.Mismatch.row:type_name -> job.v1.ReviewState
+	2, // 2: job.v1.Mismatch.doc:type_name -> job.v1.ReviewState
+	3, // 3: job.v1.VerifyReport.mismatches:type_name -> job.v1.Mismatch
+	5, // 4: job.v1.VerifyReport.start_at:type_name -> google.protobuf.Timestamp
+	5, // 5: job.v1.VerifyReport.finish_at:type_name -> google.protobuf.Timestamp
+	0, // 6: job.v1.Verify.StartVerify:input_type -> job.v1.StartVerifyRequest
+	1, // 7: job.v1.Verify.GetVerifyReport:input_type -> job.v1.GetVerifyReportRequest
+	4, // 8: job.v1.Verify.StartVerify:output_type -> job.v1.VerifyReport
+	4, // 9: job.v1.Verify.GetVerifyReport:output_type -> job.v1.VerifyReport
+	8, // [8:10] is the sub-list for method output_type
+	6, // [6:8] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_job_v1_verify_proto_init() }
+func file_job_v1_verify_proto_init() {
+	if File_job_v1_verify_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_job_v1_verify_proto_rawDesc), len(file_job_v1_verify_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_job_v1_verify_proto_goTypes,
+		DependencyIndexes: file_job_v1_verify_proto_depIdxs,
+		MessageInfos:      file_job_v1_verify_proto_msgTypes,
+	}.Build()
+	File_job_v1_verify_proto = out.File
+	file_job_v1_verify_proto_goTypes = nil
+	file_job_v1_verify_proto_depIdxs = nil
+}
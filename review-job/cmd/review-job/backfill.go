@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"review-job/internal/conf"
+	"review-job/internal/job"
+	"review-service/pkg/search"
+)
+
+// runBackfill 从 MySQL 全量扫描评价（含回复）或申诉的每张分表写入索引，用于 ES 数据丢失或新建索引后重建数据
+// 开启布隆过滤器时同步评价的同时写入存量的 review_id 和 store_id，从头同步完成后标记过滤器可用，与 seed-bloom 的效果相同
+// 可以与 review-job 同时运行，期间的 binlog 变更按版本号与全量数据对齐；中断后按 checkpoint 继续。
+// 使用 bleve 时索引目录不能被两个进程同时打开，需要先停止 review-job
+//
+//	review-job -conf ../../configs backfill [-index review|appeal] [-target index] [-chunk 500] [-rate 1000] [-checkpoint file] [-restart]
+func runBackfill(bc *conf.Bootstrap, logger log.Logger, args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	index := fs.String("index", job.IndexReview, "which index to backfill: review or appeal")
+	target := fs.String("target", "", "ES index to write into, defaults to the read/write alias; binlog changes only reach the alias")
+	chunk := fs.Int("chunk", 500, "rows read from MySQL per batch")
+	rate := fs.Int("rate", 1000, "max rows read per second, 0 means unlimited")
+	checkpoint := fs.String("checkpoint", "", "progress file, defaults to backfill_{index}.json")
+	restart := fs.Bool("restart", false, "ignore the checkpoint and start from the first row")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *checkpoint == "" {
+		*checkpoint = fmt.Sprintf("backfill_%s.json", *index)
+	}
+
+	tf, err := job.NewTransformer(bc.Data)
+	if err != nil {
+		return err
+	}
+	bloom, err := job.NewBloomFilter(bc.Data)
+	if err != nil {
+		return err
+	}
+	defer bloom.Close()
+	indices, cleanup, err := backfillIndices(bc, *index, *target, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	router := job.NewRouter(tf, job.NewSchemaGuard(tf, nil, &job.Alerter{}, logger), logger)
+	worker := job.NewJobWorker(nil, nil, indices, router, bloom, &job.DeadLetter{}, bc.Kafka, bc.Search, logger)
+	b, err := job.NewBackfill(bc.Data, worker, job.BackfillOption{
+		Index:      *index,
+		Target:     *target,
+		Chunk:      *chunk,
+		Rate:       *rate,
+		Checkpoint: *checkpoint,
+		Restart:    *restart,
+	}, logger)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	cp, err := b.Run(ctx)
+	if cp != nil {
+		fmt.Fprintf(os.Stdout, "backfilled %d rows into %s, %s last id %d, done %v, checkpoint %s\n", cp.Rows, *index, cp.Table, cp.LastID, cp.Done, *checkpoint)
+	}
+	return err
+}
+
+// backfillIndices 未指定 target 时与 review-job 写入相同的索引，否则写入 ES 中指定的索引
+func backfillIndices(bc *conf.Bootstrap, index, target string, logger log.Logger) (job.Indices, func(), error) {
+	if target == "" {
+		return job.NewSearchIndices(bc.Elasticsearch, bc.Search, logger)
+	}
+	if bc.Search.GetBackend() == "bleve" {
+		return nil, nil, fmt.Errorf("-target is not supported by bleve")
+	}
+	client, err := job.NewESClient(bc.Elasticsearch)
+	if err != nil {
+		return nil, nil, err
+	}
+	es := search.NewElasticsearch(client, target)
+	return job.Indices{index: es}, func() { _ = es.Close() }, nil
+}
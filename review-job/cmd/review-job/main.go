@@ -33,7 +33,7 @@ func init() {
 	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
 }
 
-func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server, js *job.JobWorker) *kratos.App {
+func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server, js *job.JobWorker, relay *job.OutboxRelay, creator *job.ReviewCreator) *kratos.App {
 	return kratos.New(
 		kratos.ID(id),
 		kratos.Name(Name),
@@ -43,7 +43,9 @@ func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server, js *job.JobWork
 		kratos.Server(
 			gs,
 			hs,
-			js, // 同步数据到 Elasticsearch
+			js,      // 同步数据到 Elasticsearch
+			relay,   // 投递发件箱中的领域事件
+			creator, // 写入异步创建的评价
 		),
 	)
 }
@@ -75,7 +77,38 @@ func main() {
 		panic(err)
 	}
 
-	app, cleanup, err := wireApp(bc.Server, bc.Kafka, bc.Elasticsearch, bc.Data, logger)
+	// 子命令：review-job -conf ../../configs reindex [-index review|appeal] [-delete-old]
+	if flag.Arg(0) == "reindex" {
+		if err := runReindex(bc.Elasticsearch, bc.Data, logger, flag.Args()[1:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+	// 子命令：review-job -conf ../../configs replay-dlq [-limit 100] [-idle 5s]
+	if flag.Arg(0) == "replay-dlq" {
+		if err := runReplayDLQ(bc.Kafka, logger, flag.Args()[1:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	// 子命令：review-job -conf ../../configs backfill [-index review|appeal] [-target index] [-rate 1000]
+	if flag.Arg(0) == "backfill" {
+		if err := runBackfill(&bc, logger, flag.Args()[1:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	// 子命令：review-job -conf ../../configs seed-bloom [-chunk 1000]
+	if flag.Arg(0) == "seed-bloom" {
+		if err := runSeedBloom(&bc, logger, flag.Args()[1:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	app, cleanup, err := wireApp(bc.Server, bc.Kafka, bc.Elasticsearch, bc.Search, bc.Data, bc.Source, bc.Outbox, bc.AsyncCreate, logger)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"review-job/internal/conf"
+	"review-job/internal/job"
+)
+
+// runReindex 按最新的索引模板重建 ES 索引并切换读写别名，从旧的普通索引迁移时按数据库时区转换时间字段
+//
+//	review-job -conf ../../configs reindex [-index review|appeal] [-delete-old]
+func runReindex(c *conf.Elasticsearch, dc *conf.Data, logger log.Logger, args []string) error {
+	fs := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	index := fs.String("index", job.IndexReview, "which index to rebuild: review or appeal")
+	deleteOld := fs.Bool("delete-old", false, "delete the previous indices, or the backup clone of a legacy index, after the alias is swapped")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tf, err := job.NewTransformer(dc)
+	if err != nil {
+		return err
+	}
+	client, err := job.NewESClient(c)
+	if err != nil {
+		return err
+	}
+	m, ok := job.NewIndexManagers(client, c, logger)[*index]
+	if !ok {
+		return fmt.Errorf("unknown index %q", *index)
+	}
+	name, err := m.Reindex(context.Background(), tf, *deleteOld)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "alias %s -> %s\n", m.Alias(), name)
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"review-job/internal/conf"
+	"review-job/internal/job"
+)
+
+// runReplayDLQ 将死信队列中的消息写回原 topic 重新处理，用于修复问题之后
+//
+//	review-job -conf ../../configs replay-dlq [-limit 100] [-idle 5s]
+func runReplayDLQ(c *conf.Kafka, logger log.Logger, args []string) error {
+	fs := flag.NewFlagSet("replay-dlq", flag.ContinueOnError)
+	limit := fs.Int64("limit", 0, "replay at most this many messages, 0 means all pending messages")
+	idle := fs.Duration("idle", 5*time.Second, "stop when no message arrives within this duration")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dlq, cleanup, err := job.NewDeadLetter(c, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	n, err := dlq.Replay(context.Background(), *limit, *idle)
+	fmt.Fprintf(os.Stdout, "replayed %d messages from %s\n", n, dlq.Topic())
+	return err
+}
@@ -6,9 +6,7 @@
 package main
 
 import (
-	"review-job/internal/biz"
 	"review-job/internal/conf"
-	"review-job/internal/data"
 	"review-job/internal/job"
 	"review-job/internal/server"
 	"review-job/internal/service"
@@ -19,6 +17,6 @@ import (
 )
 
 // wireApp init kratos application.
-func wireApp(*conf.Server, *conf.Kafka, *conf.Elasticsearch, *conf.Data, log.Logger) (*kratos.App, func(), error) {
-	panic(wire.Build(server.ProviderSet, data.ProviderSet, biz.ProviderSet, service.ProviderSet, job.ProviderSet, newApp))
+func wireApp(*conf.Server, *conf.Kafka, *conf.Elasticsearch, *conf.Search, *conf.Data, *conf.Source, *conf.Outbox, *conf.AsyncCreate, log.Logger) (*kratos.App, func(), error) {
+	panic(wire.Build(server.ProviderSet, service.ProviderSet, job.ProviderSet, newApp))
 }
@@ -9,9 +9,7 @@ package main
 import (
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/log"
-	"review-job/internal/biz"
 	"review-job/internal/conf"
-	"review-job/internal/data"
 	"review-job/internal/job"
 	"review-job/internal/server"
 	"review-job/internal/service"
@@ -24,29 +22,73 @@ import (
 // Injectors from wire.go:
 
 // wireApp init kratos application.
-func wireApp(confServer *conf.Server, kafka *conf.Kafka, elasticsearch *conf.Elasticsearch, confData *conf.Data, logger log.Logger) (*kratos.App, func(), error) {
-	dataData, cleanup, err := data.NewData(confData, logger)
+func wireApp(confServer *conf.Server, kafka *conf.Kafka, elasticsearch *conf.Elasticsearch, search *conf.Search, data *conf.Data, source *conf.Source, outbox *conf.Outbox, asyncCreate *conf.AsyncCreate, logger log.Logger) (*kratos.App, func(), error) {
+	deadLetter, cleanup, err := job.NewDeadLetter(kafka, logger)
 	if err != nil {
 		return nil, nil, err
 	}
-	greeterRepo := data.NewGreeterRepo(dataData, logger)
-	greeterUsecase := biz.NewGreeterUsecase(greeterRepo, logger)
-	greeterService := service.NewGreeterService(greeterUsecase)
-	grpcServer := server.NewGRPCServer(confServer, greeterService, logger)
-	httpServer := server.NewHTTPServer(confServer, greeterService, logger)
-	reader, err := job.NewKafkaReader(kafka)
+	deadLetterService := service.NewDeadLetterService(deadLetter)
+	transformer, err := job.NewTransformer(data)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
-	esClient, err := job.NewESClient(elasticsearch)
+	fieldUpdater, err := job.NewFieldUpdater(elasticsearch, search, logger)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
-	jobWorker := job.NewJobWorker(reader, esClient, logger)
-	app := newApp(logger, grpcServer, httpServer, jobWorker)
+	alerter, cleanup2, err := job.NewAlerter(kafka, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	schemaGuard := job.NewSchemaGuard(transformer, fieldUpdater, alerter, logger)
+	router := job.NewRouter(transformer, schemaGuard, logger)
+	syncService := service.NewSyncService(router)
+	changeSource, err := job.NewChangeSource(source, kafka, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	decoders, err := job.NewDecoders(kafka, transformer)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	indices, cleanup3, err := job.NewSearchIndices(elasticsearch, search, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	bloomFilter, err := job.NewBloomFilter(data)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	jobWorker := job.NewJobWorker(changeSource, decoders, indices, router, bloomFilter, deadLetter, kafka, search, logger)
+	verifier, cleanup4, err := job.NewVerifier(data, transformer, jobWorker, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	verifyService := service.NewVerifyService(verifier)
+	grpcServer := server.NewGRPCServer(confServer, deadLetterService, syncService, verifyService, logger)
+	httpServer := server.NewHTTPServer(confServer, deadLetterService, syncService, verifyService, logger)
+	outboxRelay := job.NewOutboxRelay(outbox, kafka, data, logger)
+	reviewCreator := job.NewReviewCreator(asyncCreate, kafka, data, transformer, deadLetter, logger)
+	app := newApp(logger, grpcServer, httpServer, jobWorker, outboxRelay, reviewCreator)
 	return app, func() {
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -28,6 +28,10 @@ type Bootstrap struct {
 	Data          *Data                  `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
 	Kafka         *Kafka                 `protobuf:"bytes,3,opt,name=kafka,proto3" json:"kafka,omitempty"`
 	Elasticsearch *Elasticsearch         `protobuf:"bytes,4,opt,name=elasticsearch,proto3" json:"elasticsearch,omitempty"`
+	Search        *Search                `protobuf:"bytes,5,opt,name=search,proto3" json:"search,omitempty"`
+	Source        *Source                `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	Outbox        *Outbox                `protobuf:"bytes,7,opt,name=outbox,proto3" json:"outbox,omitempty"`
+	AsyncCreate   *AsyncCreate           `protobuf:"bytes,8,opt,name=async_create,json=asyncCreate,proto3" json:"async_create,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -90,6 +94,34 @@ func (x *Bootstrap) GetElasticsearch() *Elasticsearch {
 	return nil
 }
 
+func (x *Bootstrap) GetSearch() *Search {
+	if x != nil {
+		return x.Search
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetSource() *Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetOutbox() *Outbox {
+	if x != nil {
+		return x.Outbox
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetAsyncCreate() *AsyncCreate {
+	if x != nil {
+		return x.AsyncCreate
+	}
+	return nil
+}
+
 type Server struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Http          *Server_HTTP           `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
@@ -146,6 +178,8 @@ type Data struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Database      *Data_Database         `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
 	Redis         *Data_Redis            `protobuf:"bytes,2,opt,name=redis,proto3" json:"redis,omitempty"`
+	Bloom         *Data_Bloom            `protobuf:"bytes,3,opt,name=bloom,proto3" json:"bloom,omitempty"`
+	Sharding      *Data_Sharding         `protobuf:"bytes,4,opt,name=sharding,proto3" json:"sharding,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -194,11 +228,37 @@ func (x *Data) GetRedis() *Data_Redis {
 	return nil
 }
 
+func (x *Data) GetBloom() *Data_Bloom {
+	if x != nil {
+		return x.Bloom
+	}
+	return nil
+}
+
+func (x *Data) GetSharding() *Data_Sharding {
+	if x != nil {
+		return x.Sharding
+	}
+	return nil
+}
+
 type Kafka struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Brokers       []string               `protobuf:"bytes,1,rep,name=brokers,proto3" json:"brokers,omitempty"`
-	GroupId       string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
-	Topic         string                 `protobuf:"bytes,3,opt,name=topic,proto3" json:"topic,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Brokers []string               `protobuf:"bytes,1,rep,name=brokers,proto3" json:"brokers,omitempty"`
+	GroupId string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Topic   string                 `protobuf:"bytes,3,opt,name=topic,proto3" json:"topic,omitempty"`
+	// 死信 topic，无法解析的消息和被 ES 拒绝的文档写入这里，由 replay-dlq 命令重放；为空时只记录日志
+	DlqTopic string `protobuf:"bytes,4,opt,name=dlq_topic,json=dlqTopic,proto3" json:"dlq_topic,omitempty"`
+	// 告警 topic，表结构变更（DDL）和表结构与文档结构不一致时写入告警事件；为空时只记录日志
+	AlertTopic string `protobuf:"bytes,5,opt,name=alert_topic,json=alertTopic,proto3" json:"alert_topic,omitempty"`
+	// 并发写入的 worker 数，默认 4；消息按 review_id 分片，同一条评价的变更由同一个 worker 按顺序写入
+	Workers int32 `protobuf:"varint,6,opt,name=workers,proto3" json:"workers,omitempty"`
+	// 已拉取但还没有写入并提交 offset 的消息数上限，默认 1000；达到上限后暂停拉取
+	MaxInFlight int32 `protobuf:"varint,7,opt,name=max_in_flight,json=maxInFlight,proto3" json:"max_in_flight,omitempty"`
+	// 与 topic 一起订阅的其他 topic，如 Debezium 每张表一个 topic
+	Topics []string `protobuf:"bytes,8,rep,name=topics,proto3" json:"topics,omitempty"`
+	// 每个 topic 的消息格式：canal（canal flat message，默认）或 debezium（JSON converter，需要开启 schemas.enable）
+	Formats       map[string]string `protobuf:"bytes,9,rep,name=formats,proto3" json:"formats,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -254,17 +314,276 @@ func (x *Kafka) GetTopic() string {
 	return ""
 }
 
+func (x *Kafka) GetDlqTopic() string {
+	if x != nil {
+		return x.DlqTopic
+	}
+	return ""
+}
+
+func (x *Kafka) GetAlertTopic() string {
+	if x != nil {
+		return x.AlertTopic
+	}
+	return ""
+}
+
+func (x *Kafka) GetWorkers() int32 {
+	if x != nil {
+		return x.Workers
+	}
+	return 0
+}
+
+func (x *Kafka) GetMaxInFlight() int32 {
+	if x != nil {
+		return x.MaxInFlight
+	}
+	return 0
+}
+
+func (x *Kafka) GetTopics() []string {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *Kafka) GetFormats() map[string]string {
+	if x != nil {
+		return x.Formats
+	}
+	return nil
+}
+
+// 变更数据的来源
+type Source struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// kafka（默认）：canal-server 以 kafka 模式投递，从 Kafka.topic 读取；canal：直接连接 canal-server
+	Type          string        `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Canal         *Source_Canal `protobuf:"bytes,2,opt,name=canal,proto3" json:"canal,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Source) Reset() {
+	*x = Source{}
+	mi := &file_conf_conf_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Source) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Source) ProtoMessage() {}
+
+func (x *Source) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Source.ProtoReflect.Descriptor instead.
+func (*Source) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Source) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Source) GetCanal() *Source_Canal {
+	if x != nil {
+		return x.Canal
+	}
+	return nil
+}
+
+// 将 review-service 写入发件箱 review_outbox 的领域事件投递到 kafka
+type Outbox struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 事件投递的 topic，为空时不投递
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// 发件箱所在的数据库，review-service 配置了分库时每个库一个 DSN；为空时使用 data.database
+	Databases []string `protobuf:"bytes,2,rep,name=databases,proto3" json:"databases,omitempty"`
+	// 没有待投递的事件时轮询的间隔，默认 500ms
+	Interval *durationpb.Duration `protobuf:"bytes,3,opt,name=interval,proto3" json:"interval,omitempty"`
+	// 每次读取并投递的事件数，默认 100
+	BatchSize int32 `protobuf:"varint,4,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	// 已投递的事件保留的时间，之后从发件箱中删除，默认 7 天
+	Retention     *durationpb.Duration `protobuf:"bytes,5,opt,name=retention,proto3" json:"retention,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Outbox) Reset() {
+	*x = Outbox{}
+	mi := &file_conf_conf_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Outbox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Outbox) ProtoMessage() {}
+
+func (x *Outbox) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Outbox.ProtoReflect.Descriptor instead.
+func (*Outbox) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Outbox) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Outbox) GetDatabases() []string {
+	if x != nil {
+		return x.Databases
+	}
+	return nil
+}
+
+func (x *Outbox) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *Outbox) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Outbox) GetRetention() *durationpb.Duration {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+// 异步创建评价：消费 review-service 投递的创建命令，按 review_id 幂等写入 MySQL
+type AsyncCreate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 命令 topic，与 review-service 的 data.async_create.topic 一致；为空时不消费
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// 消费者组，默认 {kafka.group_id}-create
+	GroupId string `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	// 创建进度在 redis 中保留的时间，与 review-service 一致，默认 24h
+	StatusTtl *durationpb.Duration `protobuf:"bytes,3,opt,name=status_ttl,json=statusTtl,proto3" json:"status_ttl,omitempty"`
+	// 写入数据库或更新创建进度出错时最多重试的次数，默认 5；用尽后命令写入死信队列，创建进度标记为失败
+	Retries       int32 `protobuf:"varint,4,opt,name=retries,proto3" json:"retries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AsyncCreate) Reset() {
+	*x = AsyncCreate{}
+	mi := &file_conf_conf_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AsyncCreate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AsyncCreate) ProtoMessage() {}
+
+func (x *AsyncCreate) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AsyncCreate.ProtoReflect.Descriptor instead.
+func (*AsyncCreate) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AsyncCreate) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *AsyncCreate) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *AsyncCreate) GetStatusTtl() *durationpb.Duration {
+	if x != nil {
+		return x.StatusTtl
+	}
+	return nil
+}
+
+func (x *AsyncCreate) GetRetries() int32 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
 type Elasticsearch struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Addr          []string               `protobuf:"bytes,1,rep,name=addr,proto3" json:"addr,omitempty"`
-	Index         string                 `protobuf:"bytes,2,opt,name=index,proto3" json:"index,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Addr  []string               `protobuf:"bytes,1,rep,name=addr,proto3" json:"addr,omitempty"`
+	// 读写别名，实际数据位于 {index}_v{时间戳} 的版本化索引中，由 reindex 命令切换
+	Index string `protobuf:"bytes,2,opt,name=index,proto3" json:"index,omitempty"`
+	// content 字段写入时使用的分词器，如 ik_max_word；为空时使用 standard
+	Analyzer string `protobuf:"bytes,3,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	// 申诉索引的读写别名，默认 appeal，供运营端检索申诉
+	AppealIndex string `protobuf:"bytes,4,opt,name=appeal_index,json=appealIndex,proto3" json:"appeal_index,omitempty"`
+	// 表中新增了文档结构中没有的列时，按列的类型将其加入索引的 mapping，之后写入的文档带上这些列；
+	// 只追加字段，不修改已有字段，索引模板不变，reindex 前需要把新列加入文档结构
+	AutoMapping   bool `protobuf:"varint,5,opt,name=auto_mapping,json=autoMapping,proto3" json:"auto_mapping,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Elasticsearch) Reset() {
 	*x = Elasticsearch{}
-	mi := &file_conf_conf_proto_msgTypes[4]
+	mi := &file_conf_conf_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -276,7 +595,7 @@ func (x *Elasticsearch) String() string {
 func (*Elasticsearch) ProtoMessage() {}
 
 func (x *Elasticsearch) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_conf_proto_msgTypes[4]
+	mi := &file_conf_conf_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -289,7 +608,7 @@ func (x *Elasticsearch) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Elasticsearch.ProtoReflect.Descriptor instead.
 func (*Elasticsearch) Descriptor() ([]byte, []int) {
-	return file_conf_conf_proto_rawDescGZIP(), []int{4}
+	return file_conf_conf_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Elasticsearch) GetAddr() []string {
@@ -306,6 +625,90 @@ func (x *Elasticsearch) GetIndex() string {
 	return ""
 }
 
+func (x *Elasticsearch) GetAnalyzer() string {
+	if x != nil {
+		return x.Analyzer
+	}
+	return ""
+}
+
+func (x *Elasticsearch) GetAppealIndex() string {
+	if x != nil {
+		return x.AppealIndex
+	}
+	return ""
+}
+
+func (x *Elasticsearch) GetAutoMapping() bool {
+	if x != nil {
+		return x.AutoMapping
+	}
+	return false
+}
+
+// 搜索后端，与 review-service 的 data.search 配置一致
+type Search struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// elasticsearch（默认）或 bleve
+	Backend string `protobuf:"bytes,1,opt,name=backend,proto3" json:"backend,omitempty"`
+	// bleve 评价索引目录，申诉索引位于 {path}_appeal；为空时使用内存索引
+	Path          string       `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Bulk          *Search_Bulk `protobuf:"bytes,3,opt,name=bulk,proto3" json:"bulk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Search) Reset() {
+	*x = Search{}
+	mi := &file_conf_conf_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Search) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Search) ProtoMessage() {}
+
+func (x *Search) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Search.ProtoReflect.Descriptor instead.
+func (*Search) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Search) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *Search) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Search) GetBulk() *Search_Bulk {
+	if x != nil {
+		return x.Bulk
+	}
+	return nil
+}
+
 type Server_HTTP struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
@@ -317,7 +720,7 @@ type Server_HTTP struct {
 
 func (x *Server_HTTP) Reset() {
 	*x = Server_HTTP{}
-	mi := &file_conf_conf_proto_msgTypes[5]
+	mi := &file_conf_conf_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -329,7 +732,7 @@ func (x *Server_HTTP) String() string {
 func (*Server_HTTP) ProtoMessage() {}
 
 func (x *Server_HTTP) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_conf_proto_msgTypes[5]
+	mi := &file_conf_conf_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -377,7 +780,7 @@ type Server_GRPC struct {
 
 func (x *Server_GRPC) Reset() {
 	*x = Server_GRPC{}
-	mi := &file_conf_conf_proto_msgTypes[6]
+	mi := &file_conf_conf_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -389,7 +792,7 @@ func (x *Server_GRPC) String() string {
 func (*Server_GRPC) ProtoMessage() {}
 
 func (x *Server_GRPC) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_conf_proto_msgTypes[6]
+	mi := &file_conf_conf_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -427,16 +830,18 @@ func (x *Server_GRPC) GetTimeout() *durationpb.Duration {
 }
 
 type Data_Database struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Driver        string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
-	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Driver string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
+	Source string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// 数据库的时区，canal 消息中的 datetime 按此时区解析后以 RFC3339 写入索引，如 Asia/Shanghai；为空时使用本地时区
+	TimeZone      string `protobuf:"bytes,3,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Data_Database) Reset() {
 	*x = Data_Database{}
-	mi := &file_conf_conf_proto_msgTypes[7]
+	mi := &file_conf_conf_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -448,7 +853,7 @@ func (x *Data_Database) String() string {
 func (*Data_Database) ProtoMessage() {}
 
 func (x *Data_Database) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_conf_proto_msgTypes[7]
+	mi := &file_conf_conf_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -478,19 +883,28 @@ func (x *Data_Database) GetSource() string {
 	return ""
 }
 
+func (x *Data_Database) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
 type Data_Redis struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
 	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
 	ReadTimeout   *durationpb.Duration   `protobuf:"bytes,3,opt,name=read_timeout,json=readTimeout,proto3" json:"read_timeout,omitempty"`
 	WriteTimeout  *durationpb.Duration   `protobuf:"bytes,4,opt,name=write_timeout,json=writeTimeout,proto3" json:"write_timeout,omitempty"`
+	Password      string                 `protobuf:"bytes,5,opt,name=password,proto3" json:"password,omitempty"`
+	Db            int64                  `protobuf:"varint,6,opt,name=db,proto3" json:"db,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Data_Redis) Reset() {
 	*x = Data_Redis{}
-	mi := &file_conf_conf_proto_msgTypes[8]
+	mi := &file_conf_conf_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -502,7 +916,7 @@ func (x *Data_Redis) String() string {
 func (*Data_Redis) ProtoMessage() {}
 
 func (x *Data_Redis) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_conf_proto_msgTypes[8]
+	mi := &file_conf_conf_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -546,17 +960,374 @@ func (x *Data_Redis) GetWriteTimeout() *durationpb.Duration {
 	return nil
 }
 
+func (x *Data_Redis) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Data_Redis) GetDb() int64 {
+	if x != nil {
+		return x.Db
+	}
+	return 0
+}
+
+// 根据 binlog 维护已存在的 review_id / store_id，供 review-service 防止缓存穿透
+type Data_Bloom struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Enabled   bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	ReviewKey string                 `protobuf:"bytes,2,opt,name=review_key,json=reviewKey,proto3" json:"review_key,omitempty"`
+	StoreKey  string                 `protobuf:"bytes,3,opt,name=store_key,json=storeKey,proto3" json:"store_key,omitempty"`
+	ErrorRate float64                `protobuf:"fixed64,4,opt,name=error_rate,json=errorRate,proto3" json:"error_rate,omitempty"`
+	Capacity  int64                  `protobuf:"varint,5,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// 存量数据已全部写入过滤器的标记，由 seed-bloom 或全量同步评价完成后写入，默认 bloom:ready；
+	// 与 review-service 的 data.cache.bloom_ready_key 一致，标记不存在时 review-service 不检查过滤器
+	ReadyKey      string `protobuf:"bytes,6,opt,name=ready_key,json=readyKey,proto3" json:"ready_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Bloom) Reset() {
+	*x = Data_Bloom{}
+	mi := &file_conf_conf_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Bloom) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Bloom) ProtoMessage() {}
+
+func (x *Data_Bloom) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Bloom.ProtoReflect.Descriptor instead.
+func (*Data_Bloom) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{2, 2}
+}
+
+func (x *Data_Bloom) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Data_Bloom) GetReviewKey() string {
+	if x != nil {
+		return x.ReviewKey
+	}
+	return ""
+}
+
+func (x *Data_Bloom) GetStoreKey() string {
+	if x != nil {
+		return x.StoreKey
+	}
+	return ""
+}
+
+func (x *Data_Bloom) GetErrorRate() float64 {
+	if x != nil {
+		return x.ErrorRate
+	}
+	return 0
+}
+
+func (x *Data_Bloom) GetCapacity() int64 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *Data_Bloom) GetReadyKey() string {
+	if x != nil {
+		return x.ReadyKey
+	}
+	return ""
+}
+
+// 与 review-service 的 data.sharding 一致，写入评价时按 store_id 定位分表
+type Data_Sharding struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Enabled    bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	TableCount int32                  `protobuf:"varint,2,opt,name=table_count,json=tableCount,proto3" json:"table_count,omitempty"`
+	// 分库 DSN，第 i 张分表位于 databases[i % len(databases)]；为空时全部位于 database
+	Databases     []string `protobuf:"bytes,3,rep,name=databases,proto3" json:"databases,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Sharding) Reset() {
+	*x = Data_Sharding{}
+	mi := &file_conf_conf_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Sharding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Sharding) ProtoMessage() {}
+
+func (x *Data_Sharding) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Sharding.ProtoReflect.Descriptor instead.
+func (*Data_Sharding) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{2, 3}
+}
+
+func (x *Data_Sharding) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Data_Sharding) GetTableCount() int32 {
+	if x != nil {
+		return x.TableCount
+	}
+	return 0
+}
+
+func (x *Data_Sharding) GetDatabases() []string {
+	if x != nil {
+		return x.Databases
+	}
+	return nil
+}
+
+// 直接连接 canal-server 的 TCP 端口订阅 binlog
+type Source_Canal struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// canal-server 地址，如 127.0.0.1:11111
+	Addr string `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	// canal instance 名称，默认 example
+	Destination string `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	Username    string `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	Password    string `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
+	// 订阅的表（Perl 正则），默认订阅 review_info、review_reply_info、review_appeal_info 三张表
+	Filter string `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`
+	// 每次拉取的最大条数，默认 1000
+	BatchSize int32 `protobuf:"varint,6,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	// 没有新数据时一次拉取最长等待的时间，默认 1s
+	Timeout       *durationpb.Duration `protobuf:"bytes,7,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Source_Canal) Reset() {
+	*x = Source_Canal{}
+	mi := &file_conf_conf_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Source_Canal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Source_Canal) ProtoMessage() {}
+
+func (x *Source_Canal) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Source_Canal.ProtoReflect.Descriptor instead.
+func (*Source_Canal) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{4, 0}
+}
+
+func (x *Source_Canal) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Source_Canal) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *Source_Canal) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Source_Canal) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Source_Canal) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *Source_Canal) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Source_Canal) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+// 批量写入：满足任意一个条件就刷新一次，刷新成功后才提交 kafka offset
+type Search_Bulk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 攒够多少个操作，默认 500
+	Actions int32 `protobuf:"varint,1,opt,name=actions,proto3" json:"actions,omitempty"`
+	// 攒够多少字节，默认 5MB
+	Bytes int64 `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	// 距离上次刷新最长多久，默认 1s
+	Interval *durationpb.Duration `protobuf:"bytes,3,opt,name=interval,proto3" json:"interval,omitempty"`
+	// 写入失败（请求整体失败，或部分操作因限流等临时错误失败）后的重试次数，默认 5；
+	// 重试耗尽后 review-job 退出，这批消息的 offset 没有提交，重启后重新消费
+	Retries int32 `protobuf:"varint,4,opt,name=retries,proto3" json:"retries,omitempty"`
+	// 第一次重试前的等待时间，之后每次翻倍，默认 100ms
+	Backoff *durationpb.Duration `protobuf:"bytes,5,opt,name=backoff,proto3" json:"backoff,omitempty"`
+	// 重试等待时间的上限，默认 10s
+	MaxBackoff    *durationpb.Duration `protobuf:"bytes,6,opt,name=max_backoff,json=maxBackoff,proto3" json:"max_backoff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Search_Bulk) Reset() {
+	*x = Search_Bulk{}
+	mi := &file_conf_conf_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Search_Bulk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Search_Bulk) ProtoMessage() {}
+
+func (x *Search_Bulk) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Search_Bulk.ProtoReflect.Descriptor instead.
+func (*Search_Bulk) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{8, 0}
+}
+
+func (x *Search_Bulk) GetActions() int32 {
+	if x != nil {
+		return x.Actions
+	}
+	return 0
+}
+
+func (x *Search_Bulk) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *Search_Bulk) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *Search_Bulk) GetRetries() int32 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *Search_Bulk) GetBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.Backoff
+	}
+	return nil
+}
+
+func (x *Search_Bulk) GetMaxBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.MaxBackoff
+	}
+	return nil
+}
+
 var File_conf_conf_proto protoreflect.FileDescriptor
 
 const file_conf_conf_proto_rawDesc = "" +
 	"\n" +
 	"\x0fconf/conf.proto\x12\n" +
-	"kratos.api\x1a\x1egoogle/protobuf/duration.proto\"\xc7\x01\n" +
+	"kratos.api\x1a\x1egoogle/protobuf/duration.proto\"\x87\x03\n" +
 	"\tBootstrap\x12*\n" +
 	"\x06server\x18\x01 \x01(\v2\x12.kratos.api.ServerR\x06server\x12$\n" +
 	"\x04data\x18\x02 \x01(\v2\x10.kratos.api.DataR\x04data\x12'\n" +
 	"\x05kafka\x18\x03 \x01(\v2\x11.kratos.api.KafkaR\x05kafka\x12?\n" +
-	"\relasticsearch\x18\x04 \x01(\v2\x19.kratos.api.ElasticsearchR\relasticsearch\"\xb8\x02\n" +
+	"\relasticsearch\x18\x04 \x01(\v2\x19.kratos.api.ElasticsearchR\relasticsearch\x12*\n" +
+	"\x06search\x18\x05 \x01(\v2\x12.kratos.api.SearchR\x06search\x12*\n" +
+	"\x06source\x18\x06 \x01(\v2\x12.kratos.api.SourceR\x06source\x12*\n" +
+	"\x06outbox\x18\a \x01(\v2\x12.kratos.api.OutboxR\x06outbox\x12:\n" +
+	"\fasync_create\x18\b \x01(\v2\x17.kratos.api.AsyncCreateR\vasyncCreate\"\xb8\x02\n" +
 	"\x06Server\x12+\n" +
 	"\x04http\x18\x01 \x01(\v2\x17.kratos.api.Server.HTTPR\x04http\x12+\n" +
 	"\x04grpc\x18\x02 \x01(\v2\x17.kratos.api.Server.GRPCR\x04grpc\x1ai\n" +
@@ -567,25 +1338,94 @@ const file_conf_conf_proto_rawDesc = "" +
 	"\x04GRPC\x12\x18\n" +
 	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
 	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
-	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\xdd\x02\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\xa8\x06\n" +
 	"\x04Data\x125\n" +
 	"\bdatabase\x18\x01 \x01(\v2\x19.kratos.api.Data.DatabaseR\bdatabase\x12,\n" +
-	"\x05redis\x18\x02 \x01(\v2\x16.kratos.api.Data.RedisR\x05redis\x1a:\n" +
+	"\x05redis\x18\x02 \x01(\v2\x16.kratos.api.Data.RedisR\x05redis\x12,\n" +
+	"\x05bloom\x18\x03 \x01(\v2\x16.kratos.api.Data.BloomR\x05bloom\x125\n" +
+	"\bsharding\x18\x04 \x01(\v2\x19.kratos.api.Data.ShardingR\bsharding\x1aW\n" +
 	"\bDatabase\x12\x16\n" +
 	"\x06driver\x18\x01 \x01(\tR\x06driver\x12\x16\n" +
-	"\x06source\x18\x02 \x01(\tR\x06source\x1a\xb3\x01\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1b\n" +
+	"\ttime_zone\x18\x03 \x01(\tR\btimeZone\x1a\xdf\x01\n" +
 	"\x05Redis\x12\x18\n" +
 	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
 	"\x04addr\x18\x02 \x01(\tR\x04addr\x12<\n" +
 	"\fread_timeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\vreadTimeout\x12>\n" +
-	"\rwrite_timeout\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\fwriteTimeout\"R\n" +
+	"\rwrite_timeout\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\fwriteTimeout\x12\x1a\n" +
+	"\bpassword\x18\x05 \x01(\tR\bpassword\x12\x0e\n" +
+	"\x02db\x18\x06 \x01(\x03R\x02db\x1a\xb5\x01\n" +
+	"\x05Bloom\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1d\n" +
+	"\n" +
+	"review_key\x18\x02 \x01(\tR\treviewKey\x12\x1b\n" +
+	"\tstore_key\x18\x03 \x01(\tR\bstoreKey\x12\x1d\n" +
+	"\n" +
+	"error_rate\x18\x04 \x01(\x01R\terrorRate\x12\x1a\n" +
+	"\bcapacity\x18\x05 \x01(\x03R\bcapacity\x12\x1b\n" +
+	"\tready_key\x18\x06 \x01(\tR\breadyKey\x1ac\n" +
+	"\bSharding\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1f\n" +
+	"\vtable_count\x18\x02 \x01(\x05R\n" +
+	"tableCount\x12\x1c\n" +
+	"\tdatabases\x18\x03 \x03(\tR\tdatabases\"\xdc\x02\n" +
 	"\x05Kafka\x12\x18\n" +
 	"\abrokers\x18\x01 \x03(\tR\abrokers\x12\x19\n" +
 	"\bgroup_id\x18\x02 \x01(\tR\agroupId\x12\x14\n" +
-	"\x05topic\x18\x03 \x01(\tR\x05topic\"9\n" +
+	"\x05topic\x18\x03 \x01(\tR\x05topic\x12\x1b\n" +
+	"\tdlq_topic\x18\x04 \x01(\tR\bdlqTopic\x12\x1f\n" +
+	"\valert_topic\x18\x05 \x01(\tR\n" +
+	"alertTopic\x12\x18\n" +
+	"\aworkers\x18\x06 \x01(\x05R\aworkers\x12\"\n" +
+	"\rmax_in_flight\x18\a \x01(\x05R\vmaxInFlight\x12\x16\n" +
+	"\x06topics\x18\b \x03(\tR\x06topics\x128\n" +
+	"\aformats\x18\t \x03(\v2\x1e.kratos.api.Kafka.FormatsEntryR\aformats\x1a:\n" +
+	"\fFormatsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb0\x02\n" +
+	"\x06Source\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12.\n" +
+	"\x05canal\x18\x02 \x01(\v2\x18.kratos.api.Source.CanalR\x05canal\x1a\xe1\x01\n" +
+	"\x05Canal\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x04 \x01(\tR\bpassword\x12\x16\n" +
+	"\x06filter\x18\x05 \x01(\tR\x06filter\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x06 \x01(\x05R\tbatchSize\x123\n" +
+	"\atimeout\x18\a \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\xcb\x01\n" +
+	"\x06Outbox\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1c\n" +
+	"\tdatabases\x18\x02 \x03(\tR\tdatabases\x125\n" +
+	"\binterval\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\binterval\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x04 \x01(\x05R\tbatchSize\x127\n" +
+	"\tretention\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\tretention\"\x92\x01\n" +
+	"\vAsyncCreate\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x19\n" +
+	"\bgroup_id\x18\x02 \x01(\tR\agroupId\x128\n" +
+	"\n" +
+	"status_ttl\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\tstatusTtl\x12\x18\n" +
+	"\aretries\x18\x04 \x01(\x05R\aretries\"\x9b\x01\n" +
 	"\rElasticsearch\x12\x12\n" +
 	"\x04addr\x18\x01 \x03(\tR\x04addr\x12\x14\n" +
-	"\x05index\x18\x02 \x01(\tR\x05indexB\x1fZ\x1dreview-job/internal/conf;confb\x06proto3"
+	"\x05index\x18\x02 \x01(\tR\x05index\x12\x1a\n" +
+	"\banalyzer\x18\x03 \x01(\tR\banalyzer\x12!\n" +
+	"\fappeal_index\x18\x04 \x01(\tR\vappealIndex\x12!\n" +
+	"\fauto_mapping\x18\x05 \x01(\bR\vautoMapping\"\xde\x02\n" +
+	"\x06Search\x12\x18\n" +
+	"\abackend\x18\x01 \x01(\tR\abackend\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12+\n" +
+	"\x04bulk\x18\x03 \x01(\v2\x17.kratos.api.Search.BulkR\x04bulk\x1a\xf8\x01\n" +
+	"\x04Bulk\x12\x18\n" +
+	"\aactions\x18\x01 \x01(\x05R\aactions\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x03R\x05bytes\x125\n" +
+	"\binterval\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\binterval\x12\x18\n" +
+	"\aretries\x18\x04 \x01(\x05R\aretries\x123\n" +
+	"\abackoff\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\abackoff\x12:\n" +
+	"\vmax_backoff\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"maxBackoffB\x1fZ\x1dreview-job/internal/conf;confb\x06proto3"
 
 var (
 	file_conf_conf_proto_rawDescOnce sync.Once
@@ -599,37 +1439,62 @@ func file_conf_conf_proto_rawDescGZIP() []byte {
 	return file_conf_conf_proto_rawDescData
 }
 
-var file_conf_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_conf_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_conf_conf_proto_goTypes = []any{
 	(*Bootstrap)(nil),           // 0: kratos.api.Bootstrap
 	(*Server)(nil),              // 1: kratos.api.Server
 	(*Data)(nil),                // 2: kratos.api.Data
 	(*Kafka)(nil),               // 3: kratos.api.Kafka
-	(*Elasticsearch)(nil),       // 4: kratos.api.Elasticsearch
-	(*Server_HTTP)(nil),         // 5: kratos.api.Server.HTTP
-	(*Server_GRPC)(nil),         // 6: kratos.api.Server.GRPC
-	(*Data_Database)(nil),       // 7: kratos.api.Data.Database
-	(*Data_Redis)(nil),          // 8: kratos.api.Data.Redis
-	(*durationpb.Duration)(nil), // 9: google.protobuf.Duration
+	(*Source)(nil),              // 4: kratos.api.Source
+	(*Outbox)(nil),              // 5: kratos.api.Outbox
+	(*AsyncCreate)(nil),         // 6: kratos.api.AsyncCreate
+	(*Elasticsearch)(nil),       // 7: kratos.api.Elasticsearch
+	(*Search)(nil),              // 8: kratos.api.Search
+	(*Server_HTTP)(nil),         // 9: kratos.api.Server.HTTP
+	(*Server_GRPC)(nil),         // 10: kratos.api.Server.GRPC
+	(*Data_Database)(nil),       // 11: kratos.api.Data.Database
+	(*Data_Redis)(nil),          // 12: kratos.api.Data.Redis
+	(*Data_Bloom)(nil),          // 13: kratos.api.Data.Bloom
+	(*Data_Sharding)(nil),       // 14: kratos.api.Data.Sharding
+	nil,                         // 15: kratos.api.Kafka.FormatsEntry
+	(*Source_Canal)(nil),        // 16: kratos.api.Source.Canal
+	(*Search_Bulk)(nil),         // 17: kratos.api.Search.Bulk
+	(*durationpb.Duration)(nil), // 18: google.protobuf.Duration
 }
 var file_conf_conf_proto_depIdxs = []int32{
 	1,  // 0: kratos.api.Bootstrap.server:type_name -> kratos.api.Server
 	2,  // 1: kratos.api.Bootstrap.data:type_name -> kratos.api.Data
 	3,  // 2: kratos.api.Bootstrap.kafka:type_name -> kratos.api.Kafka
-	4,  // 3: kratos.api.Bootstrap.elasticsearch:type_name -> kratos.api.Elasticsearch
-	5,  // 4: kratos.api.Server.http:type_name -> kratos.api.Server.HTTP
-	6,  // 5: kratos.api.Server.grpc:type_name -> kratos.api.Server.GRPC
-	7,  // 6: kratos.api.Data.database:type_name -> kratos.api.Data.Database
-	8,  // 7: kratos.api.Data.redis:type_name -> kratos.api.Data.Redis
-	9,  // 8: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
-	9,  // 9: kratos.api.Server.GRPC.timeout:type_name -> google.protobuf.Duration
-	9,  // 10: kratos.api.Data.Redis.read_timeout:type_name -> google.protobuf.Duration
-	9,  // 11: kratos.api.Data.Redis.write_timeout:type_name -> google.protobuf.Duration
-	12, // [12:12] is the sub-list for method output_type
-	12, // [12:12] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	7,  // 3: kratos.api.Bootstrap.elasticsearch:type_name -> kratos.api.Elasticsearch
+	8,  // 4: kratos.api.Bootstrap.search:type_name -> kratos.api.Search
+	4,  // 5: kratos.api.Bootstrap.source:type_name -> kratos.api.Source
+	5,  // 6: kratos.api.Bootstrap.outbox:type_name -> kratos.api.Outbox
+	6,  // 7: kratos.api.Bootstrap.async_create:type_name -> kratos.api.AsyncCreate
+	9,  // 8: kratos.api.Server.http:type_name -> kratos.api.Server.HTTP
+	10, // 9: kratos.api.Server.grpc:type_name -> kratos.api.Server.GRPC
+	11, // 10: kratos.api.Data.database:type_name -> kratos.api.Data.Database
+	12, // 11: kratos.api.Data.redis:type_name -> kratos.api.Data.Redis
+	13, // 12: kratos.api.Data.bloom:type_name -> kratos.api.Data.Bloom
+	14, // 13: kratos.api.Data.sharding:type_name -> kratos.api.Data.Sharding
+	15, // 14: kratos.api.Kafka.formats:type_name -> kratos.api.Kafka.FormatsEntry
+	16, // 15: kratos.api.Source.canal:type_name -> kratos.api.Source.Canal
+	18, // 16: kratos.api.Outbox.interval:type_name -> google.protobuf.Duration
+	18, // 17: kratos.api.Outbox.retention:type_name -> google.protobuf.Duration
+	18, // 18: kratos.api.AsyncCreate.status_ttl:type_name -> google.protobuf.Duration
+	17, // 19: kratos.api.Search.bulk:type_name -> kratos.api.Search.Bulk
+	18, // 20: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
+	18, // 21: kratos.api.Server.GRPC.timeout:type_name -> google.protobuf.Duration
+	18, // 22: kratos.api.Data.Redis.read_timeout:type_name -> google.protobuf.Duration
+	18, // 23: kratos.api.Data.Redis.write_timeout:type_name -> google.protobuf.Duration
+	18, // 24: kratos.api.Source.Canal.timeout:type_name -> google.protobuf.Duration
+	18, // 25: kratos.api.Search.Bulk.interval:type_name -> google.protobuf.Duration
+	18, // 26: kratos.api.Search.Bulk.backoff:type_name -> google.protobuf.Duration
+	18, // 27: kratos.api.Search.Bulk.max_backoff:type_name -> google.protobuf.Duration
+	28, // [28:28] is the sub-list for method output_type
+	28, // [28:28] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_conf_conf_proto_init() }
@@ -643,7 +1508,7 @@ func file_conf_conf_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_conf_conf_proto_rawDesc), len(file_conf_conf_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
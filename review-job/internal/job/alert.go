@@ -0,0 +1,87 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/segmentio/kafka-go"
+	"review-job/internal/conf"
+)
+
+// 告警事件的类型
+const (
+	AlertDDL         = "ddl"          // 已注册的表执行了 DDL
+	AlertSchemaDrift = "schema_drift" // 表结构与文档结构不一致
+)
+
+// Column 表中的一列
+type Column struct {
+	Name      string `json:"name"`
+	MysqlType string `json:"mysql_type"`
+	Field     string `json:"field,omitempty"` // 文档中对应字段的类型，类型不兼容时才有
+}
+
+// Alert 告警事件，以 JSON 写入告警 topic，key 为表名
+type Alert struct {
+	Type     string `json:"type"`
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	SQL      string `json:"sql,omitempty"`
+	// Added 表中新增、文档结构中没有的列，未加入 mapping 时这些列不会写入索引
+	Added []Column `json:"added,omitempty"`
+	// Missing 文档结构中有、表中已经没有的列，写入索引时为零值
+	Missing []string `json:"missing,omitempty"`
+	// Mismatched 类型与文档字段不兼容的列，写入时会转换失败，消息进入死信队列
+	Mismatched []Column `json:"mismatched,omitempty"`
+	// Applied 已按 auto_mapping 加入索引 mapping 的列
+	Applied []string  `json:"applied,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// Alerter 将告警事件写入告警 topic，未配置时什么也不做，告警内容由调用方记录日志
+type Alerter struct {
+	writer *kafka.Writer
+	log    *log.Helper
+}
+
+func NewAlerter(cfg *conf.Kafka, logger log.Logger) (*Alerter, func(), error) {
+	a := &Alerter{log: log.NewHelper(logger)}
+	if cfg.GetAlertTopic() == "" {
+		return a, func() {}, nil
+	}
+	a.writer = &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.AlertTopic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+	cleanup := func() {
+		if err := a.writer.Close(); err != nil {
+			a.log.Errorf("close alert writer failed, err:%v", err)
+		}
+	}
+	return a, cleanup, nil
+}
+
+// Enabled 是否配置了告警 topic
+func (a *Alerter) Enabled() bool {
+	return a != nil && a.writer != nil
+}
+
+// Emit 写入一条告警事件
+func (a *Alerter) Emit(ctx context.Context, alert *Alert) error {
+	if !a.Enabled() {
+		return nil
+	}
+	value, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	if err := a.writer.WriteMessages(ctx, kafka.Message{Key: []byte(alert.Table), Value: value}); err != nil {
+		return fmt.Errorf("emit %s alert of %s failed: %w", alert.Type, alert.Table, err)
+	}
+	return nil
+}
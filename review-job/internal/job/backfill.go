@@ -0,0 +1,429 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-sql-driver/mysql"
+	"review-job/internal/conf"
+	"review-service/pkg/search"
+)
+
+const (
+	defaultBackfillChunk = 500
+	// replyTable 评价的回复所在的表，全量同步评价时按 review_id 一并读取
+	replyTable = "review_reply_info"
+)
+
+// backfillTables 全量同步每个索引时按主键扫描的逻辑表，开启分表时依次扫描每张分表
+var backfillTables = map[string]string{
+	IndexReview: "review_info",
+	IndexAppeal: "review_appeal_info",
+}
+
+// NewMySQL 连接 data.database 配置的数据库，供 backfill 读取数据
+// 列值统一按文本读取，与 canal 消息中的格式一致，datetime 由 Transformer 按 time_zone 解析
+func NewMySQL(c *conf.Data_Database) (*sql.DB, error) {
+	if driver := c.GetDriver(); driver != "" && driver != "mysql" {
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+	cfg, err := mysql.ParseDSN(c.GetSource())
+	if err != nil {
+		return nil, err
+	}
+	cfg.ParseTime = false
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("connect mysql failed: %w", err)
+	}
+	return db, nil
+}
+
+// Checkpoint 全量同步的进度，每写完一批保存一次，中断后从 Table 的 LastID 之后继续
+type Checkpoint struct {
+	Index  string `json:"index"`
+	Target string `json:"target"`
+	// Table 正在同步的物理表，分表按编号依次同步，之前的分表已经同步完；为空时为第一张表
+	Table  string `json:"table,omitempty"`
+	LastID int64  `json:"last_id"` // Table 中已同步的最大主键
+	Rows   int64  `json:"rows"`
+	Done   bool   `json:"done"`
+	// Bloom 从第一行开始每一批评价都写入了布隆过滤器，同步完成后标记过滤器已写入存量数据
+	Bloom    bool      `json:"bloom,omitempty"`
+	StartAt  time.Time `json:"start_at"`
+	UpdateAt time.Time `json:"update_at"`
+}
+
+// BackfillOption 全量同步的参数
+type BackfillOption struct {
+	Index string // IndexReview 或 IndexAppeal
+	// Target 写入的索引名，只记录在 checkpoint 中，防止用同一个 checkpoint 续写另一个索引
+	Target string
+	Chunk  int // 每批读取的行数，默认 500
+	Rate   int // 每秒最多读取的行数，0 表示不限速
+	// Checkpoint 进度文件的路径
+	Checkpoint string
+	// Restart 忽略已有的进度从头开始
+	Restart bool
+}
+
+// Backfill 从 MySQL 按主键分批扫描一张表的每张分表，转换为与 binlog 相同的写入操作写入索引，用于索引丢失或新建后重建数据；
+// 同步评价时将每一行的 review_id 和 store_id 写入布隆过滤器
+//
+// 可以与消费 binlog 的 JobWorker 同时运行，两边的写入按版本号对齐：
+//   - 每批数据以读取时数据库的时间减去 catchUpMargin 作为版本号，读取之后（以及读取前不久）的 binlog 变更版本号更大，
+//     无论先于还是晚于这批数据写入，最终都以 binlog 为准；更早的 binlog 变更已经包含在读到的数据中，重复投递时被丢弃
+//   - 版本化的删除在文档不存在时不留痕迹，binlog 中的删除先于这批数据写入时会被旧数据覆盖，
+//     所以写入后重新读取这批行，被删除或被修改的行以新的版本号再写入一次
+type Backfill struct {
+	worker *JobWorker
+	opt    BackfillOption
+	shards []ShardTable
+	log    *log.Helper
+
+	// 正在同步的分表
+	db       *sql.DB
+	table    string
+	replies  string // 与评价位于同一个分片的回复表
+	database string
+}
+
+// NewBackfill 创建全量同步，按 dc 的 data.sharding 扫描每张分表，
+// 写入操作经 worker 的路由规则转换后按 worker 的重试策略批量写入，评价写入 worker 的布隆过滤器
+func NewBackfill(dc *conf.Data, worker *JobWorker, opt BackfillOption, logger log.Logger) (*Backfill, error) {
+	table, ok := backfillTables[opt.Index]
+	if !ok {
+		return nil, fmt.Errorf("unknown index %q", opt.Index)
+	}
+	if opt.Chunk <= 0 {
+		opt.Chunk = defaultBackfillChunk
+	}
+	return &Backfill{worker: worker, opt: opt, shards: shardTables(dc, table), log: log.NewHelper(logger)}, nil
+}
+
+// Run 从 checkpoint 处开始依次同步每张分表到末尾，返回最新的进度；ctx 取消时返回，没有写完的一批在下次运行时重新同步
+func (b *Backfill) Run(ctx context.Context) (*Checkpoint, error) {
+	cp, err := b.load()
+	if err != nil {
+		return nil, err
+	}
+	if cp.Done {
+		b.log.Infof("backfill of %s into %s finished at %s, use -restart to run again", cp.Index, cp.Target, cp.UpdateAt)
+		return cp, nil
+	}
+	first := 0
+	if cp.Table != "" {
+		for first < len(b.shards) && b.shards[first].Table != cp.Table {
+			first++
+		}
+		if first == len(b.shards) {
+			return nil, fmt.Errorf("checkpoint %s stopped at table %s which is not in data.sharding, use -restart", b.opt.Checkpoint, cp.Table)
+		}
+	}
+	for i := first; i < len(b.shards); i++ {
+		if cp.Table != b.shards[i].Table {
+			cp.Table, cp.LastID = b.shards[i].Table, 0
+		}
+		if err := b.runShard(ctx, b.shards[i], cp); err != nil {
+			return cp, err
+		}
+	}
+	cp.Done = true
+	cp.UpdateAt = time.Now()
+	if err := b.save(cp); err != nil {
+		return cp, err
+	}
+	if cp.Bloom {
+		if err := b.worker.bloom.MarkSeeded(ctx); err != nil {
+			return cp, fmt.Errorf("mark bloom filter seeded failed: %w", err)
+		}
+	}
+	return cp, nil
+}
+
+// runShard 从 cp.LastID 之后同步一张分表到末尾
+func (b *Backfill) runShard(ctx context.Context, shard ShardTable, cp *Checkpoint) error {
+	db, err := NewMySQL(shard.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	b.db, b.table = db, shard.Table
+	b.replies = replyTable + strings.TrimPrefix(shard.Table, backfillTables[b.opt.Index])
+	if err := b.db.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&b.database); err != nil {
+		return err
+	}
+	for {
+		start := time.Now()
+		rows, err := queryRows(ctx, b.db, fmt.Sprintf("SELECT * FROM %s WHERE id > ? ORDER BY id LIMIT ?", b.table), cp.LastID, b.opt.Chunk)
+		if err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			if err := b.sync(ctx, rows); err != nil {
+				return err
+			}
+			if cp.LastID, err = strconv.ParseInt(fmt.Sprint(rows[len(rows)-1]["id"]), 10, 64); err != nil {
+				return fmt.Errorf("parse id of %s: %w", b.table, err)
+			}
+			cp.Rows += int64(len(rows))
+		}
+		cp.UpdateAt = time.Now()
+		if err := b.save(cp); err != nil {
+			return err
+		}
+		b.log.Infof("backfilled %d rows, %s last id %d", cp.Rows, b.table, cp.LastID)
+		if len(rows) < b.opt.Chunk {
+			return nil
+		}
+		if err := b.throttle(ctx, start, len(rows)); err != nil {
+			return err
+		}
+	}
+}
+
+// sync 写入一批行及其回复，然后重新读取，补写写入期间被修改或删除的行
+func (b *Backfill) sync(ctx context.Context, rows []map[string]interface{}) error {
+	version, err := dbVersion(ctx, b.db)
+	if err != nil {
+		return err
+	}
+	var replies []map[string]interface{}
+	if b.opt.Index == IndexReview {
+		// 已删除的行留在过滤器中只会让查询多访问一次数据库
+		if err := b.worker.bloom.AddReviews(ctx, rows); err != nil {
+			return fmt.Errorf("add reviews of %s to bloom filter failed: %w", b.table, err)
+		}
+		if replies, err = b.reviewReplies(ctx, rows); err != nil {
+			return err
+		}
+	}
+	if err := b.worker.apply(ctx, append(b.messages(b.table, rows, nil, version), b.messages(b.replies, replies, nil, version)...)); err != nil {
+		return err
+	}
+
+	version, err = dbVersion(ctx, b.db)
+	if err != nil {
+		return err
+	}
+	current, err := queryRows(ctx, b.db, fmt.Sprintf("SELECT * FROM %s WHERE id IN (%s)", b.table, placeholders(len(rows))), column(rows, "id")...)
+	if err != nil {
+		return err
+	}
+	msgs := b.messages(b.table, changed(rows, current), deleted(rows, current), version)
+	if b.opt.Index == IndexReview {
+		currentReplies, err := b.reviewReplies(ctx, rows)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, b.messages(b.replies, changed(replies, currentReplies), deleted(replies, currentReplies), version)...)
+	}
+	return b.worker.apply(ctx, msgs)
+}
+
+// reviewReplies 从同一个分片的回复表读取一批评价的回复
+func (b *Backfill) reviewReplies(ctx context.Context, reviews []map[string]interface{}) ([]map[string]interface{}, error) {
+	ids := column(reviews, "review_id")
+	return queryRows(ctx, b.db, fmt.Sprintf("SELECT * FROM %s WHERE review_id IN (%s) ORDER BY id", b.replies, placeholders(len(ids))), ids...)
+}
+
+// messages 将读到的行包装为 canal 消息，交给与 binlog 相同的路由规则转换
+func (b *Backfill) messages(table string, rows, deletes []map[string]interface{}, version int64) []*Msg {
+	var msgs []*Msg
+	if len(rows) > 0 {
+		msgs = append(msgs, &Msg{Type: "INSERT", Database: b.database, Table: table, Es: version, Data: rows})
+	}
+	if len(deletes) > 0 {
+		msgs = append(msgs, &Msg{Type: "DELETE", Database: b.database, Table: table, Es: version, Data: deletes})
+	}
+	return msgs
+}
+
+// apply 将从 MySQL 读到的行按路由规则转换后写入，评价已被删除导致回复写入失败（search.ErrNotFound）时跳过，
+// 其余失败返回错误，backfill 的这一批不计入进度
+func (jw JobWorker) apply(ctx context.Context, msgs []*Msg) error {
+	var writes []Write
+	for _, msg := range msgs {
+		w, err := jw.router.Route(ctx, msg)
+		if err != nil {
+			return fmt.Errorf("convert rows of %s: %w", msg.Table, err)
+		}
+		writes = append(writes, w...)
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	rejects, err := jw.write(ctx, writes)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, r := range rejects {
+		if errors.Is(r.err, search.ErrNotFound) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("document %s: %w", writes[r.op].ID, r.err))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d documents rejected: %w", len(errs), errors.Join(errs...))
+	}
+	return nil
+}
+
+// dbVersion 数据库当前时间（毫秒）减去 catchUpMargin，作为从 MySQL 读到的数据写入索引时的版本号
+// 使用数据库的时钟与 binlog 的时间对齐；减去的一段时间让执行较久、读取时还未提交的事务的 binlog 也能覆盖这批数据
+func dbVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	var now int64
+	if err := db.QueryRowContext(ctx, "SELECT CAST(UNIX_TIMESTAMP(NOW(3)) * 1000 AS SIGNED)").Scan(&now); err != nil {
+		return 0, err
+	}
+	return now - catchUpMargin.Milliseconds(), nil
+}
+
+// queryRows 执行查询，每一行以列名到文本值的 map 返回，NULL 为 nil，与 canal 消息中的行一致
+func queryRows(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []map[string]interface{}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, name := range columns {
+			if values[i].Valid {
+				row[name] = values[i].String
+			} else {
+				row[name] = nil
+			}
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// throttle 按 Rate 限速，读取 n 行至少需要 n/Rate 秒
+func (b *Backfill) throttle(ctx context.Context, start time.Time, n int) error {
+	if b.opt.Rate <= 0 {
+		return ctx.Err()
+	}
+	wait := time.Duration(n)*time.Second/time.Duration(b.opt.Rate) - time.Since(start)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// load 读取进度文件，不存在或 Restart 时从头开始
+func (b *Backfill) load() (*Checkpoint, error) {
+	fresh := &Checkpoint{Index: b.opt.Index, Target: b.opt.Target, StartAt: time.Now()}
+	fresh.Bloom = b.opt.Index == IndexReview && b.worker.bloom.Enabled()
+	if b.opt.Restart {
+		return fresh, nil
+	}
+	data, err := os.ReadFile(b.opt.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cp := new(Checkpoint)
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", b.opt.Checkpoint, err)
+	}
+	if cp.Index != b.opt.Index || cp.Target != b.opt.Target {
+		return nil, fmt.Errorf("checkpoint %s belongs to index %s (target %q), use -restart or another checkpoint", b.opt.Checkpoint, cp.Index, cp.Target)
+	}
+	// 这次没有开启布隆过滤器，已经同步的部分也不能算作写入了过滤器
+	cp.Bloom = cp.Bloom && b.worker.bloom.Enabled()
+	b.log.Infof("resume backfill of %s from %s id %d, %d rows done", cp.Index, cp.Table, cp.LastID, cp.Rows)
+	return cp, nil
+}
+
+// save 先写临时文件再重命名，中断时不会留下写了一半的进度
+func (b *Backfill) save(cp *Checkpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := b.opt.Checkpoint + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.opt.Checkpoint)
+}
+
+// changed 重新读取时内容发生变化的行
+func changed(before, after []map[string]interface{}) []map[string]interface{} {
+	old := byID(before)
+	var rows []map[string]interface{}
+	for _, row := range after {
+		if prev, ok := old[fmt.Sprint(row["id"])]; !ok || !reflect.DeepEqual(prev, row) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// deleted 重新读取时已经不存在的行
+func deleted(before, after []map[string]interface{}) []map[string]interface{} {
+	current := byID(after)
+	var rows []map[string]interface{}
+	for _, row := range before {
+		if _, ok := current[fmt.Sprint(row["id"])]; !ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func byID(rows []map[string]interface{}) map[string]map[string]interface{} {
+	m := make(map[string]map[string]interface{}, len(rows))
+	for _, row := range rows {
+		m[fmt.Sprint(row["id"])] = row
+	}
+	return m
+}
+
+func column(rows []map[string]interface{}, name string) []interface{} {
+	values := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, row[name])
+	}
+	return values
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
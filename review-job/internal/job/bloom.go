@@ -0,0 +1,172 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"review-job/internal/conf"
+)
+
+const (
+	defaultBloomReviewKey = "bloom:review_id"
+	defaultBloomStoreKey  = "bloom:store_id"
+	defaultBloomReadyKey  = "bloom:ready"
+	defaultBloomErrorRate = 0.001
+	defaultBloomCapacity  = 10000000
+)
+
+// BloomFilter 基于 RedisBloom 维护已存在的 review_id 和 store_id
+// review-service 在查询 MySQL / ES 之前检查它，拦截不存在的 ID。
+// binlog 只包含新的变更，存量数据由 Seed 写入，全部写入后 MarkSeeded，review-service 看到标记后才开始检查
+type BloomFilter struct {
+	rdb       *redis.Client
+	reviewKey string
+	storeKey  string
+	readyKey  string
+}
+
+func NewBloomFilter(cfg *conf.Data) (*BloomFilter, error) {
+	if cfg.GetBloom() == nil || !cfg.Bloom.Enabled {
+		return &BloomFilter{}, nil
+	}
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       int(cfg.Redis.Db),
+	})
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		return nil, err
+	}
+	bf := &BloomFilter{
+		rdb:       rdb,
+		reviewKey: defaultBloomReviewKey,
+		storeKey:  defaultBloomStoreKey,
+		readyKey:  defaultBloomReadyKey,
+	}
+	if cfg.Bloom.ReadyKey != "" {
+		bf.readyKey = cfg.Bloom.ReadyKey
+	}
+	if cfg.Bloom.ReviewKey != "" {
+		bf.reviewKey = cfg.Bloom.ReviewKey
+	}
+	if cfg.Bloom.StoreKey != "" {
+		bf.storeKey = cfg.Bloom.StoreKey
+	}
+	errorRate, capacity := cfg.Bloom.ErrorRate, cfg.Bloom.Capacity
+	if errorRate <= 0 {
+		errorRate = defaultBloomErrorRate
+	}
+	if capacity <= 0 {
+		capacity = defaultBloomCapacity
+	}
+	for _, key := range []string{bf.reviewKey, bf.storeKey} {
+		if err := bf.reserve(key, errorRate, capacity); err != nil {
+			return nil, err
+		}
+	}
+	return bf, nil
+}
+
+// reserve 按配置创建过滤器，已存在时忽略
+func (bf *BloomFilter) reserve(key string, errorRate float64, capacity int64) error {
+	err := bf.rdb.Do(context.Background(), "BF.RESERVE", key, errorRate, capacity).Err()
+	if err != nil && !strings.Contains(err.Error(), "exists") {
+		return fmt.Errorf("bloom reserve %s failed: %w", key, err)
+	}
+	return nil
+}
+
+// Enabled 是否开启布隆过滤器
+func (bf *BloomFilter) Enabled() bool {
+	return bf != nil && bf.rdb != nil
+}
+
+// AddReview 将评价的 review_id 和 store_id 加入过滤器
+func (bf *BloomFilter) AddReview(ctx context.Context, d map[string]interface{}) error {
+	if !bf.Enabled() {
+		return nil
+	}
+	pipe := bf.rdb.Pipeline()
+	if v, ok := d["review_id"]; ok && v != nil {
+		pipe.Do(ctx, "BF.ADD", bf.reviewKey, fmt.Sprint(v))
+	}
+	if v, ok := d["store_id"]; ok && v != nil {
+		pipe.Do(ctx, "BF.ADD", bf.storeKey, fmt.Sprint(v))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// AddReviews 将一批评价的 review_id 和 store_id 加入过滤器
+func (bf *BloomFilter) AddReviews(ctx context.Context, rows []map[string]interface{}) error {
+	if !bf.Enabled() || len(rows) == 0 {
+		return nil
+	}
+	reviewIDs := make([]interface{}, 0, len(rows)+2)
+	storeIDs := make([]interface{}, 0, len(rows)+2)
+	reviewIDs = append(reviewIDs, "BF.MADD", bf.reviewKey)
+	storeIDs = append(storeIDs, "BF.MADD", bf.storeKey)
+	for _, row := range rows {
+		if v := row["review_id"]; v != nil {
+			reviewIDs = append(reviewIDs, fmt.Sprint(v))
+		}
+		if v := row["store_id"]; v != nil {
+			storeIDs = append(storeIDs, fmt.Sprint(v))
+		}
+	}
+	pipe := bf.rdb.Pipeline()
+	for _, args := range [][]interface{}{reviewIDs, storeIDs} {
+		if len(args) > 2 {
+			pipe.Do(ctx, args...)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Seed 按主键分批扫描一张评价表，将存量的 review_id 和 store_id 加入过滤器，返回扫描的行数
+func (bf *BloomFilter) Seed(ctx context.Context, db *sql.DB, table string, chunk int) (int64, error) {
+	if !bf.Enabled() {
+		return 0, nil
+	}
+	if chunk <= 0 {
+		chunk = defaultBackfillChunk
+	}
+	var lastID, total int64
+	for {
+		rows, err := queryRows(ctx, db, fmt.Sprintf("SELECT id, review_id, store_id FROM %s WHERE id > ? ORDER BY id LIMIT ?", table), lastID, chunk)
+		if err != nil {
+			return total, err
+		}
+		if err := bf.AddReviews(ctx, rows); err != nil {
+			return total, fmt.Errorf("seed bloom from %s failed: %w", table, err)
+		}
+		total += int64(len(rows))
+		if len(rows) < chunk {
+			return total, nil
+		}
+		if lastID, err = strconv.ParseInt(fmt.Sprint(rows[len(rows)-1]["id"]), 10, 64); err != nil {
+			return total, fmt.Errorf("parse id of %s: %w", table, err)
+		}
+	}
+}
+
+// MarkSeeded 标记存量数据已全部写入，之后 review-service 开始检查过滤器
+func (bf *BloomFilter) MarkSeeded(ctx context.Context) error {
+	if !bf.Enabled() {
+		return nil
+	}
+	return bf.rdb.Set(ctx, bf.readyKey, "1", 0).Err()
+}
+
+// Close 关闭 redis 连接
+func (bf *BloomFilter) Close() error {
+	if !bf.Enabled() {
+		return nil
+	}
+	return bf.rdb.Close()
+}
@@ -0,0 +1,275 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"review-job/internal/conf"
+	"review-service/pkg/search"
+)
+
+const (
+	defaultBulkActions  = 500
+	defaultBulkBytes    = 5 << 20 // 5MB
+	defaultBulkInterval = time.Second
+	defaultBulkRetries  = 5
+	defaultBackoff      = 100 * time.Millisecond
+	defaultMaxBackoff   = 10 * time.Second
+)
+
+// errIndexBlocked 索引被阻塞写入，重建索引切换别名后即可写入，一直重试而不计入重试次数
+var errIndexBlocked = errors.New("index blocked")
+
+// bulkOption 批量写入的刷新条件和重试策略
+type bulkOption struct {
+	actions    int
+	bytes      int64
+	interval   time.Duration
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+func newBulkOption(cfg *conf.Search) bulkOption {
+	opt := bulkOption{
+		actions:    defaultBulkActions,
+		bytes:      defaultBulkBytes,
+		interval:   defaultBulkInterval,
+		retries:    defaultBulkRetries,
+		backoff:    defaultBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	b := cfg.GetBulk()
+	if b.GetActions() > 0 {
+		opt.actions = int(b.GetActions())
+	}
+	if b.GetBytes() > 0 {
+		opt.bytes = b.GetBytes()
+	}
+	if b.GetInterval().AsDuration() > 0 {
+		opt.interval = b.GetInterval().AsDuration()
+	}
+	if b.GetRetries() > 0 {
+		opt.retries = int(b.GetRetries())
+	}
+	if b.GetBackoff().AsDuration() > 0 {
+		opt.backoff = b.GetBackoff().AsDuration()
+	}
+	if b.GetMaxBackoff().AsDuration() > 0 {
+		opt.maxBackoff = b.GetMaxBackoff().AsDuration()
+	}
+	return opt
+}
+
+// wait 第 attempt 次重试前的等待时间，从 backoff 开始指数增长，不超过 maxBackoff
+func (opt bulkOption) wait(attempt int) time.Duration {
+	d := opt.backoff
+	for i := 0; i < attempt && d < opt.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > opt.maxBackoff {
+		d = opt.maxBackoff
+	}
+	return d
+}
+
+// batch 一个 worker 攒批中的写入操作，以及产生这些操作的 kafka 消息
+type batch struct {
+	writes  []Write
+	src     []int // 每个操作来自 msgs 中的哪条消息
+	msgs    []kafka.Message
+	items   []*tracked // 与 msgs 一一对应，刷新后标记为已处理
+	letters []Letter   // 需要写入死信队列的消息
+	bytes   int64      // 按 kafka 消息的大小估算请求体大小
+}
+
+// add 加入一条消息，letter 不为空时该消息在刷新时写入死信队列
+func (b *batch) add(item *tracked, writes []Write, letter *Letter) {
+	for range writes {
+		b.src = append(b.src, len(b.msgs))
+	}
+	b.writes = append(b.writes, writes...)
+	b.msgs = append(b.msgs, item.m)
+	b.items = append(b.items, item)
+	b.bytes += int64(len(item.m.Value))
+	if letter != nil {
+		b.letters = append(b.letters, *letter)
+	}
+}
+
+// reject 被拒绝的操作所在的消息写入死信队列，同一条消息只写一次
+func (b *batch) reject(rejects []rejection) {
+	seen := map[int]bool{}
+	for _, r := range rejects {
+		src := b.src[r.op]
+		if seen[src] {
+			continue
+		}
+		seen[src] = true
+		b.letters = append(b.letters, Letter{Msg: b.msgs[src], Reason: ReasonRejected, DocumentID: b.writes[r.op].ID, Err: r.err})
+	}
+}
+
+func (b *batch) full(opt bulkOption) bool {
+	return len(b.writes) >= opt.actions || b.bytes >= opt.bytes
+}
+
+func (b *batch) reset() {
+	b.writes = b.writes[:0]
+	b.src = b.src[:0]
+	b.msgs = b.msgs[:0]
+	b.items = b.items[:0]
+	b.letters = b.letters[:0]
+	b.bytes = 0
+}
+
+// rejection 无法重试的失败操作，op 为在 writes 中的下标
+type rejection struct {
+	op  int
+	err error
+}
+
+// write 通过 _bulk 写入 writes，失败时按指数退避重试，最多 retries 次，返回无法重试的操作
+// 请求整体失败时整批重试，部分操作因限流等临时错误失败时按原顺序只重试这些操作；
+// 索引被阻塞写入时一直重试，直到重建索引切换别名或解除阻塞；
+// 重试是安全的：写入都带有版本号，重复写入的旧数据返回版本冲突，相同版本重复写入结果相同
+func (jw JobWorker) write(ctx context.Context, writes []Write) ([]rejection, error) {
+	pending := make([]int, len(writes))
+	for i := range pending {
+		pending[i] = i
+	}
+	var rejects []rejection
+	for attempt := 0; len(pending) > 0; attempt++ {
+		retry, rejected, err := jw.bulkOnce(ctx, writes, pending)
+		rejects = append(rejects, rejected...)
+		if err == nil {
+			if len(retry) == 0 {
+				break
+			}
+			err = fmt.Errorf("%d ops failed with retryable errors", len(retry))
+		}
+		pending = retry
+		if attempt >= jw.bulk.retries && !errors.Is(err, errIndexBlocked) {
+			return nil, fmt.Errorf("bulk failed after %d retries: %w", attempt, err)
+		}
+		wait := jw.bulk.wait(attempt)
+		jw.logger.Warnf("bulk %d ops failed, retry in %s, err:%v", len(pending), wait, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return rejects, nil
+}
+
+// bulkOnce 按索引分组，每个索引执行一次 _bulk 写入 writes 中下标为 pending 的操作，
+// 返回需要重试和无法重试的操作；某个索引的请求整体失败时，该索引的操作全部需要重试，
+// 索引被阻塞写入时只重试被阻塞的操作
+func (jw JobWorker) bulkOnce(ctx context.Context, writes []Write, pending []int) ([]int, []rejection, error) {
+	var (
+		names   []string
+		groups  = map[string][]int{}
+		retry   []int
+		rejects []rejection
+		errs    []error
+	)
+	for _, i := range pending {
+		name := writes[i].Index
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], i)
+	}
+	for _, name := range names {
+		group := groups[name]
+		index, ok := jw.indices[name]
+		if !ok {
+			for _, i := range group {
+				rejects = append(rejects, rejection{op: i, err: fmt.Errorf("unknown index %q", name)})
+			}
+			continue
+		}
+		r, rejected, err := jw.bulkIndex(ctx, index, writes, group)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			if !errors.Is(err, errIndexBlocked) {
+				r = group
+			}
+		}
+		retry = append(retry, r...)
+		rejects = append(rejects, rejected...)
+	}
+	sort.Ints(retry)
+	return retry, rejects, errors.Join(errs...)
+}
+
+// bulkIndex 执行一次 _bulk 写入同一个索引的操作，有操作因索引被阻塞写入失败时返回 errIndexBlocked
+func (jw JobWorker) bulkIndex(ctx context.Context, index search.Index, writes []Write, pending []int) ([]int, []rejection, error) {
+	sub := make([]search.Op, 0, len(pending))
+	for _, i := range pending {
+		sub = append(sub, writes[i].Op)
+	}
+	errs, err := index.Bulk(ctx, sub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bulk %d ops failed: %w", len(sub), err)
+	}
+	var (
+		retry   []int
+		rejects []rejection
+		blocked int
+	)
+	for i, e := range errs {
+		if e == nil || superseded(sub, errs, i) {
+			continue
+		}
+		if errors.Is(e, search.ErrConflict) {
+			// 索引中已经是更新的数据，重复投递或乱序到达的旧消息直接跳过
+			jw.logger.Debugf("skip stale %s of document %s at version %d", actionName(sub[i].Action), sub[i].ID, sub[i].Version)
+			continue
+		}
+		var itemErr *search.ItemError
+		if errors.As(e, &itemErr) && itemErr.Retryable() {
+			if itemErr.Blocked() {
+				blocked++
+			}
+			retry = append(retry, pending[i])
+			continue
+		}
+		jw.logger.Errorf("bulk %s document %s failed, err:%v", actionName(sub[i].Action), sub[i].ID, e)
+		rejects = append(rejects, rejection{op: pending[i], err: e})
+	}
+	if blocked > 0 {
+		return retry, rejects, fmt.Errorf("%d ops: %w", blocked, errIndexBlocked)
+	}
+	return retry, rejects, nil
+}
+
+// superseded 同一批中同一文档之后还有使用同一版本号字段的整篇写入、upsert 或删除成功，
+// 失败的操作不再重试，避免旧数据覆盖新数据
+func superseded(ops []search.Op, errs []error, i int) bool {
+	for j := i + 1; j < len(ops); j++ {
+		if ops[j].ID == ops[i].ID && ops[j].VersionField == ops[i].VersionField && errs[j] == nil && ops[j].Action != search.ActionUpdate {
+			return true
+		}
+	}
+	return false
+}
+
+func actionName(a search.Action) string {
+	switch a {
+	case search.ActionIndex:
+		return "index"
+	case search.ActionUpdate:
+		return "update"
+	case search.ActionDelete:
+		return "delete"
+	case search.ActionUpsert:
+		return "upsert"
+	}
+	return "unknown"
+}
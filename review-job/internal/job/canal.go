@@ -0,0 +1,264 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Q1mi/canal-go/client"
+	"github.com/Q1mi/canal-go/protocol"
+	pbe "github.com/Q1mi/canal-go/protocol/entry"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"review-job/internal/conf"
+)
+
+const (
+	defaultCanalDestination = "example"
+	defaultCanalFilter      = `.*\.(review_info|review_reply_info|review_appeal_info)`
+	defaultCanalBatchSize   = 1000
+	defaultCanalTimeout     = 1000 // 毫秒
+	canalMilliseconds       = 2    // canal 协议中 TimeUnit.MILLISECONDS 的序号
+)
+
+// canalBatch 一批已拉取但还没有确认的数据，last 为这批数据中最后一条消息的 offset
+type canalBatch struct {
+	id   int64
+	last int64
+}
+
+// CanalSource 直接连接 canal-server 订阅 binlog，每个 entry 转换为一条与 canal kafka 模式相同的 canal-json 消息
+// 消息都在分区 0 中，offset 按拉取的顺序递增；canal-server 要求按顺序确认，
+// 一批数据中的消息都提交后才确认这一批，未确认的批次在重新连接时由 canal-server 重新投递
+type CanalSource struct {
+	conn    *client.SimpleCanalConnector
+	batch   int32
+	timeout int64
+	log     *log.Helper
+
+	// committed 已提交的最大 offset；提交只记录 offset，确认在下一次拉取前进行，worker 不会等待网络请求
+	committed atomic.Int64
+
+	// 连接不支持并发请求，拉取、确认和断开串行执行
+	mu       sync.Mutex
+	buffered []kafka.Message
+	batches  []canalBatch // 按拉取的顺序
+	next     int64        // 下一条消息的 offset
+	closed   bool
+}
+
+// NewCanalSource 连接 canal-server 并订阅评价相关的表
+func NewCanalSource(cfg *conf.Source_Canal, logger log.Logger) (*CanalSource, error) {
+	host, p, err := net.SplitHostPort(cfg.GetAddr())
+	if err != nil {
+		return nil, fmt.Errorf("invalid canal addr %q: %w", cfg.GetAddr(), err)
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid canal addr %q: %w", cfg.GetAddr(), err)
+	}
+	destination := cfg.GetDestination()
+	if destination == "" {
+		destination = defaultCanalDestination
+	}
+	filter := cfg.GetFilter()
+	if filter == "" {
+		filter = defaultCanalFilter
+	}
+	s := &CanalSource{
+		batch:   defaultCanalBatchSize,
+		timeout: defaultCanalTimeout,
+		log:     log.NewHelper(logger),
+	}
+	s.committed.Store(-1)
+	if cfg.GetBatchSize() > 0 {
+		s.batch = cfg.GetBatchSize()
+	}
+	if cfg.GetTimeout().AsDuration() > 0 {
+		s.timeout = cfg.GetTimeout().AsDuration().Milliseconds()
+	}
+	s.conn = client.NewSimpleCanalConnector(host, port, cfg.GetUsername(), cfg.GetPassword(), destination, 60000, 60*60*1000)
+	if err := s.conn.Connect(); err != nil {
+		return nil, fmt.Errorf("connect canal-server %s failed: %w", cfg.GetAddr(), err)
+	}
+	if err := s.conn.Subscribe(filter); err != nil {
+		_ = s.conn.DisConnection()
+		return nil, fmt.Errorf("subscribe %s failed: %w", filter, err)
+	}
+	s.log.Infof("subscribed %s on canal-server %s/%s", filter, cfg.GetAddr(), destination)
+	return s, nil
+}
+
+// FetchMessage 返回下一条消息，没有新数据时阻塞；连接出错时返回错误，由进程管理重启后重新投递未确认的数据
+func (s *CanalSource) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return kafka.Message{}, err
+		}
+		m, ok, err := s.fetch()
+		if err != nil || ok {
+			return m, err
+		}
+	}
+}
+
+// fetch 确认已提交的批次后取出一条已拉取的消息，没有时向 canal-server 拉取一批，最多等待 timeout
+func (s *CanalSource) fetch() (m kafka.Message, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return m, false, io.EOF
+	}
+	if err := s.ack(); err != nil {
+		return m, false, err
+	}
+	if len(s.buffered) > 0 {
+		m, s.buffered = s.buffered[0], s.buffered[1:]
+		return m, true, nil
+	}
+	msg, err := s.get()
+	if err != nil {
+		return m, false, fmt.Errorf("get from canal-server failed: %w", err)
+	}
+	if msg == nil || msg.Id == -1 {
+		return m, false, nil
+	}
+	for _, entry := range msg.Entries {
+		header := entry.GetHeader()
+		value, err := canalEntry(entry)
+		if err != nil {
+			return m, false, fmt.Errorf("decode canal entry at %s:%d failed: %w", header.GetLogfileName(), header.GetLogfileOffset(), err)
+		}
+		if value == nil {
+			continue
+		}
+		s.buffered = append(s.buffered, kafka.Message{
+			Key:    []byte(header.GetSchemaName() + "." + header.GetTableName()),
+			Value:  value,
+			Offset: s.next,
+			Time:   time.UnixMilli(header.GetExecuteTime()),
+		})
+		s.next++
+	}
+	// 只有事务开始、结束的批次没有消息，随前一批一起确认
+	s.batches = append(s.batches, canalBatch{id: msg.Id, last: s.next - 1})
+	return m, false, nil
+}
+
+// get 拉取一批数据但不确认，canal-go 在收到错误响应时会 panic，转换为错误返回
+func (s *CanalSource) get() (msg *protocol.Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	units := int32(canalMilliseconds)
+	return s.conn.GetWithOutAck(s.batch, &s.timeout, &units)
+}
+
+// CommitMessages 记录已提交的 offset，其中消息都已提交的批次在下一次拉取或关闭时确认
+func (s *CanalSource) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, m := range msgs {
+		for {
+			committed := s.committed.Load()
+			if m.Offset <= committed || s.committed.CompareAndSwap(committed, m.Offset) {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ack 按顺序确认已经全部提交的批次
+func (s *CanalSource) ack() error {
+	committed := s.committed.Load()
+	n := 0
+	for n < len(s.batches) && s.batches[n].last <= committed {
+		if err := s.conn.Ack(s.batches[n].id); err != nil {
+			return fmt.Errorf("ack canal batch %d failed: %w", s.batches[n].id, err)
+		}
+		n++
+	}
+	s.batches = s.batches[n:]
+	return nil
+}
+
+// Close 确认已提交的批次后断开连接，canal-server 会回滚其余没有确认的批次
+func (s *CanalSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if err := s.ack(); err != nil {
+		s.log.Errorf("ack before close failed, err:%v", err)
+	}
+	return s.conn.DisConnection()
+}
+
+// canalEntry 将一个 entry 转换为 canal-json 格式，与 canal-server 以 kafka 模式投递的 flatMessage 一致
+// 事务开始、结束等不是行变更的 entry 返回 nil
+func canalEntry(entry *pbe.Entry) ([]byte, error) {
+	if entry.GetEntryType() != pbe.EntryType_ROWDATA {
+		return nil, nil
+	}
+	rc := new(pbe.RowChange)
+	if err := proto.Unmarshal(entry.GetStoreValue(), rc); err != nil {
+		return nil, err
+	}
+	header := entry.GetHeader()
+	msg := &Msg{
+		Type:     rc.GetEventType().String(),
+		Table:    header.GetTableName(),
+		Database: header.GetSchemaName(),
+		IsDdl:    rc.GetIsDdl(),
+		Es:       header.GetExecuteTime(),
+		SQL:      rc.GetSql(),
+	}
+	if !msg.IsDdl {
+		msg.SQL = ""
+		msg.MysqlType = map[string]string{}
+		for _, row := range rc.GetRowDatas() {
+			columns := row.GetAfterColumns()
+			if rc.GetEventType() == pbe.EventType_DELETE {
+				columns = row.GetBeforeColumns()
+			}
+			data := make(map[string]interface{}, len(columns))
+			for _, col := range columns {
+				data[col.GetName()] = nil
+				if !col.GetIsNull() {
+					data[col.GetName()] = col.GetValue()
+				}
+				msg.MysqlType[col.GetName()] = col.GetMysqlType()
+			}
+			msg.Data = append(msg.Data, data)
+			// 与 flatMessage 一致，修改时 old 只带有修改的列修改前的值
+			if rc.GetEventType() == pbe.EventType_UPDATE {
+				updated := map[string]bool{}
+				for _, col := range columns {
+					updated[col.GetName()] = col.GetUpdated()
+				}
+				old := map[string]interface{}{}
+				for _, col := range row.GetBeforeColumns() {
+					if !updated[col.GetName()] {
+						continue
+					}
+					old[col.GetName()] = nil
+					if !col.GetIsNull() {
+						old[col.GetName()] = col.GetValue()
+					}
+				}
+				msg.Old = append(msg.Old, old)
+			}
+		}
+	}
+	return json.Marshal(msg)
+}
@@ -0,0 +1,305 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"review-job/internal/conf"
+	"review-service/pkg/event"
+)
+
+const (
+	defaultCreateStatusTTL = 24 * time.Hour
+	// createFailedOrderReviewed 订单在 MySQL 中已经有另一条评价，如同步创建的评价
+	createFailedOrderReviewed = "order already reviewed"
+	// createFailedRetries 重试后仍无法写入，命令已写入死信队列
+	createFailedRetries = "internal error"
+)
+
+// ReviewCreator 消费 review-service 异步创建评价的命令并写入 MySQL，实现 transport.Server
+// 命令按 review_id 幂等写入：评价和它的 review.created 事件在同一个事务中写入分表和发件箱，
+// 重复消费时评价已存在，不再写入事件。写入后更新 redis 中的创建进度，失败时释放订单，之后才提交 offset；
+// 数据库或 redis 出错时按指数退避最多重试 retries 次，用尽后命令写入死信队列，创建进度标记为失败并释放订单，
+// 不阻塞之后的命令；停止时未提交的命令在重启后重新消费
+type ReviewCreator struct {
+	topic     string
+	groupID   string
+	brokers   []string
+	ttl       time.Duration
+	redis     *conf.Data_Redis
+	databases []*conf.Data_Database
+	sharding  bool
+	tables    int
+	loc       *time.Location
+	dlq       *DeadLetter
+	retry     bulkOption
+	log       *log.Helper
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewReviewCreator 未配置 async_create.topic 时 Start 直接返回，不连接 kafka、数据库和 redis
+func NewReviewCreator(cfg *conf.AsyncCreate, kc *conf.Kafka, dc *conf.Data, tf *Transformer, dlq *DeadLetter, logger log.Logger) *ReviewCreator {
+	c := &ReviewCreator{
+		topic:    cfg.GetTopic(),
+		groupID:  cfg.GetGroupId(),
+		brokers:  kc.GetBrokers(),
+		ttl:      defaultCreateStatusTTL,
+		redis:    dc.GetRedis(),
+		sharding: dc.GetSharding().GetEnabled(),
+		tables:   int(dc.GetSharding().GetTableCount()),
+		loc:      tf.loc,
+		dlq:      dlq,
+		retry:    bulkOption{retries: defaultBulkRetries, backoff: defaultBackoff, maxBackoff: defaultMaxBackoff},
+		log:      log.NewHelper(logger),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if c.groupID == "" {
+		c.groupID = kc.GetGroupId() + "-create"
+	}
+	if cfg.GetStatusTtl().AsDuration() > 0 {
+		c.ttl = cfg.GetStatusTtl().AsDuration()
+	}
+	if cfg.GetRetries() > 0 {
+		c.retry.retries = int(cfg.GetRetries())
+	}
+	if c.tables <= 0 {
+		c.sharding = false
+	}
+	for _, dsn := range dc.GetSharding().GetDatabases() {
+		c.databases = append(c.databases, &conf.Data_Database{Driver: dc.GetDatabase().GetDriver(), Source: dsn})
+	}
+	if len(c.databases) == 0 {
+		c.databases = append(c.databases, dc.GetDatabase())
+	}
+	return c
+}
+
+// Start 逐条消费命令，直到 Stop
+func (c *ReviewCreator) Start(ctx context.Context) error {
+	defer close(c.done)
+	if c.topic == "" {
+		c.log.Info("async create topic is not configured, creator disabled")
+		return nil
+	}
+	dbs := make([]*sql.DB, 0, len(c.databases))
+	defer func() {
+		for _, db := range dbs {
+			_ = db.Close()
+		}
+	}()
+	for _, dc := range c.databases {
+		db, err := NewMySQL(dc)
+		if err != nil {
+			return fmt.Errorf("connect review database failed: %w", err)
+		}
+		dbs = append(dbs, db)
+	}
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     c.redis.GetAddr(),
+		Password: c.redis.GetPassword(),
+		DB:       int(c.redis.GetDb()),
+	})
+	defer rdb.Close()
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: c.brokers,
+		GroupID: c.groupID,
+		Topic:   c.topic,
+	})
+	defer reader.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	c.log.Infof("review creator started, topic: %s, group: %s", c.topic, c.groupID)
+	for {
+		m, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("fetch create command failed: %w", err)
+		}
+		if err := c.handle(ctx, dbs, rdb, m); err != nil {
+			// 只有停止时才会返回，命令没有处理完，不提交
+			return nil
+		}
+		if err := reader.CommitMessages(ctx, m); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("commit create command failed: %w", err)
+		}
+	}
+}
+
+// Stop 停止消费，等待正在写入的命令处理完，超过 ctx 的期限后直接返回
+func (c *ReviewCreator) Stop(ctx context.Context) error {
+	c.stopOnce.Do(func() { close(c.stop) })
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		c.log.Warn("stop timeout, the in-flight create command will be consumed again after restart")
+	}
+	return nil
+}
+
+// handle 处理一条命令，无法解析的命令写入死信队列后跳过；其余错误退避重试，
+// 重试用尽后写入死信队列，创建进度标记为失败后跳过；只在 ctx 取消时返回错误
+func (c *ReviewCreator) handle(ctx context.Context, dbs []*sql.DB, rdb *redis.Client, m kafka.Message) error {
+	cmd := new(event.CreateReviewCommand)
+	if err := proto.Unmarshal(m.Value, cmd); err != nil || cmd.GetReviewId() == 0 {
+		if err == nil {
+			err = errors.New("missing review_id")
+		}
+		c.log.Errorf("decode create command at %s/%d/%d failed, err:%v", m.Topic, m.Partition, m.Offset, err)
+		if err := c.dlq.Publish(ctx, Letter{Msg: m, Reason: ReasonUnmarshal, Err: err}); err != nil {
+			c.log.Errorf("publish create command to dead letter failed, err:%v", err)
+		}
+		return nil
+	}
+	for attempt := 0; ; attempt++ {
+		err := c.create(ctx, dbs, rdb, cmd)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.log.Errorf("create review %d failed, attempt %d, err:%v", cmd.GetReviewId(), attempt+1, err)
+		if attempt >= c.retry.retries {
+			c.giveUp(ctx, rdb, m, cmd, err)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.retry.wait(attempt)):
+		}
+	}
+}
+
+// giveUp 重试用尽：命令写入死信队列，修复后可以重放；创建进度标记为失败并释放订单，用户可以重新提交
+func (c *ReviewCreator) giveUp(ctx context.Context, rdb *redis.Client, m kafka.Message, cmd *event.CreateReviewCommand, err error) {
+	if err := c.dlq.Publish(ctx, Letter{Msg: m, Reason: ReasonCreate, DocumentID: strconv.FormatInt(cmd.GetReviewId(), 10), Err: err}); err != nil {
+		c.log.Errorf("publish create command of review %d to dead letter failed, err:%v", cmd.GetReviewId(), err)
+	}
+	if err := c.setStatus(ctx, rdb, cmd, createFailedRetries); err != nil {
+		c.log.Errorf("mark review %d as failed failed, err:%v", cmd.GetReviewId(), err)
+	}
+}
+
+// create 写入评价后更新创建进度
+func (c *ReviewCreator) create(ctx context.Context, dbs []*sql.DB, rdb *redis.Client, cmd *event.CreateReviewCommand) error {
+	reason, err := c.save(ctx, dbs, cmd)
+	if err != nil {
+		return err
+	}
+	return c.setStatus(ctx, rdb, cmd, reason)
+}
+
+// setStatus 更新创建进度，reason 不为空时标记为失败并释放订单
+func (c *ReviewCreator) setStatus(ctx context.Context, rdb *redis.Client, cmd *event.CreateReviewCommand, reason string) error {
+	key := event.CreateStatusKey(cmd.GetReviewId())
+	status := event.CreateCreated
+	pipe := rdb.TxPipeline()
+	if reason == "" {
+		pipe.HSet(ctx, key, "status", status)
+		pipe.Expire(ctx, key, c.ttl)
+	} else {
+		status = event.CreateFailed
+		pipe.HSet(ctx, key, "status", status, "reason", reason)
+		pipe.Expire(ctx, key, c.ttl)
+		// 订单已经评价过时仍然由 MySQL 中已有的评价占用，释放后 review-service 的校验会拒绝再次提交
+		pipe.Del(ctx, event.CreateOrderKey(cmd.GetOrderId()))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("update create status failed: %w", err)
+	}
+	c.log.Infof("review %d of order %d %s %s", cmd.GetReviewId(), cmd.GetOrderId(), status, reason)
+	return nil
+}
+
+// save 在评价所在的分片上写入评价和 review.created 事件，无法写入时返回原因
+// 订单的评价都位于同一个店铺的分片，只需要在这个分片上检查订单
+func (c *ReviewCreator) save(ctx context.Context, dbs []*sql.DB, cmd *event.CreateReviewCommand) (string, error) {
+	db, table := c.locate(dbs, cmd.GetStoreId())
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var existing int64
+	err = tx.QueryRowContext(ctx, "SELECT review_id FROM "+table+" WHERE order_id = ? LIMIT 1", cmd.GetOrderId()).Scan(&existing)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return "", err
+	case existing == cmd.GetReviewId():
+		// 重复消费，评价和事件在上一次已经写入
+		return "", nil
+	default:
+		return createFailedOrderReviewed, nil
+	}
+
+	acceptedAt := time.Now()
+	if cmd.GetAcceptedAt() != nil {
+		acceptedAt = cmd.GetAcceptedAt().AsTime()
+	}
+	at := acceptedAt.In(c.loc).Format(mysqlDateTime)
+	res, err := tx.ExecContext(ctx, "INSERT INTO "+table+
+		" (create_by, update_by, create_at, update_at, review_id, content, score, service_score, express_score, has_media,"+
+		" order_id, sku_id, spu_id, store_id, user_id, anonymous, pic_info, video_info, status)"+
+		" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE id = id",
+		cmd.GetCreateBy(), cmd.GetCreateBy(), at, at, cmd.GetReviewId(), cmd.GetContent(), cmd.GetScore(), cmd.GetServiceScore(),
+		cmd.GetExpressScore(), cmd.GetHasMedia(), cmd.GetOrderId(), cmd.GetSkuId(), cmd.GetSpuId(), cmd.GetStoreId(), cmd.GetUserId(),
+		cmd.GetAnonymous(), cmd.GetPicInfo(), cmd.GetVideoInfo(), cmd.GetStatus())
+	if err != nil {
+		return "", err
+	}
+	// 已存在时影响的行数为 0，事件随第一次写入一起写入
+	if n, err := res.RowsAffected(); err != nil {
+		return "", err
+	} else if n == 1 && cmd.GetCreated() != nil {
+		ev := cmd.GetCreated()
+		payload, err := proto.Marshal(ev)
+		if err != nil {
+			return "", err
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT IGNORE INTO review_outbox (event_id, event_type, aggregate_type, aggregate_id, payload) VALUES (?, ?, ?, ?, ?)",
+			ev.GetEventId(), ev.GetEventType(), ev.GetAggregateType(), ev.GetAggregateId(), payload)
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", tx.Commit()
+}
+
+// locate 店铺所在的分片的数据库和评价表，与 review-service 的分片规则一致
+func (c *ReviewCreator) locate(dbs []*sql.DB, storeID int64) (*sql.DB, string) {
+	if !c.sharding {
+		return dbs[0], "review_info"
+	}
+	idx := shardIndex(storeID, c.tables)
+	return dbs[idx%len(dbs)], fmt.Sprintf("review_info_%02d", idx)
+}
@@ -0,0 +1,299 @@
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"review-job/internal/conf"
+)
+
+// 消息格式
+const (
+	FormatCanal    = "canal"
+	FormatDebezium = "debezium"
+)
+
+// Decoder 将一条消息解析为统一的变更事件 Msg，不需要处理的消息（如 Debezium 的 tombstone）返回 nil
+type Decoder interface {
+	Decode(m kafka.Message) (*Msg, error)
+}
+
+// Decoders 按 topic 选择 Decoder，没有配置格式的 topic 按 canal flat message 解析
+type Decoders struct {
+	topics map[string]Decoder
+}
+
+// NewDecoders 按 Kafka.formats 为每个 topic 创建 Decoder
+func NewDecoders(cfg *conf.Kafka, tf *Transformer) (*Decoders, error) {
+	d := &Decoders{topics: map[string]Decoder{}}
+	for topic, format := range cfg.GetFormats() {
+		switch format {
+		case "", FormatCanal:
+			d.topics[topic] = CanalDecoder{}
+		case FormatDebezium:
+			d.topics[topic] = DebeziumDecoder{loc: tf.loc}
+		default:
+			return nil, fmt.Errorf("unknown format %q of topic %s", format, topic)
+		}
+	}
+	return d, nil
+}
+
+// For topic 对应的 Decoder
+func (d *Decoders) For(topic string) Decoder {
+	if dec, ok := d.topics[topic]; ok {
+		return dec
+	}
+	return CanalDecoder{}
+}
+
+// CanalDecoder 解析 canal flat message，位置为消息在 kafka 中的 topic/partition/offset
+type CanalDecoder struct{}
+
+func (CanalDecoder) Decode(m kafka.Message) (*Msg, error) {
+	msg := new(Msg)
+	if err := json.Unmarshal(m.Value, msg); err != nil {
+		return nil, err
+	}
+	msg.Position = fmt.Sprintf("%s/%d/%d", m.Topic, m.Partition, m.Offset)
+	return msg, nil
+}
+
+// debeziumOps Debezium 的 op 对应的变更类型，快照读取（r）按新增处理
+var debeziumOps = map[string]string{"c": "INSERT", "r": "INSERT", "u": "UPDATE", "d": "DELETE", "t": "TRUNCATE"}
+
+// debeziumField schema 中的一个字段，Name 为逻辑类型，如 io.debezium.time.Timestamp
+type debeziumField struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name"`
+	Field      string            `json:"field"`
+	Fields     []debeziumField   `json:"fields"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+type debeziumPayload struct {
+	Before map[string]interface{} `json:"before"`
+	After  map[string]interface{} `json:"after"`
+	Op     string                 `json:"op"`
+	Source struct {
+		DB    string `json:"db"`
+		Table string `json:"table"`
+		File  string `json:"file"`
+		Pos   int64  `json:"pos"`
+		Row   int64  `json:"row"`
+		TsMs  int64  `json:"ts_ms"`
+	} `json:"source"`
+	// 表结构变更事件
+	DDL          string `json:"ddl"`
+	DatabaseName string `json:"databaseName"`
+	TableChanges []struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	} `json:"tableChanges"`
+}
+
+// DebeziumDecoder 解析 Debezium MySQL connector 经 JSON converter 输出的消息，需要开启 schemas.enable，
+// 按 schema 中的逻辑类型将各列转换为与 canal 相同的字符串：时间按数据库时区格式化为 2006-01-02 15:04:05，
+// 布尔为 0/1；mysqlType 优先取 column.propagate.source.type 带上的源类型，否则按 schema 的类型推断
+type DebeziumDecoder struct {
+	loc *time.Location
+}
+
+func (d DebeziumDecoder) Decode(m kafka.Message) (*Msg, error) {
+	// 删除之后的 tombstone，用于 kafka 的日志压缩
+	if len(m.Value) == 0 {
+		return nil, nil
+	}
+	var envelope struct {
+		Schema  *debeziumField  `json:"schema"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(m.Value, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Schema == nil || len(envelope.Payload) == 0 {
+		return nil, errors.New("debezium message has no schema, enable schemas.enable of the json converter")
+	}
+	p := new(debeziumPayload)
+	dec := json.NewDecoder(bytes.NewReader(envelope.Payload))
+	dec.UseNumber()
+	if err := dec.Decode(p); err != nil {
+		return nil, err
+	}
+	msg := &Msg{
+		Database: p.Source.DB,
+		Table:    p.Source.Table,
+		Es:       p.Source.TsMs,
+		Position: fmt.Sprintf("%s:%d:%d", p.Source.File, p.Source.Pos, p.Source.Row),
+	}
+	if p.DDL != "" {
+		return d.ddl(msg, p), nil
+	}
+	msg.Type = debeziumOps[p.Op]
+	if msg.Type == "" {
+		return nil, fmt.Errorf("unknown debezium op %q", p.Op)
+	}
+
+	// before 和 after 的结构相同
+	fields := map[string]debeziumField{}
+	for _, f := range envelope.Schema.Fields {
+		if f.Field == "before" || f.Field == "after" {
+			for _, c := range f.Fields {
+				fields[c.Field] = c
+			}
+		}
+	}
+	msg.MysqlType = make(map[string]string, len(fields))
+	for name, f := range fields {
+		msg.MysqlType[name] = f.mysqlType()
+	}
+	before, err := d.row(p.Before, fields)
+	if err != nil {
+		return nil, fmt.Errorf("before: %w", err)
+	}
+	after, err := d.row(p.After, fields)
+	if err != nil {
+		return nil, fmt.Errorf("after: %w", err)
+	}
+	if before != nil {
+		msg.Old = []map[string]interface{}{before}
+	}
+	// 与 canal 一致，删除时 data 为删除前的行
+	if msg.Type == "DELETE" {
+		after = before
+	}
+	if after != nil {
+		msg.Data = []map[string]interface{}{after}
+	}
+	return msg, nil
+}
+
+// ddl 表结构变更事件，变更类型取 tableChanges 中的类型，如 ALTER
+func (d DebeziumDecoder) ddl(msg *Msg, p *debeziumPayload) *Msg {
+	msg.IsDdl = true
+	msg.SQL = p.DDL
+	msg.Type = "QUERY"
+	if p.DatabaseName != "" {
+		msg.Database = p.DatabaseName
+	}
+	if len(p.TableChanges) > 0 {
+		msg.Type = p.TableChanges[0].Type
+		// id 形如 "review"."review_info"
+		if msg.Table == "" {
+			id := strings.Split(p.TableChanges[0].ID, ".")
+			msg.Table = strings.Trim(id[len(id)-1], `"`)
+		}
+	}
+	return msg
+}
+
+// row 将一行中的值转换为 canal 格式的字符串，null 保持为 nil
+func (d DebeziumDecoder) row(values map[string]interface{}, fields map[string]debeziumField) (map[string]interface{}, error) {
+	if values == nil {
+		return nil, nil
+	}
+	row := make(map[string]interface{}, len(values))
+	for name, v := range values {
+		s, err := d.value(v, fields[name].Name)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", name, err)
+		}
+		row[name] = s
+	}
+	return row, nil
+}
+
+func (d DebeziumDecoder) value(v interface{}, logical string) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case string:
+		// TIMESTAMP 列为 UTC 的 ISO-8601 时间
+		if logical == "io.debezium.time.ZonedTimestamp" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, err
+			}
+			return t.In(d.loc).Format(mysqlDateTime), nil
+		}
+		return v, nil
+	case json.Number:
+		switch logical {
+		case "io.debezium.time.Timestamp", "io.debezium.time.MicroTimestamp", "io.debezium.time.NanoTimestamp", "io.debezium.time.Date":
+		default:
+			return v.String(), nil
+		}
+		n, err := v.Int64()
+		if err != nil {
+			return nil, err
+		}
+		// DATETIME 和 DATE 列没有时区，Debezium 按 UTC 输出其字面值
+		switch logical {
+		case "io.debezium.time.Timestamp":
+			return time.UnixMilli(n).UTC().Format(mysqlDateTime), nil
+		case "io.debezium.time.MicroTimestamp":
+			return time.UnixMicro(n).UTC().Format(mysqlDateTime), nil
+		case "io.debezium.time.NanoTimestamp":
+			return time.Unix(0, n).UTC().Format(mysqlDateTime), nil
+		default:
+			return time.Unix(n*86400, 0).UTC().Format(time.DateOnly), nil
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// mysqlType 列的 MySQL 类型，没有源类型时按 schema 的类型推断，如 int64 为 bigint
+func (f debeziumField) mysqlType() string {
+	if t := f.Parameters["__debezium.source.column.type"]; t != "" {
+		t = strings.ToLower(t)
+		if n := f.Parameters["__debezium.source.column.length"]; n != "" {
+			t += "(" + n + ")"
+		}
+		return t
+	}
+	switch f.Name {
+	case "io.debezium.time.Timestamp", "io.debezium.time.MicroTimestamp", "io.debezium.time.NanoTimestamp":
+		return "datetime"
+	case "io.debezium.time.ZonedTimestamp":
+		return "timestamp"
+	case "io.debezium.time.Date":
+		return "date"
+	case "io.debezium.time.Year":
+		return "year"
+	case "io.debezium.data.Json":
+		return "json"
+	case "org.apache.kafka.connect.data.Decimal":
+		return "decimal"
+	}
+	switch f.Type {
+	case "int8":
+		return "tinyint"
+	case "int16":
+		return "smallint"
+	case "int32":
+		return "int"
+	case "int64":
+		return "bigint"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	case "boolean":
+		return "tinyint(1)"
+	}
+	return "varchar"
+}
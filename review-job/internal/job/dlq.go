@@ -0,0 +1,282 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/segmentio/kafka-go"
+	"review-job/internal/conf"
+)
+
+// 写入死信队列的原因
+const (
+	ReasonUnmarshal = "unmarshal" // 消息不是合法的 canal JSON
+	ReasonConvert   = "convert"   // 行数据无法转换为文档，如缺少 review_id
+	ReasonRejected  = "rejected"  // 文档被 ES 拒绝且无法重试，如 mapping 不匹配
+	ReasonCreate    = "create"    // 异步创建评价的命令重试后仍无法写入数据库
+)
+
+// 死信消息的 header，记录失败原因和原始位置
+const (
+	HeaderReason    = "x-dlq-reason"
+	HeaderError     = "x-dlq-error"
+	HeaderDocument  = "x-dlq-document"
+	HeaderTopic     = "x-dlq-topic"
+	HeaderPartition = "x-dlq-partition"
+	HeaderOffset    = "x-dlq-offset"
+	HeaderFailedAt  = "x-dlq-failed-at"
+)
+
+// Letter 一条需要写入死信队列的消息
+type Letter struct {
+	Msg        kafka.Message
+	Reason     string
+	DocumentID string // 被拒绝的文档，其余原因为空
+	Err        error
+}
+
+// DeadLetter 死信队列，原始消息原样写入死信 topic，失败原因放在 header 中
+// 死信 topic 未配置时只记录日志，与之前的行为一致
+type DeadLetter struct {
+	writer  *kafka.Writer
+	client  *kafka.Client
+	topic   string
+	replay  string // replay-dlq 使用的消费者组
+	brokers []string
+	log     *log.Helper
+
+	mu        sync.Mutex
+	published map[string]int64
+}
+
+func NewDeadLetter(cfg *conf.Kafka, logger log.Logger) (*DeadLetter, func(), error) {
+	dl := &DeadLetter{
+		topic:     cfg.DlqTopic,
+		replay:    cfg.GroupId + "-dlq-replay",
+		brokers:   cfg.Brokers,
+		log:       log.NewHelper(logger),
+		published: map[string]int64{},
+	}
+	if dl.topic == "" {
+		return dl, func() {}, nil
+	}
+	dl.writer = &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        dl.topic,
+		Balancer:     &kafka.Hash{}, // 与原 topic 一样按 key 分区
+		RequiredAcks: kafka.RequireAll,
+	}
+	dl.client = &kafka.Client{Addr: kafka.TCP(cfg.Brokers...)}
+	cleanup := func() {
+		if err := dl.writer.Close(); err != nil {
+			dl.log.Errorf("close dead letter writer failed, err:%v", err)
+		}
+	}
+	return dl, cleanup, nil
+}
+
+// Enabled 是否配置了死信 topic
+func (dl *DeadLetter) Enabled() bool {
+	return dl != nil && dl.writer != nil
+}
+
+// Topic 死信 topic
+func (dl *DeadLetter) Topic() string {
+	return dl.topic
+}
+
+// Publish 写入死信 topic，全部写入成功后才返回 nil
+func (dl *DeadLetter) Publish(ctx context.Context, letters ...Letter) error {
+	if len(letters) == 0 {
+		return nil
+	}
+	if !dl.Enabled() {
+		for _, l := range letters {
+			dl.log.Errorf("drop %s message at %s/%d/%d, document:%s, err:%v", l.Reason, l.Msg.Topic, l.Msg.Partition, l.Msg.Offset, l.DocumentID, l.Err)
+		}
+		return nil
+	}
+	msgs := make([]kafka.Message, 0, len(letters))
+	now := time.Now().Format(time.RFC3339)
+	for _, l := range letters {
+		headers := []kafka.Header{
+			{Key: HeaderReason, Value: []byte(l.Reason)},
+			{Key: HeaderError, Value: []byte(fmt.Sprint(l.Err))},
+			{Key: HeaderTopic, Value: []byte(l.Msg.Topic)},
+			{Key: HeaderPartition, Value: []byte(strconv.Itoa(l.Msg.Partition))},
+			{Key: HeaderOffset, Value: []byte(strconv.FormatInt(l.Msg.Offset, 10))},
+			{Key: HeaderFailedAt, Value: []byte(now)},
+		}
+		if l.DocumentID != "" {
+			headers = append(headers, kafka.Header{Key: HeaderDocument, Value: []byte(l.DocumentID)})
+		}
+		msgs = append(msgs, kafka.Message{Key: l.Msg.Key, Value: l.Msg.Value, Headers: headers})
+	}
+	if err := dl.writer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("publish %d messages to %s failed: %w", len(msgs), dl.topic, err)
+	}
+
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	for _, l := range letters {
+		dl.published[l.Reason]++
+		dl.log.Warnf("%s message at %s/%d/%d sent to %s, document:%s, err:%v", l.Reason, l.Msg.Topic, l.Msg.Partition, l.Msg.Offset, dl.topic, l.DocumentID, l.Err)
+	}
+	return nil
+}
+
+// PartitionStats 死信 topic 一个分区的消息数量
+type PartitionStats struct {
+	Partition int
+	Total     int64 // 分区中现存的消息数
+	Pending   int64 // replay-dlq 还没有重放的消息数
+}
+
+// Stats 死信队列的消息数量
+type Stats struct {
+	Topic      string
+	Total      int64
+	Pending    int64
+	Published  map[string]int64 // 本进程启动以来按原因写入的消息数
+	Partitions []PartitionStats
+}
+
+// Stats 按分区统计死信 topic 中现存和待重放的消息数，待重放数量根据 replay-dlq 消费者组提交的 offset 计算
+func (dl *DeadLetter) Stats(ctx context.Context) (*Stats, error) {
+	stats := &Stats{Topic: dl.topic, Published: map[string]int64{}}
+	dl.mu.Lock()
+	for reason, n := range dl.published {
+		stats.Published[reason] = n
+	}
+	dl.mu.Unlock()
+	if !dl.Enabled() {
+		return stats, nil
+	}
+
+	meta, err := dl.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{dl.topic}})
+	if err != nil {
+		return nil, fmt.Errorf("get metadata of %s failed: %w", dl.topic, err)
+	}
+	var partitions []int
+	for _, t := range meta.Topics {
+		if t.Error != nil {
+			return nil, fmt.Errorf("get metadata of %s failed: %w", dl.topic, t.Error)
+		}
+		for _, p := range t.Partitions {
+			partitions = append(partitions, p.ID)
+		}
+	}
+	if len(partitions) == 0 {
+		return stats, nil
+	}
+
+	requests := make([]kafka.OffsetRequest, 0, 2*len(partitions))
+	for _, p := range partitions {
+		requests = append(requests, kafka.FirstOffsetOf(p), kafka.LastOffsetOf(p))
+	}
+	offsets, err := dl.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{dl.topic: requests}})
+	if err != nil {
+		return nil, fmt.Errorf("list offsets of %s failed: %w", dl.topic, err)
+	}
+	committed, err := dl.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: dl.replay,
+		Topics:  map[string][]int{dl.topic: partitions},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch offsets of group %s failed: %w", dl.replay, err)
+	}
+	replayed := map[int]int64{}
+	for _, p := range committed.Topics[dl.topic] {
+		replayed[p.Partition] = p.CommittedOffset
+	}
+
+	for _, p := range offsets.Topics[dl.topic] {
+		if p.Error != nil {
+			return nil, fmt.Errorf("list offsets of %s/%d failed: %w", dl.topic, p.Partition, p.Error)
+		}
+		ps := PartitionStats{Partition: p.Partition, Total: p.LastOffset - p.FirstOffset}
+		// 消费者组还没有提交过 offset 时为 -1，分区中的消息都未重放；已过期删除的消息不计入
+		start := replayed[p.Partition]
+		if start < p.FirstOffset {
+			start = p.FirstOffset
+		}
+		ps.Pending = p.LastOffset - start
+		stats.Total += ps.Total
+		stats.Pending += ps.Pending
+		stats.Partitions = append(stats.Partitions, ps)
+	}
+	return stats, nil
+}
+
+// Replay 将死信 topic 中待重放的消息按原 key 写回原 topic，由 review-job 重新处理，返回重放的数量
+// 最多重放 limit 条（为 0 时不限制）；等待 idle 仍没有新消息时认为已经重放完
+// 每条消息写回成功后才提交 offset，中途失败可以再次执行；重复写回是安全的，旧数据会因版本号冲突被跳过
+func (dl *DeadLetter) Replay(ctx context.Context, limit int64, idle time.Duration) (int64, error) {
+	if !dl.Enabled() {
+		return 0, errors.New("dlq_topic is not configured")
+	}
+	stats, err := dl.Stats(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pending := stats.Pending
+	if limit > 0 && limit < pending {
+		pending = limit
+	}
+	if pending == 0 {
+		return 0, nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: dl.brokers,
+		GroupID: dl.replay,
+		Topic:   dl.topic,
+	})
+	defer reader.Close()
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(dl.brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+	defer writer.Close()
+
+	var n int64
+	for n < pending {
+		fctx, cancel := context.WithTimeout(ctx, idle)
+		m, err := reader.FetchMessage(fctx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			break
+		}
+		if err != nil {
+			return n, fmt.Errorf("fetch message from %s failed: %w", dl.topic, err)
+		}
+		topic := header(m, HeaderTopic)
+		if topic == "" {
+			return n, fmt.Errorf("message at %s/%d/%d has no %s header", m.Topic, m.Partition, m.Offset, HeaderTopic)
+		}
+		if err := writer.WriteMessages(ctx, kafka.Message{Topic: topic, Key: m.Key, Value: m.Value}); err != nil {
+			return n, fmt.Errorf("write message back to %s failed: %w", topic, err)
+		}
+		if err := reader.CommitMessages(ctx, m); err != nil {
+			return n, fmt.Errorf("commit message at %s/%d/%d failed: %w", m.Topic, m.Partition, m.Offset, err)
+		}
+		n++
+		dl.log.Infof("replayed %s message at %s/%d/%d to %s", header(m, HeaderReason), m.Topic, m.Partition, m.Offset, topic)
+	}
+	return n, nil
+}
+
+func header(m kafka.Message, key string) string {
+	for _, h := range m.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
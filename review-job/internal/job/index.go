@@ -0,0 +1,504 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/conflicts"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/versiontype"
+	"github.com/go-kratos/kratos/v2/log"
+	"review-job/internal/conf"
+	"review-service/pkg/search"
+)
+
+const (
+	// catchUpMargin 补齐变更时向前多取一段时间，容忍 MySQL 与 review-job 之间的时钟偏差
+	catchUpMargin = time.Minute
+	// maxCatchUpPasses 切换别名前最多补齐的轮数，某一轮没有新变更时提前结束
+	maxCatchUpPasses = 5
+	// mysqlDateTime datetime 列在 canal 消息中的格式
+	mysqlDateTime = "2006-01-02 15:04:05"
+	// defaultAppealIndex 未配置 appeal_index 时申诉索引的别名
+	defaultAppealIndex = "appeal"
+)
+
+// IndexManager 管理一个索引（评价、申诉）的模板、别名和重建
+// 读写都通过别名 {index} 进行，别名指向 {index}_v{时间戳} 的版本化索引，mapping 由索引模板统一下发
+type IndexManager struct {
+	client   *elasticsearch.TypedClient
+	alias    string
+	mapping  search.Mapping
+	analyzer string
+	// newDoc 返回索引中文档的结构体，从旧索引复制时按它转换字段类型
+	newDoc func() interface{}
+	log    *log.Helper
+}
+
+func NewIndexManager(client *elasticsearch.TypedClient, alias string, mapping search.Mapping, analyzer string, logger log.Logger) *IndexManager {
+	return &IndexManager{
+		client:   client,
+		alias:    alias,
+		mapping:  mapping,
+		analyzer: analyzer,
+		log:      log.NewHelper(logger),
+	}
+}
+
+// NewIndexManagers 按配置为每个索引创建 IndexManager，键为 IndexReview、IndexAppeal
+func NewIndexManagers(client *elasticsearch.TypedClient, cfg *conf.Elasticsearch, logger log.Logger) map[string]*IndexManager {
+	review := NewIndexManager(client, cfg.Index, search.ReviewMapping, cfg.Analyzer, logger)
+	review.newDoc = func() interface{} { return new(search.ReviewDoc) }
+	appeal := NewIndexManager(client, appealAlias(cfg), search.AppealMapping, cfg.Analyzer, logger)
+	appeal.newDoc = func() interface{} { return new(search.AppealDoc) }
+	return map[string]*IndexManager{
+		IndexReview: review,
+		IndexAppeal: appeal,
+	}
+}
+
+func appealAlias(cfg *conf.Elasticsearch) string {
+	if cfg.GetAppealIndex() != "" {
+		return cfg.GetAppealIndex()
+	}
+	return defaultAppealIndex
+}
+
+// Alias 读写别名
+func (m *IndexManager) Alias() string {
+	return m.alias
+}
+
+func (m *IndexManager) templateName() string {
+	return m.alias + "_template"
+}
+
+func (m *IndexManager) newIndexName() string {
+	return m.alias + "_v" + time.Now().Format("20060102150405")
+}
+
+// PutTemplate 创建或更新索引模板，只对之后新建的索引生效，已有索引需要 reindex
+func (m *IndexManager) PutTemplate(ctx context.Context) error {
+	_, err := m.client.Indices.PutIndexTemplate(m.templateName()).
+		IndexPatterns(m.alias + "_v*").
+		Template(&types.IndexTemplateMapping{
+			Mappings: search.ESMapping(m.mapping, m.analyzer),
+		}).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("put index template %s failed: %w", m.templateName(), err)
+	}
+	return nil
+}
+
+// Ensure review-job 启动时确保模板和别名存在
+// 别名不存在时新建第一个版本的索引并挂上别名；别名已存在时将新增的字段加入当前索引的 mapping，
+// 已有文档中没有这些字段，不需要 reindex；
+// 已有同名的普通索引（旧版本按动态 mapping 创建）时保持原样继续写入，需要执行 reindex 迁移
+func (m *IndexManager) Ensure(ctx context.Context) error {
+	if err := m.PutTemplate(ctx); err != nil {
+		return err
+	}
+	indices, legacy, err := m.current(ctx)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		m.log.Warnf("index %s uses dynamic mapping, run `review-job reindex` to migrate it behind an alias", m.alias)
+		return nil
+	}
+	if len(indices) > 0 {
+		return m.putMapping(ctx)
+	}
+	name := m.newIndexName()
+	isWrite := true
+	_, err = m.client.Indices.Create(name).
+		Aliases(map[string]types.Alias{m.alias: {IsWriteIndex: &isWrite}}).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("create index %s failed: %w", name, err)
+	}
+	m.log.Infof("created index %s with alias %s", name, m.alias)
+	return nil
+}
+
+// putMapping 将 mapping 中的字段加入别名指向的索引，只能新增字段，已有字段的类型变化需要 reindex
+func (m *IndexManager) putMapping(ctx context.Context) error {
+	return m.PutFields(ctx, m.mapping)
+}
+
+// PutFields 将 fields 加入别名指向的索引的 mapping，表中新增的列由 SchemaGuard 通过它加入索引
+func (m *IndexManager) PutFields(ctx context.Context, fields search.Mapping) error {
+	mapping := search.ESMapping(fields, m.analyzer)
+	if _, err := m.client.Indices.PutMapping(m.alias).Properties(mapping.Properties).Do(ctx); err != nil {
+		return fmt.Errorf("put mapping of %s failed: %w", m.alias, err)
+	}
+	return nil
+}
+
+// current 返回别名当前指向的索引；没有别名但存在同名的普通索引时返回该索引，legacy 为 true
+func (m *IndexManager) current(ctx context.Context) ([]string, bool, error) {
+	ok, err := m.client.Indices.ExistsAlias(m.alias).IsSuccess(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		resp, err := m.client.Indices.GetAlias().Name(m.alias).Do(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		indices := make([]string, 0, len(resp))
+		for index := range resp {
+			indices = append(indices, index)
+		}
+		return indices, false, nil
+	}
+	ok, err = m.client.Indices.Exists(m.alias).IsSuccess(ctx)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return []string{m.alias}, true, nil
+}
+
+// Reindex 按最新的模板新建版本化索引，复制当前索引的数据后原子地切换别名，返回新索引名
+// 复制期间 review-job 仍写入旧索引：切换前按 update_at、reply_at 多轮补齐复制期间变更的文档；
+// 最后一轮补齐前给旧索引加上写入阻塞，review-job 的写入等待重试，旧索引不再变化，
+// 补齐最后的变更并删除复制后在旧索引中被删除的文档，再切换别名，切换后 review-job 的重试写入新索引。
+// 复制保留文档的 _version，新索引中更新的文档不会被旧数据覆盖；
+// 从旧的普通索引复制时按 tf 将字符串的列转换为文档的字段类型，见 legacyDocument
+func (m *IndexManager) Reindex(ctx context.Context, tf *Transformer, deleteOld bool) (string, error) {
+	if err := m.PutTemplate(ctx); err != nil {
+		return "", err
+	}
+	sources, legacy, err := m.current(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(sources) == 0 {
+		return "", fmt.Errorf("index or alias %s not found", m.alias)
+	}
+	copyTo := m.copy
+	if legacy {
+		if m.newDoc == nil {
+			return "", fmt.Errorf("index %s uses dynamic mapping and can not be converted, run `review-job backfill` instead", m.alias)
+		}
+		copyTo = func(ctx context.Context, sources []string, dest string, query *types.Query) (int64, int64, error) {
+			return m.copyLegacy(ctx, tf, sources, dest, query)
+		}
+	}
+
+	name := m.newIndexName()
+	if _, err := m.client.Indices.Create(name).Do(ctx); err != nil {
+		return "", fmt.Errorf("create index %s failed: %w", name, err)
+	}
+	since := time.Now()
+	total, _, err := copyTo(ctx, sources, name, nil)
+	if err != nil {
+		return "", err
+	}
+	m.log.Infof("copied %d documents from %v to %s", total, sources, name)
+	for pass := 0; pass < maxCatchUpPasses; pass++ {
+		start := time.Now()
+		_, changed, err := copyTo(ctx, sources, name, m.changedSince(since, legacy))
+		if err != nil {
+			return "", err
+		}
+		since = start
+		m.log.Infof("catch up pass %d: %d documents", pass+1, changed)
+		if changed == 0 {
+			break
+		}
+	}
+
+	// 阻塞旧索引的写入，直到别名切换；失败时恢复写入，review-job 继续写入旧索引
+	if err := m.blockWrite(ctx, sources, true); err != nil {
+		return "", err
+	}
+	swapped := false
+	defer func() {
+		if !swapped {
+			if err := m.blockWrite(context.Background(), sources, false); err != nil {
+				m.log.Errorf("unblock writes of %v failed, err:%v", sources, err)
+			}
+		}
+	}()
+	if _, err := m.client.Indices.Refresh().Index(strings.Join(sources, ",")).Do(ctx); err != nil {
+		return "", fmt.Errorf("refresh %v failed: %w", sources, err)
+	}
+	_, changed, err := copyTo(ctx, sources, name, m.changedSince(since, legacy))
+	if err != nil {
+		return "", err
+	}
+	m.log.Infof("final catch up with writes blocked: %d documents", changed)
+	if err := m.removeDeleted(ctx, sources, name); err != nil {
+		return "", err
+	}
+
+	// 旧的普通索引与别名同名，只能在切换别名的请求中移除；移除前先克隆一份，数据保留在克隆的索引中
+	old := sources
+	if legacy {
+		backup := m.alias + "_legacy_" + time.Now().Format("20060102150405")
+		if _, err := m.client.Indices.Clone(sources[0], backup).Do(ctx); err != nil {
+			return "", fmt.Errorf("clone legacy index %s to %s failed: %w", sources[0], backup, err)
+		}
+		m.log.Infof("cloned legacy index %s to %s", sources[0], backup)
+		old = []string{backup}
+	}
+	isWrite := true
+	actions := []types.IndicesAction{{Add: &types.AddAction{Index: &name, Alias: &m.alias, IsWriteIndex: &isWrite}}}
+	for i := range sources {
+		if legacy {
+			actions = append(actions, types.IndicesAction{RemoveIndex: &types.RemoveIndexAction{Index: &sources[i]}})
+			continue
+		}
+		actions = append(actions, types.IndicesAction{Remove: &types.RemoveAction{Index: &sources[i], Alias: &m.alias}})
+	}
+	if _, err := m.client.Indices.UpdateAliases().Actions(actions...).Do(ctx); err != nil {
+		return "", fmt.Errorf("swap alias %s to %s failed: %w", m.alias, name, err)
+	}
+	swapped = true
+	m.log.Infof("alias %s now points to %s", m.alias, name)
+
+	if deleteOld {
+		if _, err := m.client.Indices.Delete(strings.Join(old, ",")).Do(ctx); err != nil {
+			return "", fmt.Errorf("delete old indices %v failed: %w", old, err)
+		}
+		m.log.Infof("deleted old indices %v", old)
+		return name, nil
+	}
+	// 旧索引已经不在别名中，恢复写入，需要时可以将别名切回
+	if err := m.blockWrite(ctx, old, false); err != nil {
+		return "", err
+	}
+	m.log.Infof("previous indices %v are kept, delete them once %s is verified", old, name)
+	return name, nil
+}
+
+// blockWrite 设置或取消索引的写入阻塞（index.blocks.write）
+func (m *IndexManager) blockWrite(ctx context.Context, indices []string, block bool) error {
+	_, err := m.client.Indices.PutSettings().
+		Indices(strings.Join(indices, ",")).
+		Settings(&types.IndexSettings{Blocks: &types.IndexSettingBlocks{Write: block}}).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("set write block of %v to %v failed: %w", indices, block, err)
+	}
+	return nil
+}
+
+// removeDeleted 删除 dest 中有、sources 中已经没有的文档，即复制之后在旧索引中被删除的文档；需要在 sources 阻塞写入后调用
+// 补齐后 dest 包含 sources 中的全部文档，数量相同时没有被删除的文档，不需要逐个比对
+func (m *IndexManager) removeDeleted(ctx context.Context, sources []string, dest string) error {
+	total, err := m.client.Count().Index(strings.Join(sources, ",")).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("count %v failed: %w", sources, err)
+	}
+	copied, err := m.client.Count().Index(dest).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("count %s failed: %w", dest, err)
+	}
+	if copied.Count <= total.Count {
+		return nil
+	}
+	m.log.Infof("%s has %d documents more than %v, removing documents deleted during the copy", dest, copied.Count-total.Count, sources)
+
+	res, err := m.client.Search().Index(dest).Scroll("1m").Size(defaultBackfillChunk).Source_(false).Sort("_doc").Do(ctx)
+	if err != nil {
+		return fmt.Errorf("scan %s failed: %w", dest, err)
+	}
+	var removed int64
+	for {
+		ids := make([]string, 0, len(res.Hits.Hits))
+		for _, hit := range res.Hits.Hits {
+			if hit.Id_ != nil {
+				ids = append(ids, *hit.Id_)
+			}
+		}
+		if len(ids) == 0 {
+			break
+		}
+		existing, err := m.client.Search().Index(strings.Join(sources, ",")).
+			Query(&types.Query{Ids: &types.IdsQuery{Values: ids}}).
+			Size(len(ids)).Source_(false).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("query %v failed: %w", sources, err)
+		}
+		present := make(map[string]bool, len(existing.Hits.Hits))
+		for _, hit := range existing.Hits.Hits {
+			if hit.Id_ != nil {
+				present[*hit.Id_] = true
+			}
+		}
+		var deleted []string
+		for _, id := range ids {
+			if !present[id] {
+				deleted = append(deleted, id)
+			}
+		}
+		if len(deleted) > 0 {
+			resp, err := m.client.DeleteByQuery(dest).Query(&types.Query{Ids: &types.IdsQuery{Values: deleted}}).Do(ctx)
+			if err != nil {
+				return fmt.Errorf("delete %d documents from %s failed: %w", len(deleted), dest, err)
+			}
+			removed += count(resp.Deleted)
+		}
+		if res.ScrollId_ == nil {
+			break
+		}
+		next, err := m.client.Scroll().ScrollId(*res.ScrollId_).Scroll("1m").Do(ctx)
+		if err != nil {
+			return fmt.Errorf("scan %s failed: %w", dest, err)
+		}
+		res.Hits, res.ScrollId_ = next.Hits, next.ScrollId_
+	}
+	if res.ScrollId_ != nil {
+		_, _ = m.client.ClearScroll().ScrollId(*res.ScrollId_).Do(ctx)
+	}
+	if _, err := m.client.Indices.Refresh().Index(dest).Do(ctx); err != nil {
+		return fmt.Errorf("refresh %s failed: %w", dest, err)
+	}
+	m.log.Infof("removed %d documents deleted during the copy from %s", removed, dest)
+	return nil
+}
+
+// copy 使用 _reindex 将 sources 中满足 query 的文档复制到 dest，并等待完成，返回复制的文档数和实际写入的文档数
+// 按 external 方式保留版本号：dest 中不存在或版本更旧的文档被覆盖，版本更新或没有变化的文档保持不变，计入 VersionConflicts
+func (m *IndexManager) copy(ctx context.Context, sources []string, dest string, query *types.Query) (int64, int64, error) {
+	resp, err := m.client.Reindex().
+		Source(&types.ReindexSource{Index: sources, Query: query}).
+		Dest(&types.ReindexDestination{Index: dest, VersionType: &versiontype.External}).
+		Conflicts(conflicts.Proceed).
+		WaitForCompletion(true).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("reindex %v to %s failed: %w", sources, dest, err)
+	}
+	if len(resp.Failures) > 0 {
+		return 0, 0, fmt.Errorf("reindex %v to %s failed: %d failures, first: %s", sources, dest, len(resp.Failures), resp.Failures[0].Cause.Type)
+	}
+	return count(resp.Total), count(resp.Created) + count(resp.Updated), nil
+}
+
+// copyLegacy 逐批读出旧的普通索引中满足 query 的文档，转换字段类型后写入 dest，返回复制的文档数和实际写入的文档数
+// 旧索引按动态 mapping 创建，_source 中的列是 canal 原样写入的字符串（如 "score":"5"），
+// _reindex 原样复制后 review-service 无法解码为 search.ReviewDoc；同样按 external 方式保留版本号
+func (m *IndexManager) copyLegacy(ctx context.Context, tf *Transformer, sources []string, dest string, query *types.Query) (int64, int64, error) {
+	req := m.client.Search().Index(strings.Join(sources, ",")).Scroll("1m").Size(defaultBackfillChunk).Version(true).Sort("_doc")
+	if query != nil {
+		req = req.Query(query)
+	}
+	res, err := req.Do(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("scan %v failed: %w", sources, err)
+	}
+	var total, changed int64
+	for len(res.Hits.Hits) > 0 {
+		bulk := m.client.Bulk().Index(dest)
+		for _, hit := range res.Hits.Hits {
+			doc, err := m.legacyDocument(tf, hit.Source_)
+			if err != nil {
+				return 0, 0, fmt.Errorf("convert document %s of %v failed: %w", stringValue(hit.Id_), sources, err)
+			}
+			op := types.IndexOperation{Id_: hit.Id_, Version: hit.Version_, VersionType: &versiontype.External}
+			if err := bulk.IndexOp(op, doc); err != nil {
+				return 0, 0, err
+			}
+		}
+		resp, err := bulk.Do(ctx)
+		if err != nil {
+			return 0, 0, fmt.Errorf("bulk %d documents to %s failed: %w", len(res.Hits.Hits), dest, err)
+		}
+		for _, item := range resp.Items {
+			for _, r := range item {
+				switch {
+				case r.Status == http.StatusConflict:
+					// dest 中已经是更新的版本
+				case r.Error != nil:
+					return 0, 0, fmt.Errorf("index document %s to %s failed: %s", stringValue(r.Id_), dest, stringValue(r.Error.Reason))
+				default:
+					changed++
+				}
+			}
+		}
+		total += int64(len(res.Hits.Hits))
+		if res.ScrollId_ == nil {
+			break
+		}
+		next, err := m.client.Scroll().ScrollId(*res.ScrollId_).Scroll("1m").Do(ctx)
+		if err != nil {
+			return 0, 0, fmt.Errorf("scan %v failed: %w", sources, err)
+		}
+		res.Hits, res.ScrollId_ = next.Hits, next.ScrollId_
+	}
+	if res.ScrollId_ != nil {
+		_, _ = m.client.ClearScroll().ScrollId(*res.ScrollId_).Do(ctx)
+	}
+	if _, err := m.client.Indices.Refresh().Index(dest).Do(ctx); err != nil {
+		return 0, 0, fmt.Errorf("refresh %s failed: %w", dest, err)
+	}
+	return total, changed, nil
+}
+
+// legacyDocument 将旧索引中的一篇文档转换为 m.newDoc 的字段类型，保留 review-job 写入的版本号字段
+func (m *IndexManager) legacyDocument(tf *Transformer, source json.RawMessage) (search.Document, error) {
+	v := m.newDoc()
+	if err := tf.Legacy(source, v); err != nil {
+		return nil, err
+	}
+	doc, err := search.NewDocument(v)
+	if err != nil {
+		return nil, err
+	}
+	var versions map[string]json.RawMessage
+	if err := json.Unmarshal(source, &versions); err != nil {
+		return nil, err
+	}
+	for _, field := range []string{search.DefaultVersionField, replyVersionField} {
+		if version, ok := versions[field]; ok {
+			doc[field] = version
+		}
+	}
+	return doc, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// changedSince 查询 t 之后变更过的文档：评价的 update_at，以及合并进来的回复的 reply_at 和回复的版本号，
+// 回复的新增、修改不会改变评价的 update_at，回复被删除时 reply_at 被清空，只能按回复的版本号（binlog 时间）查到
+// 旧索引按动态 mapping 创建时时间是 text，只能对 keyword 子字段做范围查询，也没有版本号字段
+func (m *IndexManager) changedSince(t time.Time, legacy bool) *types.Query {
+	since := t.Add(-catchUpMargin)
+	if legacy {
+		gte := since.Format(mysqlDateTime)
+		return &types.Query{Bool: &types.BoolQuery{Should: []types.Query{
+			{Range: map[string]types.RangeQuery{"update_at.keyword": types.TermRangeQuery{Gte: &gte}}},
+			{Range: map[string]types.RangeQuery{"reply_at.keyword": types.TermRangeQuery{Gte: &gte}}},
+		}}}
+	}
+	gte := since.Format(time.RFC3339)
+	format := "strict_date_optional_time"
+	version := types.Float64(since.UnixMilli())
+	return &types.Query{Bool: &types.BoolQuery{Should: []types.Query{
+		{Range: map[string]types.RangeQuery{"update_at": types.DateRangeQuery{Gte: &gte, Format: &format}}},
+		{Range: map[string]types.RangeQuery{"reply_at": types.DateRangeQuery{Gte: &gte, Format: &format}}},
+		{Range: map[string]types.RangeQuery{replyVersionField: types.NumberRangeQuery{Gte: &version}}},
+	}}}
+}
+
+func count(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,80 @@
+package job
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"review-job/internal/conf"
+	"review-service/pkg/search"
+)
+
+// legacyReview canal 按动态 mapping 写入旧索引的评价，列都是字符串；回复字段和版本号由 review-job 之后合并写入，已经是强类型的
+const legacyReview = `{
+	"id": "7", "create_by": "1001", "update_by": "1001",
+	"create_at": "2024-05-01 10:00:00", "update_at": "2024-05-02 11:30:00", "delete_at": null,
+	"version": "2", "review_id": "530000000000001", "content": "尺码偏大，物流很快",
+	"score": "5", "service_score": "4", "express_score": "5", "has_media": "1",
+	"order_id": "88001", "sku_id": "301", "spu_id": "300", "store_id": "12", "user_id": "1001",
+	"anonymous": "0", "tags": "", "pic_info": "[\"a.jpg\"]", "video_info": "",
+	"status": "20", "is_default": "0", "op_reason": "", "op_remarks": "", "op_user": "",
+	"goods_snapshoot": "", "ext_json": "", "ctrl_json": "",
+	"has_reply": true, "reply_id": 9001, "reply_content": "感谢支持", "reply_at": "2024-05-03T09:00:00+08:00",
+	"__reply_version": 1714698000000
+}`
+
+func TestLegacyDocument(t *testing.T) {
+	// 原样复制的旧文档无法按 review-service 的方式解码
+	if err := json.Unmarshal([]byte(legacyReview), new(search.ReviewDoc)); err == nil {
+		t.Fatal("legacy document decoded without conversion")
+	}
+
+	tf, err := NewTransformer(&conf.Data{Database: &conf.Data_Database{TimeZone: "Asia/Shanghai"}})
+	if err != nil {
+		t.Fatalf("new transformer: %v", err)
+	}
+	m := NewIndexManagers(nil, &conf.Elasticsearch{Index: "review"}, log.DefaultLogger)[IndexReview]
+	doc, err := m.legacyDocument(tf, json.RawMessage(legacyReview))
+	if err != nil {
+		t.Fatalf("convert legacy document: %v", err)
+	}
+	if string(doc[replyVersionField].(json.RawMessage)) != "1714698000000" {
+		t.Errorf("%s = %v, want the merged reply version", replyVersionField, doc[replyVersionField])
+	}
+	if _, ok := doc[search.DefaultVersionField]; ok {
+		t.Errorf("%s = %v, want no version for a document never written by review-job", search.DefaultVersionField, doc[search.DefaultVersionField])
+	}
+
+	// 写入新索引的 _source 按 review-service 读取评价的方式解码
+	source, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal document: %v", err)
+	}
+	got := new(search.ReviewDoc)
+	if err := json.Unmarshal(source, got); err != nil {
+		t.Fatalf("decode converted document: %v", err)
+	}
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	if got.ReviewID != 530000000000001 || got.StoreID != 12 || got.Score != 5 || got.Status != 20 || got.Version != 2 {
+		t.Errorf("numbers = %+v", got)
+	}
+	if !got.HasMedia || got.Anonymous || got.IsDefault {
+		t.Errorf("has_media = %v, anonymous = %v, is_default = %v, want true, false, false", got.HasMedia, got.Anonymous, got.IsDefault)
+	}
+	if want := time.Date(2024, 5, 1, 10, 0, 0, 0, loc); !got.CreateAt.Equal(want) {
+		t.Errorf("create_at = %v, want %v", got.CreateAt, want)
+	}
+	if want := time.Date(2024, 5, 2, 11, 30, 0, 0, loc); !got.UpdateAt.Equal(want) {
+		t.Errorf("update_at = %v, want %v", got.UpdateAt, want)
+	}
+	if got.DeleteAt != nil {
+		t.Errorf("delete_at = %v, want nil", got.DeleteAt)
+	}
+	if string(got.PicInfo) != `["a.jpg"]` {
+		t.Errorf("pic_info = %s, want the parsed array", got.PicInfo)
+	}
+	if want := time.Date(2024, 5, 3, 9, 0, 0, 0, loc); !got.HasReply || got.ReplyID != 9001 || got.ReplyContent != "感谢支持" || got.ReplyAt == nil || !got.ReplyAt.Equal(want) {
+		t.Errorf("reply = %v %d %q %v, want the merged reply", got.HasReply, got.ReplyID, got.ReplyContent, got.ReplyAt)
+	}
+}
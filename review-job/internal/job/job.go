@@ -2,4 +2,5 @@ package job
 
 import "github.com/google/wire"
 
-var ProviderSet = wire.NewSet(NewJobWorker, NewKafkaReader, NewESClient)
+var ProviderSet = wire.NewSet(NewJobWorker, NewChangeSource, NewDecoders, NewSearchIndices, NewRouter, NewTransformer, NewBloomFilter, NewDeadLetter,
+	NewAlerter, NewSchemaGuard, NewFieldUpdater, NewVerifier, NewOutboxRelay, NewReviewCreator)
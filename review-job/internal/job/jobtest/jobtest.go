@@ -0,0 +1,306 @@
+// Package jobtest 用录制的 canal 消息回放 JobWorker 的索引同步逻辑
+//
+//	func TestCanalFixtures(t *testing.T) {
+//		jobtest.Run(t, jobtest.NewBleveIndex)
+//	}
+//
+// fixtures 目录下是同一条评价依次经历新增、修改、隐藏、恢复展示、逻辑删除、撤销删除、物理删除的 canal 消息，
+// 以及这条评价的商家回复、申诉、review_info 新增列的 DDL 和一张没有处理规则的表的消息；
+// fixtures/debezium 目录下是同一条评价由 Debezium 输出的新增、快照读取、修改、逻辑删除、删除、tombstone 和 DDL 消息。
+// 部分用例重复回放旧消息，模拟 kafka 的重复投递
+package jobtest
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/segmentio/kafka-go"
+	"review-job/internal/conf"
+	"review-job/internal/job"
+	"review-service/pkg/search"
+)
+
+//go:embed fixtures/*.json fixtures/debezium/*.json
+var fixtures embed.FS
+
+// fixtures 中评价和申诉的 ID
+const (
+	reviewID = "7198357715427348481"
+	appealID = "7198358470863437825"
+	// replyContent 商家回复的内容
+	replyContent = "感谢您的支持，欢迎再次光临"
+	// timeZone 录制 fixtures 的数据库所在的时区
+	timeZone = "Asia/Shanghai"
+	// ipLocation review_ddl.json 新增的列在 review_ip_update.json 中的值
+	ipLocation = "浙江"
+)
+
+// NewIndex 每个用例为评价和申诉各调用一次，返回一个按 m 创建的空索引
+type NewIndex func(t *testing.T, m search.Mapping) search.Index
+
+// NewBleveIndex 内存中的 bleve 索引，不依赖 ES
+func NewBleveIndex(t *testing.T, m search.Mapping) search.Index {
+	t.Helper()
+	index, err := search.NewBleve("", m)
+	if err != nil {
+		t.Fatalf("new bleve index: %v", err)
+	}
+	t.Cleanup(func() { _ = index.Close() })
+	return index
+}
+
+// Fixture 读取录制的消息，debezium 目录下的按 Debezium 格式解析，其余按 canal flat message 解析；
+// 不需要处理的消息（如 tombstone）返回 nil
+func Fixture(t *testing.T, name string) *job.Msg {
+	t.Helper()
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	tf, err := job.NewTransformer(&conf.Data{Database: &conf.Data_Database{TimeZone: timeZone}})
+	if err != nil {
+		t.Fatalf("new transformer: %v", err)
+	}
+	// 以目录名作为 topic
+	decoders, err := job.NewDecoders(&conf.Kafka{Formats: map[string]string{job.FormatDebezium: job.FormatDebezium}}, tf)
+	if err != nil {
+		t.Fatalf("new decoders: %v", err)
+	}
+	topic := path.Dir(name)
+	msg, err := decoders.For(topic).Decode(kafka.Message{Topic: topic, Value: data})
+	if err != nil {
+		t.Fatalf("decode fixture %s: %v", name, err)
+	}
+	return msg
+}
+
+// Run 依次回放 fixtures 并检查索引中的文档
+func Run(t *testing.T, newIndex NewIndex) {
+	cases := []struct {
+		name    string
+		replay  []string
+		exists  bool
+		status  int32  // 文档存在时期望的 status
+		content string // 不为空时检查内容
+		visible bool   // 是否能被列表和搜索的可见性规则查到
+		typed   bool   // 检查各列转换后的类型
+		// hasReply 不为空时检查评价文档中合并的回复（"true"、"false"），reply 为期望的回复内容
+		hasReply string
+		reply    string
+		appeal   int32  // 申诉文档的 status，为 0 时申诉文档不应存在
+		notFound string // 因评价不存在而失败的消息
+		ignored  int64  // 没有处理规则而被忽略的行数
+		ddl      int64  // 跳过的 DDL 消息数
+		// added 不为空时 review_info 的结构与文档不一致，该列应加入评价索引的 mapping 并写入文档
+		added string
+	}{
+		{name: "insert", replay: []string{"review_insert.json"}, exists: true, status: 10, content: "物流很快，包装完好", visible: true, typed: true},
+		{name: "update overwrites document", replay: []string{"review_insert.json", "review_update.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true},
+		{name: "hidden status keeps document", replay: []string{"review_insert.json", "review_hidden.json"}, exists: true, status: 40},
+		{name: "visible again", replay: []string{"review_insert.json", "review_hidden.json", "review_visible.json"}, exists: true, status: 20, visible: true},
+		{name: "soft delete removes document", replay: []string{"review_insert.json", "review_soft_delete.json"}},
+		{name: "restore after soft delete", replay: []string{"review_insert.json", "review_soft_delete.json", "review_restore.json"}, exists: true, status: 20, visible: true},
+		{name: "delete removes document", replay: []string{"review_insert.json", "review_update.json", "review_delete.json"}},
+		{name: "delete missing document", replay: []string{"review_delete.json"}},
+		// 重复投递：旧消息的版本号小于索引中的文档，不会覆盖新数据
+		{name: "redelivered insert keeps update", replay: []string{"review_insert.json", "review_update.json", "review_insert.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true},
+		{name: "redelivered soft delete keeps restore", replay: []string{"review_insert.json", "review_soft_delete.json", "review_restore.json", "review_soft_delete.json"}, exists: true, status: 20, visible: true},
+		{name: "redelivered update is idempotent", replay: []string{"review_insert.json", "review_update.json", "review_update.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true},
+		// 回复合并到评价文档，评价和回复各自使用自己的版本号
+		{name: "reply merges into review", replay: []string{"review_insert.json", "reply_insert.json"}, exists: true, status: 10, content: "物流很快，包装完好", visible: true, hasReply: "true", reply: replyContent},
+		{name: "review update keeps reply", replay: []string{"review_insert.json", "reply_insert.json", "review_update.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true, hasReply: "true", reply: replyContent},
+		{name: "reply arrives after newer review update", replay: []string{"review_insert.json", "review_update.json", "reply_insert.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true, hasReply: "true", reply: replyContent},
+		{name: "reply delete clears reply", replay: []string{"review_insert.json", "reply_insert.json", "review_update.json", "reply_delete.json"}, exists: true, status: 10, visible: true, hasReply: "false"},
+		{name: "redelivered reply keeps delete", replay: []string{"review_insert.json", "reply_insert.json", "reply_delete.json", "reply_insert.json"}, exists: true, status: 10, visible: true, hasReply: "false"},
+		{name: "reply before review", replay: []string{"reply_insert.json"}, notFound: "reply_insert.json"},
+		// 申诉写入单独的索引，不影响评价文档
+		{name: "appeal indexed separately", replay: []string{"review_insert.json", "appeal_insert.json"}, exists: true, status: 10, content: "物流很快，包装完好", visible: true, appeal: 10},
+		{name: "appeal update", replay: []string{"review_insert.json", "appeal_insert.json", "appeal_update.json", "appeal_insert.json"}, exists: true, status: 10, visible: true, appeal: 20},
+		{name: "unknown table ignored", replay: []string{"review_insert.json", "store_stat_insert.json"}, exists: true, status: 10, visible: true, ignored: 1},
+		// DDL 不作为数据写入，之后的第一条 DML 按 mysqlType 检查表结构，新增的列加入 mapping
+		{name: "ddl skipped as data", replay: []string{"review_insert.json", "review_ddl.json"}, exists: true, status: 10, content: "物流很快，包装完好", visible: true, typed: true, ddl: 1},
+		{name: "added column applied after ddl", replay: []string{"review_insert.json", "review_ddl.json", "review_ip_update.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true, ddl: 1, added: "ip_location"},
+		// Debezium 的消息转换为与 canal 相同的行，写入的文档一致
+		{name: "debezium insert", replay: []string{"debezium/review_insert.json"}, exists: true, status: 10, content: "物流很快，包装完好", visible: true, typed: true},
+		{name: "debezium snapshot read", replay: []string{"debezium/review_read.json"}, exists: true, status: 10, content: "物流很快，包装完好", visible: true, typed: true},
+		{name: "debezium update", replay: []string{"debezium/review_insert.json", "debezium/review_update.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true},
+		{name: "debezium redelivered insert keeps update", replay: []string{"debezium/review_insert.json", "debezium/review_update.json", "debezium/review_insert.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true},
+		{name: "debezium soft delete removes document", replay: []string{"debezium/review_insert.json", "debezium/review_update.json", "debezium/review_soft_delete.json"}},
+		{name: "debezium delete and tombstone", replay: []string{"debezium/review_insert.json", "debezium/review_delete.json", "debezium/review_tombstone.json"}},
+		{name: "debezium added column applied after ddl", replay: []string{"debezium/review_insert.json", "debezium/review_ddl.json", "debezium/review_ip_update.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true, ddl: 1, added: "ip_location"},
+		// 同一张表的消息可以来自不同的格式，版本号都是 binlog 的执行时间
+		{name: "canal update after debezium insert", replay: []string{"debezium/review_insert.json", "review_update.json", "debezium/review_insert.json"}, exists: true, status: 10, content: "物流很快，包装完好，客服态度也不错", visible: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			indices := job.Indices{
+				job.IndexReview: newIndex(t, search.ReviewMapping),
+				job.IndexAppeal: newIndex(t, search.AppealMapping),
+			}
+			index := indices[job.IndexReview]
+			tf, err := job.NewTransformer(&conf.Data{Database: &conf.Data_Database{TimeZone: timeZone}})
+			if err != nil {
+				t.Fatalf("new transformer: %v", err)
+			}
+			fields := fieldRecorder{}
+			schema := job.NewSchemaGuard(tf, fields, &job.Alerter{}, log.DefaultLogger)
+			router := job.NewRouter(tf, schema, log.DefaultLogger)
+			decoders, err := job.NewDecoders(&conf.Kafka{}, tf)
+			if err != nil {
+				t.Fatalf("new decoders: %v", err)
+			}
+			worker := job.NewJobWorker(nil, decoders, indices, router, &job.BloomFilter{}, &job.DeadLetter{}, &conf.Kafka{}, &conf.Search{}, log.DefaultLogger)
+			for _, name := range c.replay {
+				msg := Fixture(t, name)
+				if msg == nil {
+					continue
+				}
+				err := worker.Handle(ctx, msg)
+				if name == c.notFound && errors.Is(err, search.ErrNotFound) {
+					continue
+				}
+				if err != nil {
+					t.Fatalf("handle %s: %v", name, err)
+				}
+			}
+
+			stats := router.Stats()
+			var ignored int64
+			for _, n := range stats.Ignored {
+				ignored += n
+			}
+			if ignored != c.ignored {
+				t.Fatalf("ignored rows = %d, want %d", ignored, c.ignored)
+			}
+			if stats.DDL["review_info"] != c.ddl {
+				t.Fatalf("ddl = %d, want %d", stats.DDL["review_info"], c.ddl)
+			}
+			if drifted := len(stats.Drifted) > 0; drifted != (c.added != "") {
+				t.Fatalf("drifted tables = %v, want drifted %v", stats.Drifted, c.added != "")
+			}
+			if c.added != "" && fields[job.IndexReview+"."+c.added] != search.Keyword {
+				t.Fatalf("mapping updates = %v, want %s as keyword", fields, c.added)
+			}
+			appeal := new(search.AppealDoc)
+			found, err := get(ctx, indices[job.IndexAppeal], "appeal_id", appealID, nil, appeal)
+			if err != nil {
+				t.Fatalf("query appeal: %v", err)
+			}
+			if found != (c.appeal != 0) {
+				t.Fatalf("appeal exists = %v, want %v", found, c.appeal != 0)
+			}
+			if found && appeal.Status != c.appeal {
+				t.Fatalf("appeal status = %d, want %d", appeal.Status, c.appeal)
+			}
+
+			doc := new(search.ReviewDoc)
+			found, err = get(ctx, index, "review_id", reviewID, nil, doc)
+			if err != nil {
+				t.Fatalf("query document: %v", err)
+			}
+			if found != c.exists {
+				t.Fatalf("document exists = %v, want %v", found, c.exists)
+			}
+			if !found {
+				return
+			}
+			if doc.Status != c.status {
+				t.Fatalf("status = %d, want %d", doc.Status, c.status)
+			}
+			if c.content != "" && doc.Content != c.content {
+				t.Fatalf("content = %s, want %s", doc.Content, c.content)
+			}
+			if c.typed {
+				checkTyped(t, doc)
+			}
+			if c.added != "" {
+				source := map[string]interface{}{}
+				if _, err := get(ctx, index, "review_id", reviewID, nil, &source); err != nil {
+					t.Fatalf("query document source: %v", err)
+				}
+				if source[c.added] != ipLocation {
+					t.Fatalf("%s = %v, want %s", c.added, source[c.added], ipLocation)
+				}
+			}
+			if c.hasReply != "" {
+				if strconv.FormatBool(doc.HasReply) != c.hasReply {
+					t.Fatalf("has_reply = %v, want %s", doc.HasReply, c.hasReply)
+				}
+				if doc.ReplyContent != c.reply {
+					t.Fatalf("reply_content = %q, want %q", doc.ReplyContent, c.reply)
+				}
+			}
+			visible, err := get(ctx, index, "review_id", reviewID, []search.Filter{
+				{Field: "delete_at", Exists: true},
+				{Field: "status", Values: []string{"30", "40"}},
+			}, new(search.ReviewDoc))
+			if err != nil {
+				t.Fatalf("query visible document: %v", err)
+			}
+			if visible != c.visible {
+				t.Fatalf("document visible = %v, want %v", visible, c.visible)
+			}
+		})
+	}
+}
+
+// fieldRecorder 记录 SchemaGuard 加入 mapping 的字段，键为 索引名.字段名
+type fieldRecorder map[string]search.FieldType
+
+func (r fieldRecorder) PutFields(_ context.Context, index string, fields search.Mapping) error {
+	for name, typ := range fields {
+		r[index+"."+name] = typ
+	}
+	return nil
+}
+
+// get 按 field 等于 id 查询文档并解析到 dst，excludes 与 review-service 的可见性规则一致
+func get(ctx context.Context, index search.Index, field, id string, excludes []search.Filter, dst interface{}) (bool, error) {
+	res, err := index.Query(ctx, &search.Query{
+		Filters:  []search.Filter{{Field: field, Values: []string{id}}},
+		Excludes: excludes,
+		Size:     1,
+	})
+	if err != nil || len(res.Hits) == 0 {
+		return false, err
+	}
+	return true, json.Unmarshal(res.Hits[0].Source, dst)
+}
+
+// checkTyped 检查 review_insert.json 转换后的字段类型：整数、布尔、带时区的时间和解析后的 JSON
+func checkTyped(t *testing.T, doc *search.ReviewDoc) {
+	t.Helper()
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		t.Fatalf("load time zone: %v", err)
+	}
+	if want := time.Date(2024, 5, 20, 10, 31, 7, 0, loc); !doc.CreateAt.Equal(want) {
+		t.Fatalf("create_at = %v, want %v", doc.CreateAt, want)
+	}
+	if _, offset := doc.CreateAt.Zone(); offset != 8*3600 {
+		t.Fatalf("create_at offset = %d, want +08:00", offset)
+	}
+	if doc.DeleteAt != nil {
+		t.Fatalf("delete_at = %v, want null", doc.DeleteAt)
+	}
+	if doc.ReviewID != 7198357715427348481 || doc.Score != 5 || doc.ExpressScore != 4 || doc.OrderID != 20240520001 {
+		t.Fatalf("numeric fields = %d/%d/%d/%d", doc.ReviewID, doc.Score, doc.ExpressScore, doc.OrderID)
+	}
+	if !doc.HasMedia || doc.Anonymous || doc.IsDefault {
+		t.Fatalf("has_media/anonymous/is_default = %v/%v/%v, want true/false/false", doc.HasMedia, doc.Anonymous, doc.IsDefault)
+	}
+	if string(doc.PicInfo) != `["https://img.example.com/r/1.jpg"]` {
+		t.Fatalf("pic_info = %s", doc.PicInfo)
+	}
+	if search.JSONText(doc.Tags) != "" || search.JSONText(doc.VideoInfo) != "" {
+		t.Fatalf("tags/video_info = %s/%s, want null", doc.Tags, doc.VideoInfo)
+	}
+}
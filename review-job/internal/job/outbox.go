@@ -0,0 +1,262 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/segmentio/kafka-go"
+	"review-job/internal/conf"
+	"review-service/pkg/event"
+)
+
+const (
+	defaultOutboxInterval  = 500 * time.Millisecond
+	defaultOutboxBatchSize = 100
+	defaultOutboxRetention = 7 * 24 * time.Hour
+	// outboxLockName 每个库同时只有一个副本投递，并发投递会打乱同一条评价的事件的顺序
+	outboxLockName = "review_outbox_relay"
+	// outboxPurgeInterval 清理已投递事件的间隔，每条 DELETE 最多删除 outboxPurgeLimit 行
+	outboxPurgeInterval = time.Hour
+	outboxPurgeLimit    = 1000
+)
+
+// OutboxRelay 将 review-service 写入发件箱 review_outbox 的领域事件投递到 kafka，实现 transport.Server
+// 每个库一个 goroutine，按 id 的顺序读取未投递的事件，以聚合 ID 为 key 写入，kafka 确认后才标记为已投递；
+// 标记之前退出或出错的事件会再次投递，即至少一次，消费方按 event_id 去重。
+// 同一条评价的事件位于同一个库，投递到同一个分区，顺序与写入时一致
+type OutboxRelay struct {
+	topic     string
+	databases []*conf.Data_Database
+	interval  time.Duration
+	batch     int
+	retention time.Duration
+	writer    *kafka.Writer
+	log       *log.Helper
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewOutboxRelay 未配置 outbox.topic 时 Start 直接返回，不连接数据库
+func NewOutboxRelay(cfg *conf.Outbox, kc *conf.Kafka, dc *conf.Data, logger log.Logger) *OutboxRelay {
+	r := &OutboxRelay{
+		topic:     cfg.GetTopic(),
+		interval:  defaultOutboxInterval,
+		batch:     defaultOutboxBatchSize,
+		retention: defaultOutboxRetention,
+		log:       log.NewHelper(logger),
+		stop:      make(chan struct{}),
+	}
+	if cfg.GetInterval().AsDuration() > 0 {
+		r.interval = cfg.GetInterval().AsDuration()
+	}
+	if cfg.GetBatchSize() > 0 {
+		r.batch = int(cfg.GetBatchSize())
+	}
+	if cfg.GetRetention().AsDuration() > 0 {
+		r.retention = cfg.GetRetention().AsDuration()
+	}
+	for _, dsn := range cfg.GetDatabases() {
+		r.databases = append(r.databases, &conf.Data_Database{Driver: dc.GetDatabase().GetDriver(), Source: dsn})
+	}
+	if len(r.databases) == 0 {
+		r.databases = append(r.databases, dc.GetDatabase())
+	}
+	if r.topic != "" {
+		r.writer = &kafka.Writer{
+			Addr:         kafka.TCP(kc.GetBrokers()...),
+			Topic:        r.topic,
+			Balancer:     &kafka.Hash{}, // 同一个聚合的事件写入同一个分区
+			RequiredAcks: kafka.RequireAll,
+			BatchSize:    r.batch,
+			BatchTimeout: 10 * time.Millisecond,
+		}
+	}
+	return r
+}
+
+// Start 连接各个库开始投递，直到 Stop
+func (r *OutboxRelay) Start(ctx context.Context) error {
+	if r.writer == nil {
+		r.log.Info("outbox topic is not configured, relay disabled")
+		return nil
+	}
+	dbs := make([]*sql.DB, 0, len(r.databases))
+	defer func() {
+		for _, db := range dbs {
+			_ = db.Close()
+		}
+	}()
+	for _, c := range r.databases {
+		db, err := NewMySQL(c)
+		if err != nil {
+			return fmt.Errorf("connect outbox database failed: %w", err)
+		}
+		dbs = append(dbs, db)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for i, db := range dbs {
+		r.wg.Add(1)
+		go func(idx int, db *sql.DB) {
+			defer r.wg.Done()
+			r.relay(ctx, idx, db)
+		}(i, db)
+	}
+	r.log.Infof("outbox relay started, databases: %d, topic: %s", len(dbs), r.topic)
+	select {
+	case <-r.stop:
+	case <-ctx.Done():
+	}
+	cancel()
+	r.wg.Wait()
+	return nil
+}
+
+// Stop 停止投递，正在写入 kafka 的事件没有标记，重启后再次投递
+func (r *OutboxRelay) Stop(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stop) })
+	if r.writer == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return r.writer.Close()
+}
+
+// relay 持续投递第 idx 个库中的事件，出错或锁被其他副本持有时等待 interval 后重试
+func (r *OutboxRelay) relay(ctx context.Context, idx int, db *sql.DB) {
+	for {
+		if err := r.lead(ctx, db); err != nil && ctx.Err() == nil {
+			r.log.Errorf("relay outbox of database %d failed, err:%v", idx, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// lead 在同一个连接上持有命名锁并投递，连接断开时 MySQL 会释放锁，由其他副本接替
+func (r *OutboxRelay) lead(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", outboxLockName).Scan(&locked); err != nil {
+		return err
+	}
+	if locked.Int64 != 1 {
+		return nil
+	}
+	// 连接归还到连接池之前释放锁
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", outboxLockName)
+	}()
+
+	var purgedAt time.Time
+	for {
+		n, err := r.publish(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if time.Since(purgedAt) > outboxPurgeInterval {
+			if err := r.purge(ctx, conn); err != nil {
+				r.log.Errorf("purge published outbox events failed, err:%v", err)
+			}
+			purgedAt = time.Now()
+		}
+		if n == r.batch {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// publish 投递一批未投递的事件，返回事件数
+func (r *OutboxRelay) publish(ctx context.Context, conn *sql.Conn) (int, error) {
+	rows, err := conn.QueryContext(ctx,
+		"SELECT id, event_id, event_type, aggregate_type, aggregate_id, payload FROM review_outbox WHERE published_at IS NULL ORDER BY id LIMIT ?",
+		r.batch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var (
+		ids  []interface{}
+		msgs []kafka.Message
+	)
+	for rows.Next() {
+		var (
+			id, eventID, aggregateID int64
+			eventType, aggregateType string
+			payload                  []byte
+		)
+		if err := rows.Scan(&id, &eventID, &eventType, &aggregateType, &aggregateID, &payload); err != nil {
+			return 0, err
+		}
+		ids = append(ids, id)
+		msgs = append(msgs, kafka.Message{
+			Key:   []byte(strconv.FormatInt(aggregateID, 10)),
+			Value: payload,
+			Headers: []kafka.Header{
+				{Key: event.HeaderEventID, Value: []byte(strconv.FormatInt(eventID, 10))},
+				{Key: event.HeaderEventType, Value: []byte(eventType)},
+				{Key: event.HeaderAggregateType, Value: []byte(aggregateType)},
+			},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	// 同一个分区中的消息按传入的顺序写入
+	if err := r.writer.WriteMessages(ctx, msgs...); err != nil {
+		return 0, fmt.Errorf("write %d events failed: %w", len(msgs), err)
+	}
+	_, err = conn.ExecContext(ctx,
+		"UPDATE review_outbox SET published_at = CURRENT_TIMESTAMP WHERE id IN (?"+strings.Repeat(",?", len(ids)-1)+")",
+		ids...)
+	if err != nil {
+		return 0, fmt.Errorf("mark %d events published failed: %w", len(ids), err)
+	}
+	return len(msgs), nil
+}
+
+// purge 分批删除投递时间早于 retention 的事件，按数据库时间比较
+func (r *OutboxRelay) purge(ctx context.Context, conn *sql.Conn) error {
+	for {
+		res, err := conn.ExecContext(ctx,
+			"DELETE FROM review_outbox WHERE published_at < NOW() - INTERVAL ? SECOND LIMIT ?",
+			int64(r.retention.Seconds()), outboxPurgeLimit)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n < outboxPurgeLimit {
+			return err
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"review-job/internal/conf"
+)
+
+const (
+	defaultWorkers     = 4
+	defaultMaxInFlight = 1000
+)
+
+// poolOption worker 数和在途消息数上限
+type poolOption struct {
+	workers     int
+	maxInFlight int
+}
+
+func newPoolOption(cfg *conf.Kafka) poolOption {
+	opt := poolOption{workers: defaultWorkers, maxInFlight: defaultMaxInFlight}
+	if cfg.GetWorkers() > 0 {
+		opt.workers = int(cfg.GetWorkers())
+	}
+	if cfg.GetMaxInFlight() > 0 {
+		opt.maxInFlight = int(cfg.GetMaxInFlight())
+	}
+	return opt
+}
+
+// tracked 一条在途的 kafka 消息，写入索引或死信队列后 done 为 true
+type tracked struct {
+	m    kafka.Message
+	done bool
+}
+
+// task 分发给 worker 的一条消息，解析失败时 msg 为 nil，letter 为需要写入死信队列的原因
+type task struct {
+	item   *tracked
+	msg    *Msg
+	letter *Letter
+}
+
+// topicPartition 订阅多个 topic 时不同 topic 的分区编号相同，按 topic 和分区区分
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// offsetTracker 按分区记录在途的消息，只提交每个分区从头开始连续处理完的消息的 offset
+// 各个 worker 完成的顺序与拉取的顺序不同，靠后的消息先写完时要等前面的消息也写完才提交，保证至少处理一次
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[topicPartition][]*tracked // 按拉取的顺序
+	commit     func(ctx context.Context, msgs ...kafka.Message) error
+}
+
+func (t *offsetTracker) add(m kafka.Message) *tracked {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	item := &tracked{m: m}
+	tp := topicPartition{topic: m.Topic, partition: m.Partition}
+	t.partitions[tp] = append(t.partitions[tp], item)
+	return item
+}
+
+// complete 标记一批消息已处理，提交各分区连续处理完的最后一条消息
+// 提交在锁内进行，多个 worker 的提交按顺序执行，同一分区的 offset 不会回退
+func (t *offsetTracker) complete(ctx context.Context, items []*tracked) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, item := range items {
+		item.done = true
+	}
+	var commits []kafka.Message
+	for partition, queue := range t.partitions {
+		n := 0
+		for n < len(queue) && queue[n].done {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		commits = append(commits, queue[n-1].m)
+		t.partitions[partition] = queue[n:]
+	}
+	if len(commits) == 0 {
+		return nil
+	}
+	if err := t.commit(ctx, commits...); err != nil {
+		return fmt.Errorf("commit offsets of %d partitions failed: %w", len(commits), err)
+	}
+	return nil
+}
+
+// pool 按 review_id 分片的 worker：同一条评价的消息总是交给同一个 worker，按拉取的顺序写入；
+// 在途消息达到上限时 dispatch 阻塞，不再从 kafka 拉取新消息
+type pool struct {
+	jw      JobWorker
+	shards  []chan task
+	slots   chan struct{}
+	offsets *offsetTracker
+	wg      sync.WaitGroup
+
+	// ctx 在任意一个 worker 写入失败后取消，停止分发
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// newPool 启动 worker，commit 用于提交 offset
+func (jw JobWorker) newPool(ctx context.Context, commit func(ctx context.Context, msgs ...kafka.Message) error) *pool {
+	p := &pool{
+		jw:      jw,
+		shards:  make([]chan task, jw.pool.workers),
+		slots:   make(chan struct{}, jw.pool.maxInFlight),
+		offsets: &offsetTracker{partitions: map[topicPartition][]*tracked{}, commit: commit},
+	}
+	p.ctx, p.cancel = context.WithCancelCause(ctx)
+	for i := range p.shards {
+		p.shards[i] = make(chan task)
+		p.wg.Add(1)
+		go p.work(ctx, p.shards[i])
+	}
+	return p
+}
+
+// dispatch 解析消息后交给对应的 worker，在途消息达到上限时等待；worker 失败后返回错误
+func (p *pool) dispatch(m kafka.Message) error {
+	select {
+	case p.slots <- struct{}{}:
+	case <-p.ctx.Done():
+		return context.Cause(p.ctx)
+	}
+	msg, letter := p.jw.decode(m)
+	t := task{item: p.offsets.add(m), msg: msg, letter: letter}
+	select {
+	case p.shards[shard(m, msg, len(p.shards))] <- t:
+		return nil
+	case <-p.ctx.Done():
+		return context.Cause(p.ctx)
+	}
+}
+
+// close 停止分发，等待 worker 写完已收到的消息并提交 offset，返回第一个 worker 的错误
+func (p *pool) close() error {
+	for _, s := range p.shards {
+		close(s)
+	}
+	p.wg.Wait()
+	if p.ctx.Err() != nil {
+		return context.Cause(p.ctx)
+	}
+	p.cancel(nil)
+	return nil
+}
+
+// work 一个 worker：消息转换为写入操作后攒批，按条数、大小或时间间隔刷新一次，刷新成功后才提交这批消息的 offset
+func (p *pool) work(ctx context.Context, tasks <-chan task) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.jw.bulk.interval)
+	defer ticker.Stop()
+	b := new(batch)
+	for {
+		select {
+		case t, ok := <-tasks:
+			if !ok {
+				// 停止时写完已经收到的消息
+				if len(b.msgs) > 0 {
+					if err := p.flush(ctx, b); err != nil {
+						p.cancel(err)
+					}
+				}
+				return
+			}
+			var writes []Write
+			letter := t.letter
+			if t.msg != nil {
+				writes, letter = p.jw.convert(ctx, t.item.m, t.msg)
+			}
+			b.add(t.item, writes, letter)
+			if !b.full(p.jw.bulk) {
+				continue
+			}
+		case <-ticker.C:
+			if len(b.msgs) == 0 {
+				continue
+			}
+		}
+		if err := p.flush(ctx, b); err != nil {
+			// 重试耗尽，停止分发后由 Start 返回错误，未提交的消息重启后重新消费
+			p.cancel(err)
+			for range tasks {
+			}
+			return
+		}
+	}
+}
+
+// flush 写入一批操作，失败的消息写入死信队列后标记这批消息已处理，释放在途名额
+func (p *pool) flush(ctx context.Context, b *batch) error {
+	rejects, err := p.jw.write(ctx, b.writes)
+	if err != nil {
+		return err
+	}
+	b.reject(rejects)
+	if err := p.jw.dlq.Publish(ctx, b.letters...); err != nil {
+		return err
+	}
+	defer b.reset()
+	if err := p.offsets.complete(ctx, b.items); err != nil {
+		return err
+	}
+	for range b.items {
+		<-p.slots
+	}
+	return nil
+}
+
+// shard 按第一行的 review_id 选择 worker，没有 review_id 时按 kafka 消息的 key，再没有时按分区
+// 评价、回复和申诉的消息都带有 review_id，同一条评价的变更由同一个 worker 按顺序写入；
+// 一条消息中包含多条评价时按第一条分片，写入带有版本号，不会因顺序变化而覆盖新数据
+func shard(m kafka.Message, msg *Msg, n int) int {
+	key := m.Key
+	if msg != nil && len(msg.Data) > 0 {
+		if id, ok := msg.Data[0]["review_id"].(string); ok && id != "" {
+			key = []byte(id)
+		}
+	}
+	if len(key) == 0 {
+		key = []byte(strconv.Itoa(m.Partition))
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int(h.Sum32() % uint32(n))
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestOffsetTrackerTopics(t *testing.T) {
+	var committed []string
+	tracker := &offsetTracker{
+		partitions: map[topicPartition][]*tracked{},
+		commit: func(_ context.Context, msgs ...kafka.Message) error {
+			for _, m := range msgs {
+				committed = append(committed, fmt.Sprintf("%s@%d", m.Topic, m.Offset))
+			}
+			return nil
+		},
+	}
+	// 两个 topic 的 0 号分区交替拉取
+	review1 := tracker.add(kafka.Message{Topic: "review", Partition: 0, Offset: 1})
+	reply1 := tracker.add(kafka.Message{Topic: "reply", Partition: 0, Offset: 1})
+	review2 := tracker.add(kafka.Message{Topic: "review", Partition: 0, Offset: 2})
+	reply2 := tracker.add(kafka.Message{Topic: "reply", Partition: 0, Offset: 2})
+
+	steps := []struct {
+		done []*tracked
+		want []string
+	}{
+		// review 的第一条没有写完，reply 的第一条写完后只提交 reply
+		{done: []*tracked{reply1}, want: []string{"reply@1"}},
+		{done: []*tracked{review2}, want: nil},
+		{done: []*tracked{review1}, want: []string{"review@2"}},
+		{done: []*tracked{reply2}, want: []string{"reply@2"}},
+	}
+	for i, s := range steps {
+		committed = nil
+		if err := tracker.complete(context.Background(), s.done); err != nil {
+			t.Fatalf("step %d: complete: %v", i, err)
+		}
+		if !reflect.DeepEqual(committed, s.want) {
+			t.Fatalf("step %d: committed %v, want %v", i, committed, s.want)
+		}
+	}
+}
@@ -2,147 +2,295 @@ package job
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/segmentio/kafka-go"
 	"review-job/internal/conf"
+	"review-service/pkg/search"
 )
 
 // 评价数据流处理任务
 
-// 定义 kafka 中接收到的数据
+// 定义 kafka 中接收到的数据，canal 和 Debezium 的消息都由 Decoder 转换为这一格式
+// Data 为变更后的行（删除时为删除前的行），Old 为变更前的行：canal 只带有修改的列，Debezium 为整行
 type Msg struct {
 	Type     string                   `json:"type"`
 	Data     []map[string]interface{} `json:"data"`
 	Table    string                   `json:"table"`
 	IsDdl    bool                     `json:"isDdl"`
 	Database string                   `json:"database"`
+	// Es binlog 的执行时间（毫秒），作为写入操作的版本号
+	Es int64 `json:"es"`
+	// SQL DDL 消息中执行的语句
+	SQL string `json:"sql"`
+	// MysqlType DML 消息中每一列的 MySQL 类型，即变更时表的结构
+	MysqlType map[string]string        `json:"mysqlType"`
+	Old       []map[string]interface{} `json:"old"`
+	// Position 消息在来源中的位置，用于日志：canal 为 kafka 的 topic/partition/offset，Debezium 为 binlog 的 file:pos:row
+	Position string `json:"-"`
 }
 
 // 自定义执行 job，实现 transport.server
 type JobWorker struct {
-	kafkaReader *kafka.Reader
-	esClient    *ESClient
-	logger      *log.Helper
+	source   ChangeSource
+	decoders *Decoders
+	indices  Indices
+	router   *Router
+	bloom    *BloomFilter
+	dlq      *DeadLetter
+	bulk     bulkOption
+	pool     poolOption
+	run      *runState
+	logger   *log.Helper
 }
 
-type ESClient struct {
-	Client *elasticsearch.TypedClient
-	index  string
+// runState Start 和 Stop 之间的协作：Stop 通知停止拉取，等待 Start 写完在途的消息后再关闭 source
+type runState struct {
+	started  atomic.Bool
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
 }
 
-func NewJobWorker(kafka *kafka.Reader, esClient *ESClient, logger log.Logger) *JobWorker {
+func NewJobWorker(source ChangeSource, decoders *Decoders, indices Indices, router *Router, bloom *BloomFilter, dlq *DeadLetter, kc *conf.Kafka, cfg *conf.Search, logger log.Logger) *JobWorker {
 	return &JobWorker{
-		kafkaReader: kafka,
-		esClient:    esClient,
-		logger:      log.NewHelper(logger),
+		source:   source,
+		decoders: decoders,
+		indices:  indices,
+		router:   router,
+		bloom:    bloom,
+		dlq:      dlq,
+		bulk:     newBulkOption(cfg),
+		pool:     newPoolOption(kc),
+		run:      &runState{stop: make(chan struct{}), done: make(chan struct{})},
+		logger:   log.NewHelper(logger),
 	}
 }
 
+// NewKafkaReader 配置了 topics 时与 topic 一起按消费组订阅多个 topic
 func NewKafkaReader(cfg *conf.Kafka) (*kafka.Reader, error) {
-	return kafka.NewReader(kafka.ReaderConfig{
+	rc := kafka.ReaderConfig{
 		Brokers:  cfg.Brokers,
 		GroupID:  cfg.GroupId,
 		Topic:    cfg.Topic,
 		MaxBytes: 10e6, // 10MB
-	}), nil
+	}
+	if len(cfg.Topics) > 0 {
+		rc.Topic = ""
+		if cfg.Topic != "" {
+			rc.GroupTopics = append(rc.GroupTopics, cfg.Topic)
+		}
+		rc.GroupTopics = append(rc.GroupTopics, cfg.Topics...)
+	}
+	return kafka.NewReader(rc), nil
+}
+
+// NewSearchIndices 按配置创建评价和申诉两个索引，默认写入 ES
+// 使用 ES 时先确保索引模板和读写别名存在，之后的写入都通过别名进行
+func NewSearchIndices(cfgES *conf.Elasticsearch, cfg *conf.Search, logger log.Logger) (Indices, func(), error) {
+	indices := Indices{}
+	cleanup := func() {
+		for _, index := range indices {
+			_ = index.Close()
+		}
+	}
+	if cfg.GetBackend() == "bleve" {
+		mappings := map[string]search.Mapping{IndexReview: search.ReviewMapping, IndexAppeal: search.AppealMapping}
+		for name, m := range mappings {
+			path := cfg.GetPath()
+			if path != "" && name != IndexReview {
+				path += "_" + name
+			}
+			bleve, err := search.NewBleve(path, m)
+			if err != nil {
+				log.NewHelper(logger).Errorf("open bleve index %s at %q failed, err:%v", name, path, err)
+				cleanup()
+				return nil, nil, err
+			}
+			indices[name] = bleve
+		}
+		return indices, cleanup, nil
+	}
+	client, err := NewESClient(cfgES)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, m := range NewIndexManagers(client, cfgES, logger) {
+		if err := m.Ensure(context.Background()); err != nil {
+			return nil, nil, err
+		}
+		indices[name] = search.NewElasticsearch(client, m.Alias())
+	}
+	return indices, cleanup, nil
 }
 
-func NewESClient(cfg_es *conf.Elasticsearch) (*ESClient, error) {
+func NewESClient(cfgES *conf.Elasticsearch) (*elasticsearch.TypedClient, error) {
 	// ES 配置
 	cfg := elasticsearch.Config{
-		Addresses: cfg_es.Addr,
+		Addresses: cfgES.Addr,
 	}
 
 	// 创建客户端连接
 	client, err := elasticsearch.NewTypedClient(cfg)
 	if err != nil {
 		fmt.Printf("elasticsearch.NewTypedClient failed, err:%v\n", err)
-		return &ESClient{}, err
+		return nil, err
 	}
-	return &ESClient{
-		index:  cfg_es.Index,
-		Client: client,
-	}, nil
+	return client, nil
 }
 
+// errStopped 停止拉取后结束分发
+var errStopped = errors.New("job worker stopped")
+
 // Start 开始之后执行的程序
+// 消息按 review_id 分给多个 worker 并发写入，每个 worker 攒批后按条数、大小或时间间隔刷新一次；
+// 每个分区只提交连续处理完的消息的 offset，保证每条消息至少被处理一次
 func (jw JobWorker) Start(ctx context.Context) error {
-	jw.logger.Debugf("job worker starting")
-	// 接收消息
-	for {
-		m, err := jw.kafkaReader.ReadMessage(ctx)
-		if errors.Is(err, context.Canceled) {
-			return nil
-		}
-		if err != nil {
-			jw.logger.Error("read message error:", err)
-			break
-		}
-		fmt.Printf("message at offset %d: %s = %s\n", m.Offset, string(m.Key), string(m.Value))
+	jw.logger.Debugf("job worker starting with %d workers", jw.pool.workers)
+	jw.run.started.Store(true)
+	defer close(jw.run.done)
 
-		// 将数据写入 es
-		msg := new(Msg)
-		err = json.Unmarshal(m.Value, msg)
-		if err != nil {
-			jw.logger.Error("json unmarshal error:", err)
-			continue
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-jw.run.stop:
+			cancel()
+		case <-fetchCtx.Done():
 		}
+	}()
+	msgs := make(chan kafka.Message)
+	fetchErr := make(chan error, 1)
+	go func() {
+		fetchErr <- jw.fetch(fetchCtx, msgs)
+		close(msgs)
+	}()
 
-		if msg.Type == "INSERT" {
-			// 往 ES 中新增文档
-			for idx := range msg.Data {
-				jw.indexDocument(msg.Data[idx])
-			}
-		} else {
-			// 其他操作，在 ES 里更新文档
-			for idx := range msg.Data {
-				jw.updateDocument(msg.Data[idx])
+	p := jw.newPool(ctx, jw.source.CommitMessages)
+	var err, ferr error
+	for err == nil {
+		select {
+		case m, ok := <-msgs:
+			if !ok {
+				// 停止拉取或 source 出错，写完在途的消息后退出
+				ferr = <-fetchErr
+				err = errStopped
+				break
 			}
+			fmt.Printf("message at offset %d: %s = %s\n", m.Offset, string(m.Key), string(m.Value))
+			err = p.dispatch(m)
+		case <-p.ctx.Done():
+			err = context.Cause(p.ctx)
+		}
+	}
+	cancel()
+	if err := p.close(); err != nil {
+		if ctx.Err() != nil {
+			return nil
 		}
+		// 重试耗尽，退出后由进程管理重启，未提交的消息会重新消费
+		return fmt.Errorf("flush batch failed: %w", err)
+	}
+	if ferr != nil && ctx.Err() == nil {
+		// 与写入失败一样退出，由进程管理重启后重新连接
+		return fmt.Errorf("fetch message failed: %w", ferr)
 	}
 	return nil
 }
 
-// 退出方法
-func (jw JobWorker) Stop(ctx context.Context) error {
-	jw.logger.Debugf("job worker stopping")
-	// 关闭 kafka 连接
-	if err := jw.kafkaReader.Close(); err != nil {
-		jw.logger.Error("failed to close reader:", err)
+// fetch 持续拉取消息直到 ctx 取消，source 关闭时返回 nil，其余错误原样返回
+func (jw JobWorker) fetch(ctx context.Context, msgs chan<- kafka.Message) error {
+	for {
+		m, err := jw.source.FetchMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, io.EOF) {
+				return nil
+			}
+			jw.logger.Error("fetch message error:", err)
+			return err
+		}
+		select {
+		case msgs <- m:
+		case <-ctx.Done():
+			return nil
+		}
 	}
-	return nil
 }
 
-// indexDocument 索引文档
-func (jw JobWorker) indexDocument(d map[string]interface{}) {
-	reviewID := d["review_id"].(string)
-
-	// 添加文档
-	resp, err := jw.esClient.Client.Index(jw.esClient.index).
-		Id(reviewID).
-		Document(d).
-		Do(context.Background())
+// decode 按 topic 的格式解析消息，无法解析时返回需要写入死信队列的 Letter；不需要处理的消息两者都为 nil
+func (jw JobWorker) decode(m kafka.Message) (*Msg, *Letter) {
+	msg, err := jw.decoders.For(m.Topic).Decode(m)
 	if err != nil {
-		jw.logger.Debugf("indexing document failed, err:%v\n", err)
-		return
+		jw.logger.Errorf("decode message at %s/%d/%d failed, err:%v", m.Topic, m.Partition, m.Offset, err)
+		return nil, &Letter{Msg: m, Reason: ReasonUnmarshal, Err: err}
 	}
-	fmt.Printf("result:%#v\n", resp.Result)
+	return msg, nil
 }
 
-// updateDocument 更新文档
-func (jw JobWorker) updateDocument(d map[string]interface{}) {
-	reviewID := d["review_id"].(string)
+// convert 将一条消息按表路由为写入操作，无法处理的消息返回需要写入死信队列的 Letter
+// 部分行无法转换时其余行照常写入，整条消息进入死信队列，重放时已写入的行因版本号相同不受影响
+func (jw JobWorker) convert(ctx context.Context, m kafka.Message, msg *Msg) ([]Write, *Letter) {
+	jw.addBloom(ctx, msg)
+	writes, err := jw.router.Route(ctx, msg)
+	if err != nil {
+		jw.logger.Errorf("convert message at %s failed, err:%v", msg.Position, err)
+		return writes, &Letter{Msg: m, Reason: ReasonConvert, Err: err}
+	}
+	return writes, nil
+}
 
-	resp, err := jw.esClient.Client.Update(jw.esClient.index, reviewID).
-		Doc(d). // 使用结构体变量更新
-		Do(context.Background())
+// Handle 将一条 canal 消息立即同步到索引，不经过攒批和死信队列，返回处理过程中遇到的第一个错误
+func (jw JobWorker) Handle(ctx context.Context, msg *Msg) error {
+	writes, err := jw.router.Route(ctx, msg)
+	jw.addBloom(ctx, msg)
+	rejects, werr := jw.write(ctx, writes)
 	if err != nil {
-		jw.logger.Errorf("update document failed, err:%v\n", err)
+		return err
+	}
+	if werr != nil {
+		return werr
+	}
+	if len(rejects) > 0 {
+		return rejects[0].err
+	}
+	return nil
+}
+
+// addBloom 维护布隆过滤器，记录已存在的 review_id 和 store_id
+func (jw JobWorker) addBloom(ctx context.Context, msg *Msg) {
+	if logicalTable(msg.Table) != "review_info" || msg.Type == "DELETE" {
 		return
 	}
-	fmt.Printf("result:%v\n", resp.Result)
+	for idx := range msg.Data {
+		if err := jw.bloom.AddReview(ctx, msg.Data[idx]); err != nil {
+			jw.logger.Errorf("add bloom filter failed, err:%v", err)
+		}
+	}
+}
+
+// 退出方法：停止拉取，等待 worker 写完在途的消息并提交 offset，超过 ctx 的期限后直接关闭
+func (jw JobWorker) Stop(ctx context.Context) error {
+	jw.logger.Debugf("job worker stopping")
+	jw.run.stopOnce.Do(func() { close(jw.run.stop) })
+	if jw.run.started.Load() {
+		select {
+		case <-jw.run.done:
+		case <-ctx.Done():
+			jw.logger.Warn("stop timeout, in-flight messages will be consumed again after restart")
+		}
+	}
+	// 关闭 kafka 或 canal 连接
+	if err := jw.source.Close(); err != nil {
+		jw.logger.Error("failed to close source:", err)
+	}
+	if err := jw.bloom.Close(); err != nil {
+		jw.logger.Error("failed to close bloom filter:", err)
+	}
+	return nil
 }
@@ -0,0 +1,134 @@
+package job
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"review-service/pkg/search"
+)
+
+// 索引名，表的处理规则通过名称指定写入哪个索引
+const (
+	IndexReview = "review" // 评价，回复合并在评价文档中
+	IndexAppeal = "appeal" // 申诉，供运营端检索
+)
+
+// Indices 按名称区分的搜索索引
+type Indices map[string]search.Index
+
+// Write 一行变更转换出的写入操作，Index 为写入的索引名
+type Write struct {
+	Index string
+	search.Op
+}
+
+// TableHandler 将一张表的一行变更转换为写入操作，返回错误时这一行被跳过，整条消息写入死信队列
+type TableHandler func(msg *Msg, row map[string]interface{}) ([]Write, error)
+
+// Router 按表名将 binlog 变更交给对应的 TableHandler，没有注册处理规则的表被忽略并计数
+// 分表 review_info_03 等按逻辑表名 review_info 查找处理规则，统计也按逻辑表名汇总
+// DDL 消息不作为数据处理，交给 SchemaGuard 告警并重新检查表结构
+type Router struct {
+	handlers map[string]TableHandler
+	schema   *SchemaGuard
+	log      *log.Helper
+
+	mu      sync.Mutex
+	routed  map[string]int64
+	ignored map[string]int64
+	ddl     map[string]int64
+}
+
+// NewRouter 创建 Router 并注册评价、回复、申诉三张表的处理规则，行数据由 tf 转换为强类型的文档
+func NewRouter(tf *Transformer, schema *SchemaGuard, logger log.Logger) *Router {
+	r := &Router{
+		handlers: map[string]TableHandler{},
+		schema:   schema,
+		log:      log.NewHelper(logger),
+		routed:   map[string]int64{},
+		ignored:  map[string]int64{},
+		ddl:      map[string]int64{},
+	}
+	r.Register("review_info", tf.reviewWrites)
+	r.Register("review_reply_info", tf.replyWrites)
+	r.Register("review_appeal_info", tf.appealWrites)
+	return r
+}
+
+// Register 注册一张表的处理规则，同名的表会被覆盖；需要在 JobWorker 启动前调用
+func (r *Router) Register(table string, h TableHandler) {
+	r.handlers[table] = h
+}
+
+// Route 将消息中的每一行转换为写入操作，无法转换的行被跳过，返回遇到的第一个错误
+func (r *Router) Route(ctx context.Context, msg *Msg) ([]Write, error) {
+	table := logicalTable(msg.Table)
+	if msg.IsDdl {
+		r.mu.Lock()
+		r.ddl[table]++
+		r.mu.Unlock()
+		r.schema.DDL(ctx, msg)
+		return nil, nil
+	}
+	h, ok := r.handlers[table]
+	if !ok {
+		r.mu.Lock()
+		if _, seen := r.ignored[msg.Table]; !seen {
+			r.log.Warnf("no handler for table %s.%s, its changes are ignored", msg.Database, msg.Table)
+		}
+		r.ignored[msg.Table] += int64(len(msg.Data))
+		r.mu.Unlock()
+		return nil, nil
+	}
+	r.schema.Check(ctx, msg)
+	var firstErr error
+	writes := make([]Write, 0, len(msg.Data))
+	for _, row := range msg.Data {
+		w, err := h(msg, row)
+		if err == nil {
+			err = r.schema.Extend(msg, row, w)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		writes = append(writes, w...)
+	}
+	r.mu.Lock()
+	r.routed[table] += int64(len(msg.Data))
+	r.mu.Unlock()
+	return writes, firstErr
+}
+
+// RouteStats 本进程启动以来按表统计的行数和 DDL 次数，Drifted 为结构与文档不一致的表
+type RouteStats struct {
+	Routed  map[string]int64
+	Ignored map[string]int64
+	DDL     map[string]int64
+	Drifted []string
+}
+
+// Stats 各表已处理和被忽略的行数
+func (r *Router) Stats() RouteStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := RouteStats{
+		Routed:  make(map[string]int64, len(r.routed)),
+		Ignored: make(map[string]int64, len(r.ignored)),
+		DDL:     make(map[string]int64, len(r.ddl)),
+		Drifted: r.schema.Drifted(),
+	}
+	for table, n := range r.routed {
+		stats.Routed[table] = n
+	}
+	for table, n := range r.ignored {
+		stats.Ignored[table] = n
+	}
+	for table, n := range r.ddl {
+		stats.DDL[table] = n
+	}
+	return stats
+}
@@ -0,0 +1,78 @@
+package job_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"review-job/internal/conf"
+	"review-job/internal/job"
+	"review-job/internal/job/jobtest"
+	"review-service/pkg/search"
+)
+
+// fieldRecorder 记录 SchemaGuard 加入 mapping 的字段
+type fieldRecorder map[string]search.FieldType
+
+func (r fieldRecorder) PutFields(_ context.Context, index string, fields search.Mapping) error {
+	for name, typ := range fields {
+		r[index+"."+name] = typ
+	}
+	return nil
+}
+
+func newRouter(t *testing.T, fields fieldRecorder) *job.Router {
+	t.Helper()
+	tf, err := job.NewTransformer(&conf.Data{Database: &conf.Data_Database{TimeZone: "Asia/Shanghai"}})
+	if err != nil {
+		t.Fatalf("new transformer: %v", err)
+	}
+	return job.NewRouter(tf, job.NewSchemaGuard(tf, fields, &job.Alerter{}, log.DefaultLogger), log.DefaultLogger)
+}
+
+// 分表的消息按逻辑表名路由，与未分表时产生相同的写入
+func TestRouterShardTables(t *testing.T) {
+	ctx := context.Background()
+	fields := fieldRecorder{}
+	router := newRouter(t, fields)
+	plain := newRouter(t, fieldRecorder{})
+	for _, name := range []string{"review_insert.json", "reply_insert.json", "appeal_insert.json", "review_ddl.json", "review_ip_update.json"} {
+		want, err := plain.Route(ctx, jobtest.Fixture(t, name))
+		if err != nil {
+			t.Fatalf("route %s: %v", name, err)
+		}
+		msg := jobtest.Fixture(t, name)
+		msg.Table += "_03"
+		writes, err := router.Route(ctx, msg)
+		if err != nil {
+			t.Fatalf("route %s as %s: %v", name, msg.Table, err)
+		}
+		if !msg.IsDdl && len(writes) == 0 {
+			t.Fatalf("%s routed to no writes", msg.Table)
+		}
+		if name != "review_ip_update.json" && !reflect.DeepEqual(writes, want) {
+			t.Fatalf("writes of %s = %+v, want %+v", msg.Table, writes, want)
+		}
+	}
+
+	stats := router.Stats()
+	if len(stats.Ignored) != 0 {
+		t.Fatalf("ignored = %v, want none", stats.Ignored)
+	}
+	for _, table := range []string{"review_info", "review_reply_info", "review_appeal_info"} {
+		if stats.Routed[table] == 0 {
+			t.Fatalf("routed = %v, want rows of %s", stats.Routed, table)
+		}
+	}
+	if stats.DDL["review_info"] != 1 {
+		t.Fatalf("ddl = %v, want 1 on review_info", stats.DDL)
+	}
+	// 表结构按物理表检查，新增的列加入评价索引的 mapping
+	if !reflect.DeepEqual(stats.Drifted, []string{"review_info_03"}) {
+		t.Fatalf("drifted = %v, want review_info_03", stats.Drifted)
+	}
+	if fields[job.IndexReview+".ip_location"] != search.Keyword {
+		t.Fatalf("mapping updates = %v, want ip_location", fields)
+	}
+}
@@ -0,0 +1,336 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"review-job/internal/conf"
+	"review-service/pkg/search"
+)
+
+// MySQL 列类型的大类，决定与文档字段是否兼容以及新增列在索引中的类型
+const (
+	kindInt    = "int"
+	kindFloat  = "float"
+	kindTime   = "time"
+	kindText   = "text"
+	kindString = "string"
+)
+
+// FieldUpdater 向索引的 mapping 中追加字段，auto_mapping 使用
+type FieldUpdater interface {
+	PutFields(ctx context.Context, index string, fields search.Mapping) error
+}
+
+// NewFieldUpdater 开启 auto_mapping 且使用 ES 时返回按索引名更新 mapping 的 FieldUpdater，否则返回 nil，新增的列只告警
+func NewFieldUpdater(cfgES *conf.Elasticsearch, cfg *conf.Search, logger log.Logger) (FieldUpdater, error) {
+	if !cfgES.GetAutoMapping() {
+		return nil, nil
+	}
+	if cfg.GetBackend() == "bleve" {
+		log.NewHelper(logger).Warn("auto_mapping is not supported by bleve, new columns are only alerted")
+		return nil, nil
+	}
+	client, err := NewESClient(cfgES)
+	if err != nil {
+		return nil, err
+	}
+	return indexManagers(NewIndexManagers(client, cfgES, logger)), nil
+}
+
+type indexManagers map[string]*IndexManager
+
+func (m indexManagers) PutFields(ctx context.Context, index string, fields search.Mapping) error {
+	manager, ok := m[index]
+	if !ok {
+		return fmt.Errorf("unknown index %q", index)
+	}
+	return manager.PutFields(ctx, fields)
+}
+
+// tableSchema 一张表对应的文档结构
+type tableSchema struct {
+	index  string
+	fields map[string]reflect.Type // 列名到文档字段类型
+	// partial 文档只使用表中的部分列，表中新增的列不算不一致
+	partial bool
+}
+
+// SchemaGuard 检查表结构与文档结构是否一致
+// 分表按逻辑表名查找登记的文档结构，每张物理表各自检查；
+// 启动后每张表的第一条 DML 消息，以及每次 DDL 之后的第一条 DML 消息，按消息中的 mysqlType 与文档结构比较，
+// 不一致时记录日志并写入告警事件；配置了 FieldUpdater 时将表中新增的列加入索引的 mapping，之后写入的文档带上这些列
+type SchemaGuard struct {
+	tables  map[string]*tableSchema
+	tf      *Transformer
+	updater FieldUpdater
+	alerter *Alerter
+	log     *log.Helper
+
+	mu      sync.Mutex
+	checked map[string]bool
+	drifted map[string]bool
+	extra   map[string]map[string]string // 已加入 mapping 的新增列及其 MySQL 类型
+}
+
+// NewSchemaGuard 创建 SchemaGuard 并登记评价、回复、申诉三张表的文档结构
+func NewSchemaGuard(tf *Transformer, updater FieldUpdater, alerter *Alerter, logger log.Logger) *SchemaGuard {
+	g := &SchemaGuard{
+		tables:  map[string]*tableSchema{},
+		tf:      tf,
+		updater: updater,
+		alerter: alerter,
+		log:     log.NewHelper(logger),
+		checked: map[string]bool{},
+		drifted: map[string]bool{},
+		extra:   map[string]map[string]string{},
+	}
+	g.Watch("review_info", IndexReview, search.ReviewDoc{}, false, "reply_id", "reply_content", "reply_at")
+	g.Watch("review_reply_info", IndexReview, replyRow{}, true)
+	g.Watch("review_appeal_info", IndexAppeal, search.AppealDoc{}, false)
+	return g
+}
+
+// Watch 登记一张表的文档结构，doc 为 Transformer 解码的结构体，exclude 为文档中不来自这张表的字段
+func (g *SchemaGuard) Watch(table, index string, doc interface{}, partial bool, exclude ...string) {
+	fields := map[string]reflect.Type{}
+	t := reflect.TypeOf(doc)
+	for i := 0; i < t.NumField(); i++ {
+		column := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if column == "" || column == "-" {
+			continue
+		}
+		fields[column] = t.Field(i).Type
+	}
+	for _, column := range exclude {
+		delete(fields, column)
+	}
+	g.tables[table] = &tableSchema{index: index, fields: fields, partial: partial}
+}
+
+// DDL 登记过的表执行了 DDL：发出告警，下一条 DML 消息重新检查表结构
+func (g *SchemaGuard) DDL(ctx context.Context, msg *Msg) {
+	if _, ok := g.tables[logicalTable(msg.Table)]; !ok {
+		return
+	}
+	g.mu.Lock()
+	delete(g.checked, msg.Table)
+	g.mu.Unlock()
+	g.log.Warnf("%s on %s.%s: %s", msg.Type, msg.Database, msg.Table, msg.SQL)
+	g.emit(ctx, &Alert{Type: AlertDDL, Database: msg.Database, Table: msg.Table, SQL: msg.SQL, At: time.Now()})
+}
+
+// Check 按 DML 消息中的 mysqlType 检查表结构，每张表只在启动后和 DDL 之后检查一次
+func (g *SchemaGuard) Check(ctx context.Context, msg *Msg) {
+	s, ok := g.tables[logicalTable(msg.Table)]
+	if !ok || len(msg.MysqlType) == 0 {
+		return
+	}
+	g.mu.Lock()
+	if g.checked[msg.Table] {
+		g.mu.Unlock()
+		return
+	}
+	g.checked[msg.Table] = true
+	delete(g.extra, msg.Table)
+	g.mu.Unlock()
+
+	alert := diffSchema(msg, s)
+	g.mu.Lock()
+	g.drifted[msg.Table] = alert != nil
+	g.mu.Unlock()
+	if alert == nil {
+		return
+	}
+	if len(alert.Added) > 0 && !s.partial && g.updater != nil {
+		g.apply(ctx, msg.Table, s, alert)
+	}
+	g.log.Warnf("schema of %s.%s drifted from the document, added:%v, missing:%v, mismatched:%v, applied:%v",
+		msg.Database, msg.Table, alert.Added, alert.Missing, alert.Mismatched, alert.Applied)
+	g.emit(ctx, alert)
+}
+
+// apply 将新增的列加入索引的 mapping，成功后写入的文档带上这些列
+func (g *SchemaGuard) apply(ctx context.Context, table string, s *tableSchema, alert *Alert) {
+	fields := search.Mapping{}
+	extra := map[string]string{}
+	for _, c := range alert.Added {
+		fields[c.Name] = fieldType(c.MysqlType)
+		extra[c.Name] = c.MysqlType
+	}
+	if err := g.updater.PutFields(ctx, s.index, fields); err != nil {
+		g.log.Errorf("add columns of %s to index %s failed, err:%v", table, s.index, err)
+		return
+	}
+	g.mu.Lock()
+	g.extra[table] = extra
+	g.mu.Unlock()
+	for _, c := range alert.Added {
+		alert.Applied = append(alert.Applied, c.Name)
+	}
+}
+
+// Extend 将已加入 mapping 的新增列写入这一行产生的整篇写入和 upsert
+func (g *SchemaGuard) Extend(msg *Msg, row map[string]interface{}, writes []Write) error {
+	g.mu.Lock()
+	extra := g.extra[msg.Table]
+	g.mu.Unlock()
+	if len(extra) == 0 {
+		return nil
+	}
+	index := g.tables[logicalTable(msg.Table)].index
+	for _, w := range writes {
+		if w.Index != index || w.Doc == nil || (w.Action != search.ActionIndex && w.Action != search.ActionUpsert) {
+			continue
+		}
+		for column, mysqlType := range extra {
+			v, ok := row[column]
+			if !ok {
+				continue
+			}
+			if v == nil {
+				w.Doc[column] = nil
+				continue
+			}
+			value, err := g.tf.Value(mysqlType, fmt.Sprint(v))
+			if err != nil {
+				return fmt.Errorf("column %s: %w", column, err)
+			}
+			w.Doc[column] = value
+		}
+	}
+	return nil
+}
+
+// Drifted 最近一次检查时结构不一致的表
+func (g *SchemaGuard) Drifted() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	tables := make([]string, 0, len(g.drifted))
+	for table, drifted := range g.drifted {
+		if drifted {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+func (g *SchemaGuard) emit(ctx context.Context, alert *Alert) {
+	if err := g.alerter.Emit(ctx, alert); err != nil {
+		g.log.Errorf("emit alert failed, err:%v", err)
+	}
+}
+
+// diffSchema 比较表结构与文档结构，一致时返回 nil
+func diffSchema(msg *Msg, s *tableSchema) *Alert {
+	alert := &Alert{Type: AlertSchemaDrift, Database: msg.Database, Table: msg.Table, At: time.Now()}
+	for column, mysqlType := range msg.MysqlType {
+		field, ok := s.fields[column]
+		switch {
+		case !ok && !s.partial:
+			alert.Added = append(alert.Added, Column{Name: column, MysqlType: mysqlType})
+		case ok && !compatible(field, mysqlKind(mysqlType)):
+			alert.Mismatched = append(alert.Mismatched, Column{Name: column, MysqlType: mysqlType, Field: field.String()})
+		}
+	}
+	for column := range s.fields {
+		if _, ok := msg.MysqlType[column]; !ok {
+			alert.Missing = append(alert.Missing, column)
+		}
+	}
+	if len(alert.Added)+len(alert.Missing)+len(alert.Mismatched) == 0 {
+		return nil
+	}
+	sort.Slice(alert.Added, func(i, j int) bool { return alert.Added[i].Name < alert.Added[j].Name })
+	sort.Slice(alert.Mismatched, func(i, j int) bool { return alert.Mismatched[i].Name < alert.Mismatched[j].Name })
+	sort.Strings(alert.Missing)
+	return alert
+}
+
+// mysqlKind MySQL 列类型的大类，如 bigint(20) unsigned 为 kindInt
+func mysqlKind(mysqlType string) string {
+	t := strings.ToLower(mysqlType)
+	if i := strings.IndexAny(t, "( "); i >= 0 {
+		t = t[:i]
+	}
+	switch t {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "year", "bit":
+		return kindInt
+	case "float", "double", "decimal", "numeric", "real":
+		return kindFloat
+	case "date", "datetime", "timestamp":
+		return kindTime
+	case "text", "tinytext", "mediumtext", "longtext":
+		return kindText
+	}
+	return kindString
+}
+
+// compatible 列的值能否由 Transformer 转换为文档字段的类型
+func compatible(field reflect.Type, kind string) bool {
+	switch field {
+	case timeType, timePtrType:
+		return kind == kindTime
+	case rawType:
+		return kind == kindString || kind == kindText
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return kind == kindString || kind == kindText
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Bool:
+		return kind == kindInt
+	}
+	return false
+}
+
+// fieldType 新增的列在索引中的类型
+func fieldType(mysqlType string) search.FieldType {
+	switch mysqlKind(mysqlType) {
+	case kindInt:
+		return search.Long
+	case kindFloat:
+		return search.Numeric
+	case kindTime:
+		return search.Date
+	case kindText:
+		return search.Text
+	}
+	return search.Keyword
+}
+
+// Value 按 MySQL 类型转换一列的值，用于文档结构中没有的新增列：数值为 json.Number，时间为 RFC3339
+func (tf *Transformer) Value(mysqlType, s string) (interface{}, error) {
+	switch mysqlKind(mysqlType) {
+	case kindInt, kindFloat:
+		if s == "" {
+			return nil, nil
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return nil, err
+		}
+		return json.Number(s), nil
+	case kindTime:
+		if s == "" {
+			return nil, nil
+		}
+		layout := mysqlDateTime
+		if len(s) == len(time.DateOnly) {
+			layout = time.DateOnly
+		}
+		t, err := time.ParseInLocation(layout, s, tf.loc)
+		if err != nil {
+			return nil, err
+		}
+		return t.Format(time.RFC3339Nano), nil
+	}
+	return s, nil
+}
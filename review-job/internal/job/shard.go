@@ -0,0 +1,70 @@
+package job
+
+import (
+	"fmt"
+	"strings"
+
+	"review-job/internal/conf"
+)
+
+// ShardTable 一张逻辑表的一张物理分表及其所在的数据库
+type ShardTable struct {
+	Database *conf.Data_Database
+	Table    string
+}
+
+// shardTables 按 data.sharding 列出逻辑表的全部物理表，与 review-service 的分片规则一致：
+// 第 i 张分表为 {logical}_{i:02d}，位于 databases[i % len(databases)]，未配置分库时位于 database；未开启分表时只有逻辑表本身
+func shardTables(dc *conf.Data, logical string) []ShardTable {
+	sharding := dc.GetSharding()
+	if !sharding.GetEnabled() || sharding.GetTableCount() <= 0 {
+		return []ShardTable{{Database: dc.GetDatabase(), Table: logical}}
+	}
+	databases := []*conf.Data_Database{dc.GetDatabase()}
+	if dsns := sharding.GetDatabases(); len(dsns) > 0 {
+		databases = databases[:0]
+		for _, dsn := range dsns {
+			databases = append(databases, &conf.Data_Database{
+				Driver:   dc.GetDatabase().GetDriver(),
+				Source:   dsn,
+				TimeZone: dc.GetDatabase().GetTimeZone(),
+			})
+		}
+	}
+	tables := make([]ShardTable, 0, sharding.GetTableCount())
+	for i := 0; i < int(sharding.GetTableCount()); i++ {
+		tables = append(tables, ShardTable{
+			Database: databases[i%len(databases)],
+			Table:    fmt.Sprintf("%s_%02d", logical, i),
+		})
+	}
+	return tables
+}
+
+// logicalTable 分表的物理表名去掉 _{i:02d} 后缀得到逻辑表名，如 review_info_03 为 review_info；不是分表时原样返回
+func logicalTable(table string) string {
+	i := strings.LastIndexByte(table, '_')
+	if i < 0 || len(table)-i-1 < 2 {
+		return table
+	}
+	for _, c := range table[i+1:] {
+		if c < '0' || c > '9' {
+			return table
+		}
+	}
+	return table[:i]
+}
+
+// shardIndex store_id 所在的分表编号
+func shardIndex(storeID int64, tables int) int {
+	idx := storeID % int64(tables)
+	if idx < 0 {
+		idx = -idx
+	}
+	return int(idx)
+}
+
+// ReviewShardTables 评价表 review_info 的全部物理表
+func ReviewShardTables(dc *conf.Data) []ShardTable {
+	return shardTables(dc, "review_info")
+}
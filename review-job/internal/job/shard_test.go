@@ -0,0 +1,56 @@
+package job
+
+import (
+	"reflect"
+	"testing"
+
+	"review-job/internal/conf"
+)
+
+func TestShardTables(t *testing.T) {
+	main := &conf.Data_Database{Driver: "mysql", Source: "main", TimeZone: "Asia/Shanghai"}
+	cases := []struct {
+		name     string
+		sharding *conf.Data_Sharding
+		tables   []string
+		sources  []string
+	}{
+		{name: "not sharded", tables: []string{"review_info"}, sources: []string{"main"}},
+		{name: "disabled", sharding: &conf.Data_Sharding{TableCount: 2}, tables: []string{"review_info"}, sources: []string{"main"}},
+		{name: "tables in main database", sharding: &conf.Data_Sharding{Enabled: true, TableCount: 3},
+			tables: []string{"review_info_00", "review_info_01", "review_info_02"}, sources: []string{"main", "main", "main"}},
+		{name: "tables across databases", sharding: &conf.Data_Sharding{Enabled: true, TableCount: 3, Databases: []string{"db0", "db1"}},
+			tables: []string{"review_info_00", "review_info_01", "review_info_02"}, sources: []string{"db0", "db1", "db0"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var tables, sources []string
+			for _, s := range shardTables(&conf.Data{Database: main, Sharding: c.sharding}, "review_info") {
+				tables = append(tables, s.Table)
+				sources = append(sources, s.Database.GetSource())
+				if s.Database.GetTimeZone() != main.TimeZone {
+					t.Fatalf("time zone of %s = %q, want %q", s.Table, s.Database.GetTimeZone(), main.TimeZone)
+				}
+			}
+			if !reflect.DeepEqual(tables, c.tables) || !reflect.DeepEqual(sources, c.sources) {
+				t.Fatalf("tables = %v in %v, want %v in %v", tables, sources, c.tables, c.sources)
+			}
+		})
+	}
+}
+
+func TestLogicalTable(t *testing.T) {
+	for table, want := range map[string]string{
+		"review_info":           "review_info",
+		"review_info_03":        "review_info",
+		"review_reply_info_12":  "review_reply_info",
+		"review_appeal_info_00": "review_appeal_info",
+		"store_stat":            "store_stat",
+		"review_info_x1":        "review_info_x1",
+		"review_info_1":         "review_info_1",
+	} {
+		if got := logicalTable(table); got != want {
+			t.Errorf("logicalTable(%s) = %s, want %s", table, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/segmentio/kafka-go"
+	"review-job/internal/conf"
+)
+
+// 变更数据来源的类型
+const (
+	SourceKafka = "kafka"
+	SourceCanal = "canal"
+)
+
+// ChangeSource 变更数据的来源，每条消息的 Value 为 canal-json 格式
+// CommitMessages 传入的是各分区连续处理完的最后一条消息，来源据此确认之前的消息都已写入，不再重新投递；
+// 没有确认的消息在重启后重新投递，保证每条消息至少被处理一次
+type ChangeSource interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// NewChangeSource 按配置创建变更数据的来源，默认从 kafka 读取
+func NewChangeSource(cfg *conf.Source, kc *conf.Kafka, logger log.Logger) (ChangeSource, error) {
+	switch cfg.GetType() {
+	case "", SourceKafka:
+		return NewKafkaReader(kc)
+	case SourceCanal:
+		s, err := NewCanalSource(cfg.GetCanal(), logger)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown source type %q", cfg.GetType())
+	}
+}
@@ -0,0 +1,116 @@
+package job
+
+import (
+	"fmt"
+	"time"
+
+	"review-service/pkg/search"
+)
+
+// replyVersionField 回复合并到评价文档时使用的版本号字段
+// 评价和回复的 binlog 之间没有先后顺序的保证，各自使用自己的版本号，互不覆盖
+const replyVersionField = "__reply_version"
+
+// reviewWrites review_info 的变更写入评价索引，以 review_id 作为文档 ID
+func (tf *Transformer) reviewWrites(msg *Msg, row map[string]interface{}) ([]Write, error) {
+	reviewID, err := rowID(row, "review_id")
+	if err != nil {
+		return nil, err
+	}
+	if msg.Type == "DELETE" || softDeleted(row) {
+		// 物理删除和逻辑删除都从索引中移除
+		return []Write{{Index: IndexReview, Op: search.Op{Action: search.ActionDelete, ID: reviewID, Version: msg.Es}}}, nil
+	}
+	review := new(search.ReviewDoc)
+	if err := tf.Decode(row, review); err != nil {
+		return nil, fmt.Errorf("review %s: %w", reviewID, err)
+	}
+	// canal 的 UPDATE 消息带有整行数据，合并写入：评价的字段整体更新，保留从回复表合并进来的字段；
+	// 撤销逻辑删除时文档会重新出现；审核不通过、隐藏的评价保留在索引中，由查询按 status 过滤
+	doc, err := review.Document()
+	if err != nil {
+		return nil, err
+	}
+	return []Write{{Index: IndexReview, Op: search.Op{Action: search.ActionUpsert, ID: reviewID, Doc: doc, Version: msg.Es}}}, nil
+}
+
+// replyRow review_reply_info 中合并到评价文档的列
+type replyRow struct {
+	ReplyID  int64     `json:"reply_id"`
+	Content  string    `json:"content"`
+	CreateAt time.Time `json:"create_at"`
+}
+
+// replyDoc 评价文档中的回复字段
+type replyDoc struct {
+	HasReply     bool      `json:"has_reply"`
+	ReplyID      int64     `json:"reply_id"`
+	ReplyContent string    `json:"reply_content"`
+	ReplyAt      time.Time `json:"reply_at"`
+}
+
+// replyWrites review_reply_info 的变更合并到所属评价的文档中，回复被删除时清空回复字段
+// 评价还没有写入索引时返回 search.ErrNotFound，消息进入死信队列，评价写入后重放即可
+func (tf *Transformer) replyWrites(msg *Msg, row map[string]interface{}) ([]Write, error) {
+	reviewID, err := rowID(row, "review_id")
+	if err != nil {
+		return nil, err
+	}
+	doc := search.Document{"has_reply": false, "reply_id": nil, "reply_content": nil, "reply_at": nil}
+	if msg.Type != "DELETE" && !softDeleted(row) {
+		reply := new(replyRow)
+		if err := tf.Decode(row, reply); err != nil {
+			return nil, fmt.Errorf("reply of review %s: %w", reviewID, err)
+		}
+		doc, err = search.NewDocument(&replyDoc{HasReply: true, ReplyID: reply.ReplyID, ReplyContent: reply.Content, ReplyAt: reply.CreateAt})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return []Write{{Index: IndexReview, Op: search.Op{
+		Action:       search.ActionUpdate,
+		ID:           reviewID,
+		Doc:          doc,
+		Version:      msg.Es,
+		VersionField: replyVersionField,
+	}}}, nil
+}
+
+// appealWrites review_appeal_info 的变更写入申诉索引，以 appeal_id 作为文档 ID
+func (tf *Transformer) appealWrites(msg *Msg, row map[string]interface{}) ([]Write, error) {
+	appealID, err := rowID(row, "appeal_id")
+	if err != nil {
+		return nil, err
+	}
+	if msg.Type == "DELETE" || softDeleted(row) {
+		return []Write{{Index: IndexAppeal, Op: search.Op{Action: search.ActionDelete, ID: appealID, Version: msg.Es}}}, nil
+	}
+	appeal := new(search.AppealDoc)
+	if err := tf.Decode(row, appeal); err != nil {
+		return nil, fmt.Errorf("appeal %s: %w", appealID, err)
+	}
+	doc, err := search.NewDocument(appeal)
+	if err != nil {
+		return nil, err
+	}
+	return []Write{{Index: IndexAppeal, Op: search.Op{Action: search.ActionIndex, ID: appealID, Doc: doc, Version: msg.Es}}}, nil
+}
+
+// softDeleted delete_at 不为空说明这一行已被逻辑删除
+func softDeleted(d map[string]interface{}) bool {
+	v, ok := d["delete_at"]
+	if !ok || v == nil {
+		return false
+	}
+	s, ok := v.(string)
+	return !ok || s != ""
+}
+
+// rowID 以 key 列的值作为文档 ID
+func rowID(d map[string]interface{}, key string) (string, error) {
+	id, ok := d[key].(string)
+	if !ok || id == "" {
+		return "", fmt.Errorf("row has no %s", key)
+	}
+	return id, nil
+}
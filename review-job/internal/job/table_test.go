@@ -0,0 +1,71 @@
+package job_test
+
+import (
+	"context"
+	"testing"
+
+	"review-job/internal/job"
+	"review-job/internal/job/jobtest"
+	"review-service/pkg/search"
+)
+
+// 回放 fixtures 并检查 bleve 索引中的文档
+func TestCanalFixtures(t *testing.T) {
+	jobtest.Run(t, jobtest.NewBleveIndex)
+}
+
+// 各张表的变更转换出的写入操作，删除和逻辑删除从索引中移除文档，回复被删除时清空评价文档中的回复字段
+func TestTableWrites(t *testing.T) {
+	const (
+		reviewID = "7198357715427348481"
+		appealID = "7198358470863437825"
+	)
+	cases := []struct {
+		fixture string
+		index   string
+		action  search.Action
+		id      string
+		// fields 不为空时检查写入的文档中这些字段的值，nil 表示字段被清空
+		fields map[string]interface{}
+	}{
+		{fixture: "review_insert.json", index: job.IndexReview, action: search.ActionUpsert, id: reviewID},
+		{fixture: "review_update.json", index: job.IndexReview, action: search.ActionUpsert, id: reviewID},
+		{fixture: "review_hidden.json", index: job.IndexReview, action: search.ActionUpsert, id: reviewID},
+		{fixture: "review_restore.json", index: job.IndexReview, action: search.ActionUpsert, id: reviewID},
+		{fixture: "review_soft_delete.json", index: job.IndexReview, action: search.ActionDelete, id: reviewID},
+		{fixture: "review_delete.json", index: job.IndexReview, action: search.ActionDelete, id: reviewID},
+		{fixture: "debezium/review_soft_delete.json", index: job.IndexReview, action: search.ActionDelete, id: reviewID},
+		{fixture: "debezium/review_delete.json", index: job.IndexReview, action: search.ActionDelete, id: reviewID},
+		{fixture: "reply_insert.json", index: job.IndexReview, action: search.ActionUpdate, id: reviewID, fields: map[string]interface{}{"has_reply": true}},
+		{fixture: "reply_delete.json", index: job.IndexReview, action: search.ActionUpdate, id: reviewID,
+			fields: map[string]interface{}{"has_reply": false, "reply_id": nil, "reply_content": nil, "reply_at": nil}},
+		{fixture: "appeal_insert.json", index: job.IndexAppeal, action: search.ActionIndex, id: appealID},
+	}
+	for _, c := range cases {
+		t.Run(c.fixture, func(t *testing.T) {
+			msg := jobtest.Fixture(t, c.fixture)
+			writes, err := newRouter(t, fieldRecorder{}).Route(context.Background(), msg)
+			if err != nil {
+				t.Fatalf("route: %v", err)
+			}
+			if len(writes) != 1 {
+				t.Fatalf("writes = %+v, want 1", writes)
+			}
+			w := writes[0]
+			if w.Index != c.index || w.Action != c.action || w.ID != c.id {
+				t.Fatalf("write = %s %v %s, want %s %v %s", w.Index, w.Action, w.ID, c.index, c.action, c.id)
+			}
+			if w.Version != msg.Es {
+				t.Fatalf("version = %d, want binlog time %d", w.Version, msg.Es)
+			}
+			if c.action == search.ActionDelete && w.Doc != nil {
+				t.Fatalf("delete carries document %v", w.Doc)
+			}
+			for field, want := range c.fields {
+				if got, ok := w.Doc[field]; !ok || got != want {
+					t.Fatalf("%s = %v (set %v), want %v", field, got, ok, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"review-job/internal/conf"
+	"review-service/pkg/search"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf((*time.Time)(nil))
+	rawType     = reflect.TypeOf(json.RawMessage{})
+)
+
+// Transformer 将 canal 消息中的一行转换为强类型的文档结构体，如 search.ReviewDoc
+// canal flat message 中每一列都是字符串，按结构体字段的类型转换：整数、布尔（tinyint 的 0/1）、
+// 按数据库时区解析的时间（写入时为 RFC3339），json.RawMessage 字段按 search.ParseJSON 解析
+type Transformer struct {
+	loc *time.Location
+}
+
+func NewTransformer(cfg *conf.Data) (*Transformer, error) {
+	loc := time.Local
+	if tz := cfg.GetDatabase().GetTimeZone(); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("load time zone %q failed: %w", tz, err)
+		}
+		loc = l
+	}
+	return &Transformer{loc: loc}, nil
+}
+
+// Decode 按 dst 字段的 json 标签从 row 中取出对应的列并转换类型，row 中没有或为 null 的列保持零值
+func (tf *Transformer) Decode(row map[string]interface{}, dst interface{}) error {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		column := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if column == "" || column == "-" {
+			continue
+		}
+		value, ok := row[column]
+		if !ok || value == nil {
+			continue
+		}
+		s, ok := value.(string)
+		if !ok {
+			s = fmt.Sprint(value)
+		}
+		if err := tf.set(v.Field(i), s); err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// Legacy 将旧索引（canal 按动态 mapping 写入）中的文档转换为 dst 的强类型结构体
+// 旧文档中的列都是字符串，按 Decode 的规则转换；review-job 之后合并进来的字段（如回复）已经是强类型的，
+// 与字符串的列可能出现在同一篇文档中，直接解码
+func (tf *Transformer) Legacy(source json.RawMessage, dst interface{}) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(source, &fields); err != nil {
+		return err
+	}
+	row := map[string]interface{}{}
+	typed := map[string]json.RawMessage{}
+	for column, raw := range fields {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil && string(raw) != "null" {
+			row[column] = s
+			continue
+		}
+		typed[column] = raw
+	}
+	data, err := json.Marshal(typed)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return err
+	}
+	return tf.Decode(row, dst)
+}
+
+func (tf *Transformer) set(field reflect.Value, s string) error {
+	switch field.Type() {
+	case timeType, timePtrType:
+		if s == "" {
+			return nil
+		}
+		t, err := time.ParseInLocation(mysqlDateTime, s, tf.loc)
+		if err != nil {
+			// 旧索引中 review-job 写入的时间已经是 RFC3339
+			var e error
+			if t, e = time.Parse(time.RFC3339Nano, s); e != nil {
+				return err
+			}
+		}
+		if field.Type() == timePtrType {
+			field.Set(reflect.ValueOf(&t))
+			return nil
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case rawType:
+		field.Set(reflect.ValueOf(search.ParseJSON(s)))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		if s == "" {
+			return nil
+		}
+		// tinyint 的 0/1
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
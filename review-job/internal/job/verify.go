@@ -0,0 +1,432 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"review-job/internal/conf"
+	"review-service/pkg/search"
+)
+
+// 核对出的不一致的类型
+const (
+	MismatchMissing = "missing" // 行存在且未删除，索引中没有文档
+	MismatchStale   = "stale"   // 文档的 version、status 或 update_at 与行不一致
+	MismatchExtra   = "extra"   // 行已被物理或逻辑删除，索引中仍有文档
+)
+
+// 核对的状态
+const (
+	VerifyIdle    = "idle"
+	VerifyRunning = "running"
+	VerifyDone    = "done"
+	VerifyFailed  = "failed"
+)
+
+const (
+	defaultVerifyChunk = 500
+	// maxReportMismatches 报告中最多保留的不一致条数，计数不受影响
+	maxReportMismatches = 100
+)
+
+// ErrVerifyRunning 已有核对在进行
+var ErrVerifyRunning = errors.New("verify is already running")
+
+// VerifyOption 核对的参数
+type VerifyOption struct {
+	MinID, MaxID int64   // 主键范围，开启分表时用于每张分表，为 0 时取表中的最小、最大值
+	Sample       float64 // 范围内每个主键被抽中的概率，0 或 1 表示全量核对
+	Repair       bool    // 按 MySQL 中的数据修复不一致的文档
+	Chunk        int     // 每批核对的主键数，默认 500
+}
+
+// ReviewState 参与比对的字段
+type ReviewState struct {
+	Version  int32
+	Status   int32
+	UpdateAt time.Time
+	Deleted  bool
+}
+
+// Mismatch 一条不一致，Row 为 nil 表示行不存在，Doc 为 nil 表示文档不存在
+type Mismatch struct {
+	Kind     string
+	Table    string // 行所在的物理表，ID 只在这张表内唯一
+	ID       int64
+	ReviewID int64
+	Row      *ReviewState
+	Doc      *ReviewState
+	Repaired bool
+}
+
+// VerifyReport 一次核对的进度和结果
+type VerifyReport struct {
+	State  string
+	Option VerifyOption
+	// Checked 已核对的行数；Skipped 为 update_at 在 catchUpMargin 之内，binlog 可能还没有同步而跳过的行数
+	Checked, Skipped      int64
+	Missing, Stale, Extra int64
+	Repaired              int64
+	Mismatches            []Mismatch
+	Table                 string // 当前核对的物理表
+	Cursor                int64  // 当前核对到的主键
+	Err                   string
+	StartAt, FinishAt     time.Time
+}
+
+// Verifier 按主键范围依次比对 review_info 的每张分表与评价索引，报告缺失、过期和多余的文档，可以按 MySQL 中的数据修复
+// 文档以 review_id 为 ID，行与文档按 review_id 对应；主键只在一张分表内唯一，按主键找到的文档只用于发现行已被物理删除的文档。
+// 修复与 backfill 一样以数据库时间减去 catchUpMargin 作为版本号，核对期间 binlog 写入的更新的数据不会被覆盖
+type Verifier struct {
+	shards []ShardTable
+	tables int // 分表数量，未开启分表时为 0
+	tf     *Transformer
+	worker *JobWorker
+	log    *log.Helper
+
+	mu     sync.Mutex
+	dbs    map[string]*sql.DB // 按 DSN 复用连接
+	report *VerifyReport
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewVerifier 创建 Verifier，第一次核对时才连接数据库，未配置数据库不影响 review-job 启动
+func NewVerifier(cfg *conf.Data, tf *Transformer, worker *JobWorker, logger log.Logger) (*Verifier, func(), error) {
+	v := &Verifier{
+		shards: ReviewShardTables(cfg),
+		tf:     tf,
+		worker: worker,
+		log:    log.NewHelper(logger),
+		dbs:    map[string]*sql.DB{},
+		report: &VerifyReport{State: VerifyIdle},
+	}
+	if cfg.GetSharding().GetEnabled() {
+		v.tables = int(cfg.GetSharding().GetTableCount())
+	}
+	cleanup := func() {
+		v.mu.Lock()
+		cancel, done := v.cancel, v.done
+		v.mu.Unlock()
+		if cancel != nil {
+			cancel()
+			<-done
+		}
+		for _, db := range v.dbs {
+			_ = db.Close()
+		}
+	}
+	return v, cleanup, nil
+}
+
+// Start 在后台开始一次核对，返回初始的报告
+func (v *Verifier) Start(opt VerifyOption) (*VerifyReport, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.report.State == VerifyRunning {
+		return nil, ErrVerifyRunning
+	}
+	for _, shard := range v.shards {
+		if v.dbs[shard.Database.GetSource()] != nil {
+			continue
+		}
+		db, err := NewMySQL(shard.Database)
+		if err != nil {
+			return nil, err
+		}
+		v.dbs[shard.Database.GetSource()] = db
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	v.report = &VerifyReport{State: VerifyRunning, Option: opt, StartAt: time.Now()}
+	v.cancel, v.done = cancel, make(chan struct{})
+	go func(report *VerifyReport, done chan struct{}) {
+		defer close(done)
+		defer cancel()
+		err := v.run(ctx, report)
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		report.FinishAt = time.Now()
+		report.State = VerifyDone
+		if err != nil {
+			report.State = VerifyFailed
+			report.Err = err.Error()
+			v.log.Errorf("verify failed at %s id %d, err:%v", report.Table, report.Cursor, err)
+			return
+		}
+		v.log.Infof("verify done, checked:%d, missing:%d, stale:%d, extra:%d, repaired:%d",
+			report.Checked, report.Missing, report.Stale, report.Extra, report.Repaired)
+	}(v.report, v.done)
+	return v.copyReport(), nil
+}
+
+// Report 最近一次核对的报告
+func (v *Verifier) Report() *VerifyReport {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.copyReport()
+}
+
+func (v *Verifier) copyReport() *VerifyReport {
+	r := *v.report
+	r.Mismatches = append([]Mismatch(nil), v.report.Mismatches...)
+	return &r
+}
+
+// run 依次核对每张分表
+func (v *Verifier) run(ctx context.Context, report *VerifyReport) error {
+	opt := report.Option
+	if opt.Chunk <= 0 {
+		opt.Chunk = defaultVerifyChunk
+	}
+	if opt.Sample <= 0 || opt.Sample > 1 {
+		opt.Sample = 1
+	}
+	v.mu.Lock()
+	report.Option = opt
+	v.mu.Unlock()
+	for i, shard := range v.shards {
+		v.mu.Lock()
+		report.Table, report.Cursor = shard.Table, 0
+		db := v.dbs[shard.Database.GetSource()]
+		v.mu.Unlock()
+		if err := v.runShard(ctx, report, db, i, opt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runShard 按主键分批核对一张分表，每批从范围内抽取主键后分别查询 MySQL 和索引
+func (v *Verifier) runShard(ctx context.Context, report *VerifyReport, db *sql.DB, shard int, opt VerifyOption) error {
+	table := v.shards[shard].Table
+	if opt.MinID == 0 || opt.MaxID == 0 {
+		var minID, maxID int64
+		if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(MIN(id), 0), COALESCE(MAX(id), 0) FROM %s", table)).Scan(&minID, &maxID); err != nil {
+			return err
+		}
+		if opt.MinID == 0 {
+			opt.MinID = minID
+		}
+		if opt.MaxID == 0 {
+			opt.MaxID = maxID
+		}
+	}
+
+	// 抽样时扩大每批的主键范围，使每批抽中的主键数与全量核对时接近
+	step := int64(float64(opt.Chunk) / opt.Sample)
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for lo := opt.MinID; lo <= opt.MaxID; lo += step {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hi := lo + step - 1
+		if hi > opt.MaxID {
+			hi = opt.MaxID
+		}
+		var ids []interface{}
+		for id := lo; id <= hi; id++ {
+			if opt.Sample == 1 || rng.Float64() < opt.Sample {
+				ids = append(ids, strconv.FormatInt(id, 10))
+			}
+		}
+		if len(ids) > 0 {
+			if err := v.check(ctx, report, db, shard, ids, opt.Repair); err != nil {
+				return err
+			}
+		}
+		v.mu.Lock()
+		report.Cursor = hi
+		v.mu.Unlock()
+	}
+	return nil
+}
+
+// check 核对一张分表的一批主键，需要修复时与 backfill 一样经路由规则写入
+func (v *Verifier) check(ctx context.Context, report *VerifyReport, db *sql.DB, shard int, ids []interface{}, repair bool) error {
+	table := v.shards[shard].Table
+	recent, err := dbVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	rows, err := queryRows(ctx, db, fmt.Sprintf("SELECT * FROM %s WHERE id IN (%s)", table, placeholders(len(ids))), ids...)
+	if err != nil {
+		return err
+	}
+	docs, err := v.docs(ctx, "review_id", column(rows, "review_id"), 1)
+	if err != nil {
+		return err
+	}
+	gone, err := v.gone(ctx, db, shard, ids, rows)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mismatches       []Mismatch
+		upserts, deletes []map[string]interface{}
+		checked, skipped int64
+	)
+	for _, row := range rows {
+		r := new(search.ReviewDoc)
+		if err := v.tf.Decode(row, r); err != nil {
+			return fmt.Errorf("%s %v: %w", table, row["id"], err)
+		}
+		doc, found := docs[r.ReviewID]
+		if r.UpdateAt.UnixMilli() > recent {
+			skipped++
+			continue
+		}
+		checked++
+		m := Mismatch{Table: table, ID: r.ID, ReviewID: r.ReviewID, Row: state(r)}
+		if found {
+			m.Doc = state(doc)
+		}
+		switch {
+		case m.Row.Deleted && found:
+			m.Kind = MismatchExtra
+		case !m.Row.Deleted && !found:
+			m.Kind = MismatchMissing
+		case found && !sameState(m.Row, m.Doc):
+			m.Kind = MismatchStale
+		default:
+			continue
+		}
+		mismatches = append(mismatches, m)
+		upserts = append(upserts, row)
+	}
+	for _, doc := range gone {
+		mismatches = append(mismatches, Mismatch{Kind: MismatchExtra, Table: table, ID: doc.ID, ReviewID: doc.ReviewID, Doc: state(doc)})
+		deletes = append(deletes, map[string]interface{}{"id": strconv.FormatInt(doc.ID, 10), "review_id": strconv.FormatInt(doc.ReviewID, 10)})
+	}
+
+	if repair && len(mismatches) > 0 {
+		version, err := dbVersion(ctx, db)
+		if err != nil {
+			return err
+		}
+		msgs := []*Msg{
+			{Type: "UPDATE", Table: table, Es: version, Data: upserts},
+			{Type: "DELETE", Table: table, Es: version, Data: deletes},
+		}
+		if err := v.worker.apply(ctx, msgs); err != nil {
+			return fmt.Errorf("repair %d documents: %w", len(mismatches), err)
+		}
+		for i := range mismatches {
+			mismatches[i].Repaired = true
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	report.Checked += checked
+	report.Skipped += skipped
+	for _, m := range mismatches {
+		switch m.Kind {
+		case MismatchMissing:
+			report.Missing++
+		case MismatchStale:
+			report.Stale++
+		case MismatchExtra:
+			report.Extra++
+		}
+		if m.Repaired {
+			report.Repaired++
+		}
+		if len(report.Mismatches) < maxReportMismatches {
+			report.Mismatches = append(report.Mismatches, m)
+		}
+	}
+	return nil
+}
+
+// gone 行已被物理删除、索引中仍有的文档：按主键找到这张分表的文档，其中 review_id 不在这批行中、
+// 在分表中也已经不存在的文档。其他分表的行与这张分表的主键相同，按 store_id 排除
+func (v *Verifier) gone(ctx context.Context, db *sql.DB, shard int, ids []interface{}, rows []map[string]interface{}) ([]*search.ReviewDoc, error) {
+	byID, err := v.docs(ctx, "id", ids, v.tables)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		present[fmt.Sprint(row["review_id"])] = true
+	}
+	var candidates []*search.ReviewDoc
+	var reviewIDs []interface{}
+	for reviewID, doc := range byID {
+		if present[strconv.FormatInt(reviewID, 10)] || (v.tables > 0 && shardIndex(doc.StoreID, v.tables) != shard) {
+			continue
+		}
+		candidates = append(candidates, doc)
+		reviewIDs = append(reviewIDs, strconv.FormatInt(reviewID, 10))
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	// review_id 仍在分表中时主键已经变化（如重新分片），这一行在它自己的批次中核对
+	existing, err := queryRows(ctx, db, fmt.Sprintf("SELECT review_id FROM %s WHERE review_id IN (%s)", v.shards[shard].Table, placeholders(len(reviewIDs))), reviewIDs...)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range existing {
+		present[fmt.Sprint(row["review_id"])] = true
+	}
+	var docs []*search.ReviewDoc
+	for _, doc := range candidates {
+		if !present[strconv.FormatInt(doc.ReviewID, 10)] {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// docs 按 field 查询评价索引中的文档，每个值最多对应 per 篇文档，以 review_id（文档 ID）为键；无法解析的旧文档按零值比对，计为 stale
+func (v *Verifier) docs(ctx context.Context, field string, ids []interface{}, per int) (map[int64]*search.ReviewDoc, error) {
+	docs := make(map[int64]*search.ReviewDoc, len(ids))
+	if len(ids) == 0 {
+		return docs, nil
+	}
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, fmt.Sprint(id))
+	}
+	res, err := v.worker.indices[IndexReview].Query(ctx, &search.Query{
+		Filters: []search.Filter{{Field: field, Values: values}},
+		Size:    len(values) * max(per, 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range res.Hits {
+		doc := new(search.ReviewDoc)
+		if err := json.Unmarshal(hit.Source, doc); err != nil {
+			v.log.Warnf("decode document %s failed, err:%v", hit.ID, err)
+			id, _ := strconv.ParseInt(hit.ID, 10, 64)
+			doc = &search.ReviewDoc{ReviewID: id}
+			var raw struct {
+				ID      json.Number `json:"id"`
+				StoreID json.Number `json:"store_id"`
+			}
+			_ = json.Unmarshal(hit.Source, &raw)
+			doc.ID, _ = strconv.ParseInt(raw.ID.String(), 10, 64)
+			doc.StoreID, _ = strconv.ParseInt(raw.StoreID.String(), 10, 64)
+		}
+		docs[doc.ReviewID] = doc
+	}
+	return docs, nil
+}
+
+func state(d *search.ReviewDoc) *ReviewState {
+	return &ReviewState{Version: d.Version, Status: d.Status, UpdateAt: d.UpdateAt, Deleted: d.DeleteAt != nil}
+}
+
+func sameState(a, b *ReviewState) bool {
+	return a.Version == b.Version && a.Status == b.Status && a.UpdateAt.Equal(b.UpdateAt)
+}
@@ -1,7 +1,7 @@
 package server
 
 import (
-	v1 "review-job/api/helloworld/v1"
+	v1 "review-job/api/job/v1"
 	"review-job/internal/conf"
 	"review-job/internal/service"
 
@@ -11,7 +11,7 @@ import (
 )
 
 // NewGRPCServer new a gRPC server.
-func NewGRPCServer(c *conf.Server, greeter *service.GreeterService, logger log.Logger) *grpc.Server {
+func NewGRPCServer(c *conf.Server, dlq *service.DeadLetterService, sync *service.SyncService, verify *service.VerifyService, logger log.Logger) *grpc.Server {
 	var opts = []grpc.ServerOption{
 		grpc.Middleware(
 			recovery.Recovery(),
@@ -27,6 +27,8 @@ func NewGRPCServer(c *conf.Server, greeter *service.GreeterService, logger log.L
 		opts = append(opts, grpc.Timeout(c.Grpc.Timeout.AsDuration()))
 	}
 	srv := grpc.NewServer(opts...)
-	v1.RegisterGreeterServer(srv, greeter)
+	v1.RegisterDeadLetterServer(srv, dlq)
+	v1.RegisterSyncServer(srv, sync)
+	v1.RegisterVerifyServer(srv, verify)
 	return srv
 }
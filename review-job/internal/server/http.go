@@ -1,7 +1,7 @@
 package server
 
 import (
-	v1 "review-job/api/helloworld/v1"
+	v1 "review-job/api/job/v1"
 	"review-job/internal/conf"
 	"review-job/internal/service"
 
@@ -11,7 +11,7 @@ import (
 )
 
 // NewHTTPServer new an HTTP server.
-func NewHTTPServer(c *conf.Server, greeter *service.GreeterService, logger log.Logger) *http.Server {
+func NewHTTPServer(c *conf.Server, dlq *service.DeadLetterService, sync *service.SyncService, verify *service.VerifyService, logger log.Logger) *http.Server {
 	var opts = []http.ServerOption{
 		http.Middleware(
 			recovery.Recovery(),
@@ -27,6 +27,8 @@ func NewHTTPServer(c *conf.Server, greeter *service.GreeterService, logger log.L
 		opts = append(opts, http.Timeout(c.Http.Timeout.AsDuration()))
 	}
 	srv := http.NewServer(opts...)
-	v1.RegisterGreeterHTTPServer(srv, greeter)
+	v1.RegisterDeadLetterHTTPServer(srv, dlq)
+	v1.RegisterSyncHTTPServer(srv, sync)
+	v1.RegisterVerifyHTTPServer(srv, verify)
 	return srv
 }
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	v1 "review-job/api/job/v1"
+	"review-job/internal/job"
+)
+
+// DeadLetterService 查询死信队列的状态
+type DeadLetterService struct {
+	v1.UnimplementedDeadLetterServer
+
+	dlq *job.DeadLetter
+}
+
+func NewDeadLetterService(dlq *job.DeadLetter) *DeadLetterService {
+	return &DeadLetterService{dlq: dlq}
+}
+
+// GetDeadLetterStats 死信队列中现存和待重放的消息数
+func (s *DeadLetterService) GetDeadLetterStats(ctx context.Context, req *v1.GetDeadLetterStatsRequest) (*v1.GetDeadLetterStatsReply, error) {
+	stats, err := s.dlq.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply := &v1.GetDeadLetterStatsReply{
+		Topic:      stats.Topic,
+		Total:      stats.Total,
+		Pending:    stats.Pending,
+		Published:  stats.Published,
+		Partitions: make([]*v1.GetDeadLetterStatsReply_Partition, 0, len(stats.Partitions)),
+	}
+	for _, p := range stats.Partitions {
+		reply.Partitions = append(reply.Partitions, &v1.GetDeadLetterStatsReply_Partition{
+			Partition: int32(p.Partition),
+			Total:     p.Total,
+			Pending:   p.Pending,
+		})
+	}
+	return reply, nil
+}
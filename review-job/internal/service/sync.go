@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	v1 "review-job/api/job/v1"
+	"review-job/internal/job"
+)
+
+// SyncService 查询数据同步的状态
+type SyncService struct {
+	v1.UnimplementedSyncServer
+
+	router *job.Router
+}
+
+func NewSyncService(router *job.Router) *SyncService {
+	return &SyncService{router: router}
+}
+
+// GetSyncStats 各表已处理和被忽略的行数、DDL 次数和结构不一致的表
+func (s *SyncService) GetSyncStats(ctx context.Context, req *v1.GetSyncStatsRequest) (*v1.GetSyncStatsReply, error) {
+	stats := s.router.Stats()
+	return &v1.GetSyncStatsReply{
+		Routed:        stats.Routed,
+		Ignored:       stats.Ignored,
+		Ddl:           stats.DDL,
+		DriftedTables: stats.Drifted,
+	}, nil
+}
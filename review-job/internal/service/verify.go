@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	v1 "review-job/api/job/v1"
+	"review-job/internal/job"
+)
+
+// VerifyService 核对 MySQL 与评价索引的一致性
+type VerifyService struct {
+	v1.UnimplementedVerifyServer
+
+	verifier *job.Verifier
+}
+
+func NewVerifyService(verifier *job.Verifier) *VerifyService {
+	return &VerifyService{verifier: verifier}
+}
+
+// StartVerify 在后台开始一次核对
+func (s *VerifyService) StartVerify(ctx context.Context, req *v1.StartVerifyRequest) (*v1.VerifyReport, error) {
+	if req.MinId < 0 || req.MaxId < 0 || (req.MaxId > 0 && req.MinId > req.MaxId) {
+		return nil, kerrors.BadRequest("INVALID_RANGE", "min_id and max_id must be non-negative and min_id <= max_id")
+	}
+	if req.Sample < 0 || req.Sample > 1 {
+		return nil, kerrors.BadRequest("INVALID_SAMPLE", "sample must be between 0 and 1")
+	}
+	report, err := s.verifier.Start(job.VerifyOption{
+		MinID:  req.MinId,
+		MaxID:  req.MaxId,
+		Sample: req.Sample,
+		Repair: req.Repair,
+	})
+	if errors.Is(err, job.ErrVerifyRunning) {
+		return nil, kerrors.Conflict("VERIFY_RUNNING", err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toVerifyReport(report), nil
+}
+
+// GetVerifyReport 最近一次核对的进度和结果
+func (s *VerifyService) GetVerifyReport(ctx context.Context, req *v1.GetVerifyReportRequest) (*v1.VerifyReport, error) {
+	return toVerifyReport(s.verifier.Report()), nil
+}
+
+func toVerifyReport(r *job.VerifyReport) *v1.VerifyReport {
+	reply := &v1.VerifyReport{
+		State:      r.State,
+		MinId:      r.Option.MinID,
+		MaxId:      r.Option.MaxID,
+		Sample:     r.Option.Sample,
+		Repair:     r.Option.Repair,
+		Checked:    r.Checked,
+		Skipped:    r.Skipped,
+		Missing:    r.Missing,
+		Stale:      r.Stale,
+		Extra:      r.Extra,
+		Repaired:   r.Repaired,
+		Mismatches: make([]*v1.Mismatch, 0, len(r.Mismatches)),
+		Table:      r.Table,
+		Cursor:     r.Cursor,
+		Error:      r.Err,
+		StartAt:    timestamp(r.StartAt),
+		FinishAt:   timestamp(r.FinishAt),
+	}
+	for _, m := range r.Mismatches {
+		reply.Mismatches = append(reply.Mismatches, &v1.Mismatch{
+			Kind:     m.Kind,
+			Table:    m.Table,
+			Id:       m.ID,
+			ReviewId: m.ReviewID,
+			Row:      toReviewState(m.Row),
+			Doc:      toReviewState(m.Doc),
+			Repaired: m.Repaired,
+		})
+	}
+	return reply
+}
+
+func toReviewState(s *job.ReviewState) *v1.ReviewState {
+	if s == nil {
+		return nil
+	}
+	return &v1.ReviewState{Version: s.Version, Status: s.Status, UpdateAt: timestamp(s.UpdateAt), Deleted: s.Deleted}
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
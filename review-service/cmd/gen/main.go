@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"regexp"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"gorm.io/driver/mysql"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+	"review-service/internal/conf"
+	"review-service/internal/data/querier"
+)
+
+// gorm/gen 代码生成工具，在 review-service 目录下执行
+//
+//	go run ./cmd/gen -conf ./configs -out ./internal/data/query
+//	go run ./cmd/gen -conf ./configs -tables review_info,review_appeal_info
+//
+// 模型生成到 out 同级的 model 目录，querier 中的自定义接口会生成为对应表的查询方法
+
+var (
+	flagconf string
+	outPath  string
+	tables   string
+)
+
+func init() {
+	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
+	flag.StringVar(&outPath, "out", "../../internal/data/query", "query code output path")
+	flag.StringVar(&tables, "tables", "", "comma separated tables to generate, empty means all tables")
+}
+
+// diyInterfaces 表对应的自定义查询接口
+var diyInterfaces = map[string][]interface{}{
+	"review_info": {
+		func(querier.ReviewIDQuerier) {},
+		func(querier.ReviewQuerier) {},
+	},
+	"review_reply_info": {
+		func(querier.ReviewIDQuerier) {},
+	},
+	"review_appeal_info": {
+		func(querier.ReviewIDQuerier) {},
+		func(querier.AppealQuerier) {},
+	},
+}
+
+// dataTypeMap 字段类型映射
+// 时间类型统一为 time.Time，可空列由 FieldNullable 生成指针；JSON 列使用 datatypes.JSON
+var dataTypeMap = map[string]func(columnType gorm.ColumnType) (dataType string){
+	"tinyint":   func(gorm.ColumnType) string { return "int32" },
+	"smallint":  func(gorm.ColumnType) string { return "int32" },
+	"date":      func(gorm.ColumnType) string { return "time.Time" },
+	"datetime":  func(gorm.ColumnType) string { return "time.Time" },
+	"timestamp": func(gorm.ColumnType) string { return "time.Time" },
+	"json":      func(gorm.ColumnType) string { return "datatypes.JSON" },
+}
+
+func main() {
+	flag.Parse()
+
+	// 1. 读取配置文件
+	c := config.New(config.WithSource(file.NewSource(flagconf)))
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		panic(err)
+	}
+	var bc conf.Bootstrap
+	if err := c.Scan(&bc); err != nil {
+		panic(err)
+	}
+
+	// 2. 连接数据库，生成模型需要读取表结构
+	db, err := gorm.Open(mysql.Open(bc.Data.Database.Source), &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+
+	// 3. 初始化 gorm gen
+	g := gen.NewGenerator(gen.Config{
+		OutPath:       outPath,
+		Mode:          gen.WithDefaultQuery | gen.WithQueryInterface,
+		FieldNullable: true,
+	})
+	g.WithDataTypeMap(dataTypeMap)
+	g.WithImportPkgPath("gorm.io/datatypes")
+	g.UseDB(db)
+
+	// 4. 生成表模型，未指定时生成所有表
+	names, err := tableNames(db)
+	if err != nil {
+		panic(err)
+	}
+	models := make(map[string]interface{}, len(names))
+	basic := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		models[name] = g.GenerateModel(name)
+		basic = append(basic, models[name])
+	}
+	g.ApplyBasic(basic...)
+
+	// 5. 生成自定义查询方法
+	for name, m := range models {
+		for _, fc := range diyInterfaces[name] {
+			g.ApplyInterface(fc, m)
+		}
+	}
+
+	// 6. 执行生成代码
+	g.Execute()
+}
+
+// shardTable 分表的物理表，如 review_info_03
+var shardTable = regexp.MustCompile(`_\d{2}$`)
+
+// tableNames 需要生成的表
+// 未指定时取库中所有表，跳过迁移记录表和物理分表
+func tableNames(db *gorm.DB) ([]string, error) {
+	var names []string
+	if tables == "" {
+		all, err := db.Migrator().GetTables()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range all {
+			if name == "schema_migrations" || shardTable.MatchString(name) {
+				continue
+			}
+			names = append(names, name)
+		}
+		return names, nil
+	}
+	for _, name := range strings.Split(tables, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
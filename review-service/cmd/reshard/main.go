@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+	"review-service/internal/conf"
+	"review-service/internal/data"
+	"review-service/internal/data/model"
+)
+
+// 分表数据迁移工具
+// 按 conf 中的 sharding 配置创建物理分表，并把旧布局中的数据按 store_id 重新分布
+//
+//	reshard -conf ../../configs -from 0                          从未分表的原表迁移
+//	reshard -conf ../../configs -from 4                          从位于主库的 4 张分表迁移到配置中的新布局
+//	reshard -conf ../../configs -from 4 -from-databases a,b      旧布局的 4 张分表位于分库 a、b
+//
+// 旧布局按 -from 和 -from-databases 定位源分表，与配置中的新布局相互独立：旧分表 i 位于
+// from-databases[i % len]，未指定时位于主库。源表从主库读取，避免从库延迟漏掉数据
+// 以 review_id / reply_id / appeal_id 唯一键做 upsert，可以重复执行
+// 源表同时也是目标分表（同一个库中的同名表）时删除已迁走的数据；其余旧分表需要确认后手动删除
+
+var (
+	flagconf      string
+	fromCount     int
+	fromDatabases string
+	batchSize     int
+)
+
+func init() {
+	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
+	flag.IntVar(&fromCount, "from", 0, "source table count, 0 means the original unsharded tables")
+	flag.StringVar(&fromDatabases, "from-databases", "", "comma separated DSNs of the source layout, empty means the main database")
+	flag.IntVar(&batchSize, "batch", 500, "rows per batch")
+}
+
+func main() {
+	flag.Parse()
+	logger := log.NewStdLogger(os.Stdout)
+	helper := log.NewHelper(logger)
+
+	c := config.New(config.WithSource(file.NewSource(flagconf)))
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		panic(err)
+	}
+	var bc conf.Bootstrap
+	if err := c.Scan(&bc); err != nil {
+		panic(err)
+	}
+
+	db, err := data.NewDB(bc.Data, logger)
+	if err != nil {
+		panic(err)
+	}
+	target, cleanup, err := data.NewSharding(bc.Data, db, logger)
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+	if !target.Enabled() {
+		helper.Fatal("sharding is not enabled in config")
+	}
+
+	// 旧布局，未分表时只有主库中的原表
+	from := &conf.Data{Database: bc.Data.Database}
+	if fromCount > 0 {
+		from.Sharding = &conf.Data_Sharding{Enabled: true, TableCount: int32(fromCount)}
+		if fromDatabases != "" {
+			from.Sharding.Databases = strings.Split(fromDatabases, ",")
+		}
+	}
+	source, sourceCleanup, err := data.NewSharding(from, db, logger)
+	if err != nil {
+		panic(err)
+	}
+	defer sourceCleanup()
+
+	tables := []string{model.TableNameReviewInfo, model.TableNameReviewReplyInfo, model.TableNameReviewAppealInfo}
+	// 1. 创建目标分表
+	for idx := 0; idx < target.Count(); idx++ {
+		for _, logical := range tables {
+			sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` LIKE `%s`", target.TableName(logical, idx), logical)
+			if err := target.DB(idx).Exec(sql).Error; err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// 2. 逐个源表迁移数据
+	for src := 0; src < source.Count(); src++ {
+		srcDB := source.DB(src)
+		idx := src
+		srcTable := func(logical string) string { return source.TableName(logical, idx) }
+
+		n, err := copyTable(srcDB, srcTable(model.TableNameReviewInfo), "review_id", target,
+			func(row *model.ReviewInfo) (int64, int64) { id := row.ID; row.ID = 0; return id, row.StoreID },
+			model.TableNameReviewInfo)
+		if err != nil {
+			panic(err)
+		}
+		helper.Infof("resharded %s: %d rows", srcTable(model.TableNameReviewInfo), n)
+
+		n, err = copyTable(srcDB, srcTable(model.TableNameReviewReplyInfo), "reply_id", target,
+			func(row *model.ReviewReplyInfo) (int64, int64) { id := row.ID; row.ID = 0; return id, row.StoreID },
+			model.TableNameReviewReplyInfo)
+		if err != nil {
+			panic(err)
+		}
+		helper.Infof("resharded %s: %d rows", srcTable(model.TableNameReviewReplyInfo), n)
+
+		n, err = copyTable(srcDB, srcTable(model.TableNameReviewAppealInfo), "appeal_id", target,
+			func(row *model.ReviewAppealInfo) (int64, int64) { id := row.ID; row.ID = 0; return id, row.StoreID },
+			model.TableNameReviewAppealInfo)
+		if err != nil {
+			panic(err)
+		}
+		helper.Infof("resharded %s: %d rows", srcTable(model.TableNameReviewAppealInfo), n)
+
+		// 源表同时也是目标分表时，删除已迁走的数据；分片下标与 ShardIndex 一样取余数的绝对值
+		if fromCount > 0 && src < target.Count() && sameDatabase(from, bc.Data, src) {
+			for _, logical := range tables {
+				sql := fmt.Sprintf("DELETE FROM `%s` WHERE ABS(MOD(store_id, ?)) <> ?", srcTable(logical))
+				if err := srcDB.Exec(sql, target.Count(), src).Error; err != nil {
+					panic(err)
+				}
+			}
+		}
+	}
+}
+
+// sameDatabase 旧布局和新布局的第 idx 张分表是否位于同一个库
+func sameDatabase(from, to *conf.Data, idx int) bool {
+	dsn := func(c *conf.Data) string {
+		if dbs := c.GetSharding().GetDatabases(); len(dbs) > 0 {
+			return dbs[idx%len(dbs)]
+		}
+		return c.GetDatabase().GetSource()
+	}
+	return dsn(from) == dsn(to)
+}
+
+// copyTable 按主键分批读取源表，按 store_id 写入目标分表
+// route 返回行的原主键和 store_id，并清空主键交给目标表自增
+func copyTable[T any](src *gorm.DB, srcTable, uniqueKey string, target *data.Sharding,
+	route func(row *T) (id int64, storeID int64), logical string) (int, error) {
+	var (
+		lastID int64
+		total  int
+	)
+	for {
+		var rows []*T
+		err := src.Clauses(dbresolver.Write).Table(srcTable).Where("id > ?", lastID).Order("id").Limit(batchSize).Find(&rows).Error
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+		for _, row := range rows {
+			id, storeID := route(row)
+			lastID = id
+			idx := target.ShardIndex(storeID)
+			err := target.DB(idx).Table(target.TableName(logical, idx)).
+				Clauses(clause.OnConflict{Columns: []clause.Column{{Name: uniqueKey}}, UpdateAll: true}).
+				Create(row).Error
+			if err != nil {
+				return total, err
+			}
+		}
+		total += len(rows)
+	}
+}
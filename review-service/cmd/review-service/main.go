@@ -75,6 +75,14 @@ func main() {
 		panic(err)
 	}
 
+	// 子命令：review-service -conf ../../configs migrate up|down [n]|status
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(bc.Data, logger, flag.Args()[1:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	app, cleanup, err := wireApp(bc.Server, bc.Registry, bc.Data, logger)
 	if err != nil {
 		panic(err)
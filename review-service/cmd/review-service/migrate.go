@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"review-service/internal/conf"
+	"review-service/internal/data"
+	"review-service/internal/data/migrate"
+)
+
+// runMigrate 执行数据库迁移子命令
+// 开启分库时依次迁移主库和每个分库：分库中同样需要逻辑表（reshard 按它创建分表）和发件箱 review_outbox
+//
+//	review-service -conf ../../configs migrate up
+//	review-service -conf ../../configs migrate down [n]
+//	review-service -conf ../../configs migrate status
+func runMigrate(c *conf.Data, logger log.Logger, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [n]|status")
+	}
+	steps := 1
+	switch args[0] {
+	case "up", "status":
+	case "down":
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid steps: %s", args[1])
+			}
+		}
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+	sources := migrateSources(c)
+	for i, dsn := range sources {
+		name := "main database"
+		if i > 0 {
+			name = fmt.Sprintf("shard database %d", i)
+		}
+		if args[0] == "status" && len(sources) > 1 {
+			fmt.Fprintf(os.Stdout, "%s:\n", name)
+		}
+		if err := migrateDatabase(c, dsn, logger, args[0], steps); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if args[0] != "status" {
+			log.NewHelper(logger).Infof("migrate %s of %s done", args[0], name)
+		}
+	}
+	return nil
+}
+
+// migrateSources 需要迁移的数据库：主库，开启分库时加上与主库不同的分库
+func migrateSources(c *conf.Data) []string {
+	sources := []string{c.Database.Source}
+	if !c.GetSharding().GetEnabled() {
+		return sources
+	}
+	seen := map[string]bool{c.Database.Source: true}
+	for _, dsn := range c.Sharding.Databases {
+		if !seen[dsn] {
+			seen[dsn] = true
+			sources = append(sources, dsn)
+		}
+	}
+	return sources
+}
+
+// migrateDatabase 对一个数据库执行迁移命令，迁移只在主库执行，不连接从库
+func migrateDatabase(c *conf.Data, dsn string, logger log.Logger, cmd string, steps int) error {
+	db, err := data.OpenDB(c.Database, dsn, nil, logger)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	m, err := migrate.NewMigrator(sqlDB, logger)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	switch cmd {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx, steps)
+	default:
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range list {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(os.Stdout, "%04d  %-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+		return nil
+	}
+}
@@ -29,30 +29,52 @@ func wireApp(confServer *conf.Server, registry *conf.Registry, confData *conf.Da
 	if err != nil {
 		return nil, nil, err
 	}
+	sharding, cleanup, err := data.NewSharding(confData, db, logger)
+	if err != nil {
+		return nil, nil, err
+	}
 	client, err := data.NewRedis(confData, logger)
 	if err != nil {
+		cleanup()
 		return nil, nil, err
 	}
-	typedClient, err := data.NewESClient(confData, logger)
+	index, cleanup2, err := data.NewSearchIndex(confData, logger)
 	if err != nil {
+		cleanup()
 		return nil, nil, err
 	}
-	dataData, cleanup, err := data.NewData(db, client, typedClient, confData, logger)
+	dataData, cleanup3, err := data.NewData(db, sharding, client, index, confData, logger)
 	if err != nil {
+		cleanup2()
+		cleanup()
 		return nil, nil, err
 	}
 	reviewerRepo := data.NewReviewerRepo(dataData, logger)
-	snowflake, err := biz.NewSnowflake(confData)
+	reviewQueue, cleanup4, err := data.NewReviewQueue(confData, dataData, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	snowflake, cleanup5, err := data.NewSnowflake(confData, client, logger)
 	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	reviewerUsecase := biz.NewReviewerUsecase(reviewerRepo, snowflake, logger)
+	reviewerUsecase := biz.NewReviewerUsecase(reviewerRepo, reviewQueue, snowflake, logger)
 	reviewService := service.NewReviewService(reviewerUsecase)
 	grpcServer := server.NewGRPCServer(confServer, reviewService, logger)
 	httpServer := server.NewHTTPServer(confServer, reviewService, logger)
 	app := newApp(logger, registrar, grpcServer, httpServer)
 	return app, func() {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"review-service/pkg/snowflake"
+)
+
+// 雪花ID排查工具
+//
+//	snowflake decode 123456789 987654321
+//	snowflake range -start "2025-09-01 00:00:00" -end "2025-09-02 00:00:00"
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  snowflake decode <id> [id...]        解析ID的生成时间、数据中心、机器和序列号
+  snowflake range -start <t> [-end <t>] 输出时间范围对应的ID上下界`)
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "decode":
+		decode(os.Args[2:])
+	case "range":
+		idRange(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func decode(args []string) {
+	if len(args) == 0 {
+		usage()
+	}
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid id %q: %v\n", arg, err)
+			os.Exit(1)
+		}
+		d := snowflake.Decode(id)
+		fmt.Printf("id=%d time=%s dataCenterID=%d workerID=%d sequence=%d\n",
+			id, d.Timestamp.Format("2006-01-02 15:04:05.000"), d.DataCenterID, d.WorkerID, d.Sequence)
+	}
+}
+
+func idRange(args []string) {
+	fs := flag.NewFlagSet("range", flag.ExitOnError)
+	start := fs.String("start", "", "start time, eg: 2025-09-01 00:00:00 or RFC3339")
+	end := fs.String("end", "", "end time, default now")
+	_ = fs.Parse(args)
+	if *start == "" {
+		usage()
+	}
+	st, err := parseTime(*start)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid start: %v\n", err)
+		os.Exit(1)
+	}
+	et := time.Now()
+	if *end != "" {
+		if et, err = parseTime(*end); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid end: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("min_id=%d max_id=%d\n", snowflake.MinIDForTime(st), snowflake.MaxIDForTime(et))
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateTime, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,107 @@
+package biz
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	v1 "review-service/api/review/v1"
+	"review-service/internal/data/model"
+	"review-service/pkg/event"
+)
+
+// CreateStatus 评价的创建进度，Status 为 event.CreateAccepted / CreateCreated / CreateFailed
+type CreateStatus struct {
+	ReviewID int64
+	Status   string
+	Reason   string
+}
+
+// ReviewQueue 异步创建评价的命令队列，命令由 review-job 消费后写入 MySQL，进度记录在 redis
+type ReviewQueue interface {
+	// Enabled 是否开启异步创建，关闭时评价同步写入 MySQL
+	Enabled() bool
+	// Accept 占用订单并记录受理状态，订单已有受理中的评价时返回 false
+	Accept(ctx context.Context, reviewID, orderID int64) (bool, error)
+	// Release 投递失败时释放订单和受理状态
+	Release(ctx context.Context, reviewID, orderID int64)
+	Publish(ctx context.Context, cmd *event.CreateReviewCommand) error
+	// Status 评价的创建进度，没有记录时返回 nil
+	Status(ctx context.Context, reviewID int64) (*CreateStatus, error)
+}
+
+// SubmitReview 创建评价并返回创建进度
+// 开启异步创建时只做校验，把创建命令投递到 kafka 后返回 accepted；否则同步写入后返回 created
+func (uc *ReviewerUsecase) SubmitReview(ctx context.Context, review *model.ReviewInfo) (*model.ReviewInfo, string, error) {
+	if uc.queue == nil || !uc.queue.Enabled() {
+		rv, err := uc.CreateReviewer(ctx, review)
+		return rv, event.CreateCreated, err
+	}
+	if err := uc.checkOrder(ctx, review.OrderID); err != nil {
+		return nil, "", err
+	}
+	reviewID, err := uc.sf.NextID()
+	if err != nil {
+		return nil, "", err
+	}
+	review.ReviewID = reviewID
+	created, err := uc.reviewCreated(review)
+	if err != nil {
+		return nil, "", err
+	}
+	cmd := &event.CreateReviewCommand{
+		ReviewId:     review.ReviewID,
+		OrderId:      review.OrderID,
+		StoreId:      review.StoreID,
+		UserId:       review.UserID,
+		SkuId:        review.SkuID,
+		SpuId:        review.SpuID,
+		Score:        review.Score,
+		ServiceScore: review.ServiceScore,
+		ExpressScore: review.ExpressScore,
+		Content:      review.Content,
+		PicInfo:      review.PicInfo,
+		VideoInfo:    review.VideoInfo,
+		HasMedia:     review.HasMedia,
+		Anonymous:    review.Anonymous,
+		Status:       review.Status,
+		CreateBy:     review.CreateBy,
+		AcceptedAt:   timestamppb.New(review.CreateAt),
+		Created:      created,
+	}
+	// 订单在 MySQL 中还没有评价，但可能有受理中的评价
+	ok, err := uc.queue.Accept(ctx, review.ReviewID, review.OrderID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		return nil, "", v1.ErrorOrderReviewed("order id %d already exist a review", review.OrderID)
+	}
+	if err := uc.queue.Publish(ctx, cmd); err != nil {
+		uc.queue.Release(ctx, review.ReviewID, review.OrderID)
+		return nil, "", err
+	}
+	uc.log.WithContext(ctx).Infof("[biz] SubmitReview accepted ID: %v", review.ReviewID)
+	return review, event.CreateAccepted, nil
+}
+
+// GetReviewStatus 评价的创建进度
+// 没有进度记录（同步创建或记录已过期）时以评价是否存在为准
+func (uc *ReviewerUsecase) GetReviewStatus(ctx context.Context, reviewID int64) (*CreateStatus, error) {
+	if uc.queue != nil {
+		status, err := uc.queue.Status(ctx, reviewID)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil {
+			return status, nil
+		}
+	}
+	rv, err := uc.repo.GetReviewByReviewID(WithPrimary(ctx), reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rv) == 0 {
+		return nil, v1.ErrorReviewidErr("Do not exist ReviewID: %v", reviewID)
+	}
+	return &CreateStatus{ReviewID: reviewID, Status: event.CreateCreated}, nil
+}
@@ -0,0 +1,87 @@
+package biz
+
+import (
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"review-service/internal/data/model"
+	"review-service/pkg/event"
+)
+
+// 领域事件与业务数据在同一个事务中写入发件箱，由 review-job 投递到 kafka
+
+// newEvent 构造评价聚合上的一个事件，payload 由调用方设置
+func (uc *ReviewerUsecase) newEvent(eventType string, reviewID int64, actor *event.Actor) (*event.Envelope, error) {
+	eventID, err := uc.sf.NextID()
+	if err != nil {
+		return nil, err
+	}
+	return &event.Envelope{
+		EventId:       eventID,
+		EventType:     eventType,
+		AggregateType: event.AggregateReview,
+		AggregateId:   reviewID,
+		Actor:         actor,
+		OccurredAt:    timestamppb.Now(),
+	}, nil
+}
+
+// reviewCreated 用户发表评价的事件
+func (uc *ReviewerUsecase) reviewCreated(review *model.ReviewInfo) (*event.Envelope, error) {
+	ev, err := uc.newEvent(event.TypeReviewCreated, review.ReviewID, &event.Actor{
+		Type: event.ActorUser,
+		Id:   strconv.FormatInt(review.UserID, 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ev.Payload = &event.Envelope_ReviewCreated{ReviewCreated: &event.ReviewCreated{
+		ReviewId:     review.ReviewID,
+		OrderId:      review.OrderID,
+		StoreId:      review.StoreID,
+		UserId:       review.UserID,
+		SkuId:        review.SkuID,
+		SpuId:        review.SpuID,
+		Score:        review.Score,
+		ServiceScore: review.ServiceScore,
+		ExpressScore: review.ExpressScore,
+		Content:      review.Content,
+		HasMedia:     review.HasMedia == 1,
+		Anonymous:    review.Anonymous == 1,
+		Status:       review.Status,
+	}}
+	return ev, nil
+}
+
+// reviewApproved 运营审核通过评价的事件，review 为审核前的评价
+func (uc *ReviewerUsecase) reviewApproved(review *model.ReviewInfo, opUser, opRemarks string) (*event.Envelope, error) {
+	ev, err := uc.newEvent(event.TypeReviewApproved, review.ReviewID, &event.Actor{Type: event.ActorOperator, Id: opUser})
+	if err != nil {
+		return nil, err
+	}
+	ev.Payload = &event.Envelope_ReviewApproved{ReviewApproved: &event.ReviewApproved{
+		ReviewId:  review.ReviewID,
+		StoreId:   review.StoreID,
+		UserId:    review.UserID,
+		OpUser:    opUser,
+		OpRemarks: opRemarks,
+	}}
+	return ev, nil
+}
+
+// appealDecided 运营处理申诉的事件，appeal 为处理前的申诉，decision 为处理的结果
+func (uc *ReviewerUsecase) appealDecided(appeal, decision *model.ReviewAppealInfo) (*event.Envelope, error) {
+	ev, err := uc.newEvent(event.TypeAppealDecided, appeal.ReviewID, &event.Actor{Type: event.ActorOperator, Id: decision.OpUser})
+	if err != nil {
+		return nil, err
+	}
+	ev.Payload = &event.Envelope_AppealDecided{AppealDecided: &event.AppealDecided{
+		AppealId:  appeal.AppealID,
+		ReviewId:  appeal.ReviewID,
+		StoreId:   appeal.StoreID,
+		Status:    decision.Status,
+		OpUser:    decision.OpUser,
+		OpRemarks: decision.OpRemarks,
+	}}
+	return ev, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	v1 "review-service/api/review/v1"
 	"review-service/internal/data/model"
+	"review-service/pkg/event"
 	"review-service/pkg/snowflake"
 
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 )
 
@@ -14,74 +16,126 @@ var (
 // ErrUserNotFound = errors.NotFound(v1.ErrorReason_USER_NOT_FOUND.String(), "user not found")
 )
 
+// 运营审核评价、处理申诉的结果
+const (
+	ReviewStatusApproved = 20 // 审核通过
+	ReviewStatusRejected = 30 // 审核不通过
+	AppealStatusApproved = 20 // 申诉通过
+	AppealStatusRejected = 30 // 申诉驳回
+)
+
 // Reviewer is a Reviewer model.
 type Reviewer struct {
 	Hello string
 }
 
 // ReviewerRepo is a Greater repo.
+// 带有 events 的写操作在同一个事务中把事件写入发件箱，业务数据写入失败时事件也不会写入
 type ReviewerRepo interface {
-	SaveReview(context.Context, *model.ReviewInfo) (*model.ReviewInfo, error)
+	SaveReview(context.Context, *model.ReviewInfo, ...*event.Envelope) (*model.ReviewInfo, error)
 	Update(context.Context, *Reviewer) (*Reviewer, error)
 	GetReviewByOrderID(context.Context, int64) ([]*model.ReviewInfo, error)
 	ListByHello(context.Context, string) ([]*Reviewer, error)
 	ListAll(context.Context) ([]*Reviewer, error)
-	DeleteReview(context.Context, int64) error
-	GetReviewByID(context.Context, int64) (*model.ReviewInfo, error)
+	// DeleteReview 按 review_id 逻辑删除，review.StoreID 为评价所在的分片
+	DeleteReview(context.Context, *model.ReviewInfo) error
+	// GetReviewByID 按主键查询 storeID 所在分片中的评价，开启分表后主键只在分片内唯一
+	GetReviewByID(ctx context.Context, storeID int64, ID int64) (*model.ReviewInfo, error)
+	// ListReviewByID 按主键在所有分片上查询，开启分表后不同分片中的评价可能有相同的主键
+	ListReviewByID(ctx context.Context, ID int64) ([]*model.ReviewInfo, error)
 	GetReviewByReviewID(context.Context, int64) ([]*model.ReviewInfo, error)
 	UpdateReviewByReviewID(context.Context, *model.ReviewInfo) (int64, error)
+	AuditReview(context.Context, *model.ReviewInfo, ...*event.Envelope) error
 	GetReviewByUID(context.Context, int64) ([]*model.ReviewInfo, error)
 	AddReviewReply(context.Context, *model.ReviewReplyInfo) (int64, error)
 	AddAppealReview(context.Context, *model.ReviewAppealInfo) (int64, error)
 	GetAppealByReviewID(context.Context, int64) ([]*model.ReviewAppealInfo, error)
-	UpdateAppealByAppealID(context.Context, *model.ReviewAppealInfo) (*model.ReviewAppealInfo, error)
+	UpdateAppealByAppealID(context.Context, *model.ReviewAppealInfo, ...*event.Envelope) (*model.ReviewAppealInfo, error)
 	GetAppealByAppealID(context.Context, int64) ([]*model.ReviewAppealInfo, error)
 	ListReviewByStoreID(ctx context.Context, storeID int64, offset int32, limit int32) ([]*MyReviewInfo, error)
+	SearchReviews(ctx context.Context, param *SearchParam) (*SearchResult, error)
 }
 
 // ReviewerUsecase is a Reviewer usecase.
 type ReviewerUsecase struct {
-	repo ReviewerRepo
-	sf   *snowflake.Snowflake
-	log  *log.Helper
+	repo  ReviewerRepo
+	queue ReviewQueue
+	sf    *snowflake.Snowflake
+	log   *log.Helper
 }
 
 // NewReviewerUsecase new a Reviewer usecase.
-func NewReviewerUsecase(repo ReviewerRepo, sf *snowflake.Snowflake, logger log.Logger) *ReviewerUsecase {
-	return &ReviewerUsecase{repo: repo, sf: sf, log: log.NewHelper(logger)}
+// queue 为 nil 时不支持异步创建
+func NewReviewerUsecase(repo ReviewerRepo, queue ReviewQueue, sf *snowflake.Snowflake, logger log.Logger) *ReviewerUsecase {
+	return &ReviewerUsecase{repo: repo, queue: queue, sf: sf, log: log.NewHelper(logger)}
 }
 
 // CreateReviewer creates a Reviewer, and returns the new Reviewer.
 func (uc *ReviewerUsecase) CreateReviewer(ctx context.Context, review *model.ReviewInfo) (*model.ReviewInfo, error) {
-	// 数据校验
-	reviews, err := uc.repo.GetReviewByOrderID(ctx, review.OrderID)
+	if err := uc.checkOrder(ctx, review.OrderID); err != nil {
+		return nil, err
+	}
+	// 生成 ID
+	// 使用雪花算法生成 ID
+	reviewID, err := uc.sf.NextID()
+	if err != nil {
+		return nil, err
+	}
+	review.ReviewID = reviewID
+	uc.log.WithContext(ctx).Infof("[biz] CreateReviewer ID: %v", review.ReviewID)
+	ev, err := uc.reviewCreated(review)
+	if err != nil {
+		return nil, err
+	}
+	return uc.repo.SaveReview(ctx, review, ev)
+}
+
+// checkOrder 数据校验，走主库避免从库延迟导致重复评价
+func (uc *ReviewerUsecase) checkOrder(ctx context.Context, orderID int64) error {
+	reviews, err := uc.repo.GetReviewByOrderID(WithPrimary(ctx), orderID)
 	if err != nil {
-		return nil, v1.ErrorDbFailed("DB search error!")
+		return v1.ErrorDbFailed("DB search error!")
 	}
 	// 当前 Order 已经被评价
 	if len(reviews) > 0 {
-		return nil, v1.ErrorOrderReviewed("order id %d already exist a review", review.OrderID)
+		return v1.ErrorOrderReviewed("order id %d already exist a review", orderID)
 	}
-	// 生成 ID
-	// 使用雪花算法生成 ID
-	review.ReviewID = uc.sf.NextID()
-	uc.log.WithContext(ctx).Infof("[biz] CreateReviewer ID: %v", review.ReviewID)
-	return uc.repo.SaveReview(ctx, review)
+	return nil
 }
 
 // 删除一个评论业务逻辑
-func (uc *ReviewerUsecase) DeleteReviewer(ctx context.Context, ID int64) error {
-	data, err := uc.repo.GetReviewByID(ctx, ID)
+// 按 review_id 查出评价所在的店铺后只在它的分片上删除，主键只在分片内唯一，不能用来定位评价
+func (uc *ReviewerUsecase) DeleteReviewer(ctx context.Context, reviewID int64) error {
+	rv, err := uc.repo.GetReviewByReviewID(WithPrimary(ctx), reviewID)
 	if err != nil {
 		return err
 	}
-	if data == nil {
-		return v1.ErrorIdErr("Do not exist ID: %v", ID)
-	} else if data.DeleteAt != nil {
-		return v1.ErrorReviewHasBeenDeleted("Has been Delete ID: %v", ID)
+	if len(rv) == 0 {
+		return v1.ErrorReviewidErr("Do not exist ReviewID: %v", reviewID)
+	} else if rv[0].DeleteAt != nil {
+		return v1.ErrorReviewHasBeenDeleted("Has been Delete ReviewID: %v", reviewID)
 	}
 
-	return uc.repo.DeleteReview(ctx, ID)
+	return uc.repo.DeleteReview(ctx, rv[0])
+}
+
+// DeleteReviewByID 按主键删除评价，兼容只传主键的调用方，返回被删除评价的 reviewID
+// 开启分表后主键只在分片内唯一，多个分片中都有该主键时无法确定评价，需要按 reviewID 删除
+func (uc *ReviewerUsecase) DeleteReviewByID(ctx context.Context, ID int64) (int64, error) {
+	rv, err := uc.repo.ListReviewByID(WithPrimary(ctx), ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(rv) == 0 {
+		return 0, v1.ErrorIdErr("Do not exist ID: %v", ID)
+	}
+	if len(rv) > 1 {
+		return 0, v1.ErrorIdErr("ID %v matches %d reviews in different shards, delete by ReviewID", ID, len(rv))
+	}
+	if rv[0].DeleteAt != nil {
+		return 0, v1.ErrorReviewHasBeenDeleted("Has been Delete ID: %v", ID)
+	}
+	return rv[0].ReviewID, uc.repo.DeleteReview(ctx, rv[0])
 }
 
 // 根据 reviewID 获取评论内容
@@ -96,7 +150,7 @@ func (uc *ReviewerUsecase) GetReviewByReviewID(ctx context.Context, reviewId int
 
 // 根据 reviewId 更新数据
 func (uc *ReviewerUsecase) UpdateReviewByReviewID(ctx context.Context, review *model.ReviewInfo) (int64, error) {
-	rv, err := uc.repo.GetReviewByReviewID(ctx, review.ReviewID)
+	rv, err := uc.repo.GetReviewByReviewID(WithPrimary(ctx), review.ReviewID)
 	if err != nil {
 		return 0, err
 	}
@@ -111,6 +165,34 @@ func (uc *ReviewerUsecase) UpdateReviewByReviewID(ctx context.Context, review *m
 	return reviewId, err
 }
 
+// O 端审核评价，审核通过时发布 review.approved 事件
+func (uc *ReviewerUsecase) AuditReview(ctx context.Context, review *model.ReviewInfo) error {
+	if review.Status != ReviewStatusApproved && review.Status != ReviewStatusRejected {
+		return errors.BadRequest("INVALID_ARGUMENT", "status must be 20 or 30")
+	}
+	rv, err := uc.repo.GetReviewByReviewID(WithPrimary(ctx), review.ReviewID)
+	if err != nil {
+		return err
+	}
+	if len(rv) == 0 {
+		return v1.ErrorReviewidErr("Do not exist ReviewID: %v", review.ReviewID)
+	}
+	if rv[0].DeleteAt != nil {
+		return v1.ErrorReviewidErr("The review has been delete: %v", review.ReviewID)
+	}
+	// 按评价所在的分片写入
+	review.StoreID = rv[0].StoreID
+	var events []*event.Envelope
+	if review.Status == ReviewStatusApproved && rv[0].Status != ReviewStatusApproved {
+		ev, err := uc.reviewApproved(rv[0], review.OpUser, review.OpRemarks)
+		if err != nil {
+			return err
+		}
+		events = append(events, ev)
+	}
+	return uc.repo.AuditReview(ctx, review, events...)
+}
+
 // 根据 uid 获取一个用户所有的评论
 func (uc *ReviewerUsecase) ListReviewByUid(ctx context.Context, uid int64) ([]*model.ReviewInfo, error) {
 	rvList, err := uc.repo.GetReviewByUID(ctx, uid)
@@ -123,10 +205,14 @@ func (uc *ReviewerUsecase) ListReviewByUid(ctx context.Context, uid int64) ([]*m
 // 商家对用户的评论进行回复
 func (uc *ReviewerUsecase) AddReplyReview(ctx context.Context, reply *model.ReviewReplyInfo) (int64, error) {
 	// 生成雪花 ID
-	reply.ReplyID = uc.sf.NextID()
+	replyID, err := uc.sf.NextID()
+	if err != nil {
+		return 0, err
+	}
+	reply.ReplyID = replyID
 	uc.log.WithContext(ctx).Infof("[biz] CreateReviewer ID: %v", reply.ReplyID)
-	// 获取需要回复的评论
-	reviewInfo, err := uc.repo.GetReviewByReviewID(ctx, reply.ReviewID)
+	// 获取需要回复的评论，与更新一样走主库，刚创建的评价在从库上可能还不存在
+	reviewInfo, err := uc.repo.GetReviewByReviewID(WithPrimary(ctx), reply.ReviewID)
 	if err != nil {
 		return 0, err
 	}
@@ -141,8 +227,8 @@ func (uc *ReviewerUsecase) AddReplyReview(ctx context.Context, reply *model.Revi
 
 // 商家对用户评论进行申诉
 func (uc *ReviewerUsecase) AppealReview(ctx context.Context, appeal *model.ReviewAppealInfo) (int64, error) {
-	// 1. 检查评论是否存在
-	reviewInfo, err := uc.repo.GetReviewByReviewID(ctx, appeal.ReviewID)
+	// 1. 检查评论是否存在，走主库
+	reviewInfo, err := uc.repo.GetReviewByReviewID(WithPrimary(ctx), appeal.ReviewID)
 	if err != nil {
 		return 0, err
 	}
@@ -155,7 +241,7 @@ func (uc *ReviewerUsecase) AppealReview(ctx context.Context, appeal *model.Revie
 		return 0, v1.ErrorStoreidReviewidMismatch("StoreID and Review's StoreID mismatch: %v - %v", appeal.StoreID, reviewInfo[0].StoreID)
 	}
 	// 4. 检查该评论是否已经被申诉过
-	existAppeal, err := uc.repo.GetAppealByReviewID(ctx, appeal.ReviewID)
+	existAppeal, err := uc.repo.GetAppealByReviewID(WithPrimary(ctx), appeal.ReviewID)
 	if err != nil {
 		return 0, err
 	}
@@ -165,7 +251,10 @@ func (uc *ReviewerUsecase) AppealReview(ctx context.Context, appeal *model.Revie
 
 	// 主逻辑：插入一条申诉记录
 	// 生成雪花 ID
-	appeal.AppealID = uc.sf.NextID()
+	appeal.AppealID, err = uc.sf.NextID()
+	if err != nil {
+		return 0, err
+	}
 	uc.log.WithContext(ctx).Infof("[biz] CreateReviewer ID: %v", appeal.AppealID)
 	review, err := uc.repo.AddAppealReview(ctx, appeal)
 	if err != nil {
@@ -177,7 +266,7 @@ func (uc *ReviewerUsecase) AppealReview(ctx context.Context, appeal *model.Revie
 // O 端处理申诉
 func (uc *ReviewerUsecase) HandleAppeal(ctx context.Context, info *model.ReviewAppealInfo) (*model.ReviewAppealInfo, error) {
 	// 1. 检查申诉是否存在
-	existAppeal, err := uc.repo.GetAppealByAppealID(ctx, info.AppealID)
+	existAppeal, err := uc.repo.GetAppealByAppealID(WithPrimary(ctx), info.AppealID)
 	if err != nil {
 		return &model.ReviewAppealInfo{}, err
 	}
@@ -189,8 +278,16 @@ func (uc *ReviewerUsecase) HandleAppeal(ctx context.Context, info *model.ReviewA
 		return &model.ReviewAppealInfo{}, v1.ErrorErrorAppealExists("AppealID and ID mismatch: %v - %v", info.ID, existAppeal[0].ID)
 	}
 
-	// 业务主逻辑
-	data, err := uc.repo.UpdateAppealByAppealID(ctx, info)
+	// 处理结果和 appeal.decided 事件一起写入
+	var events []*event.Envelope
+	if info.Status == AppealStatusApproved || info.Status == AppealStatusRejected {
+		ev, err := uc.appealDecided(existAppeal[0], info)
+		if err != nil {
+			return &model.ReviewAppealInfo{}, err
+		}
+		events = append(events, ev)
+	}
+	data, err := uc.repo.UpdateAppealByAppealID(ctx, info, events...)
 	if err != nil {
 		return &model.ReviewAppealInfo{}, err
 	}
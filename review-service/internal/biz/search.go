@@ -0,0 +1,54 @@
+package biz
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+const (
+	defaultSearchSize = 10
+	maxSearchSize     = 50
+)
+
+// SearchParam 评价全文搜索条件
+type SearchParam struct {
+	Query    string
+	StoreID  int64
+	SpuID    int64
+	MinScore int32  // 最低评分，0 表示不限
+	HasMedia bool   // 只看有图或视频的评价
+	Cursor   string // 上一页返回的游标，为空时从第一页开始
+	Size     int32
+}
+
+// SearchHit 一条搜索结果
+type SearchHit struct {
+	*MyReviewInfo
+	Highlight []string // 命中关键词的内容片段，关键词用 <em></em> 包裹
+	Relevance float64  // 相关度与时间衰减的综合得分
+}
+
+// SearchResult 搜索结果，NextCursor 为空说明没有下一页
+type SearchResult struct {
+	Hits       []*SearchHit
+	Total      int64
+	NextCursor string
+}
+
+// SearchReviews 按关键词搜索店铺或商品下的评价
+func (uc *ReviewerUsecase) SearchReviews(ctx context.Context, param *SearchParam) (*SearchResult, error) {
+	param.Query = strings.TrimSpace(param.Query)
+	if param.Query == "" {
+		return nil, errors.BadRequest("INVALID_ARGUMENT", "query is required")
+	}
+	if param.StoreID <= 0 && param.SpuID <= 0 {
+		return nil, errors.BadRequest("INVALID_ARGUMENT", "store_id or spu_id is required")
+	}
+	if param.Size <= 0 || param.Size > maxSearchSize {
+		param.Size = defaultSearchSize
+	}
+	uc.log.WithContext(ctx).Debugf("[biz] SearchReviews query: %s, store: %d, spu: %d", param.Query, param.StoreID, param.SpuID)
+	return uc.repo.SearchReviews(ctx, param)
+}
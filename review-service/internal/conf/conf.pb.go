@@ -140,6 +140,10 @@ type Data struct {
 	Redis         *Data_Redis            `protobuf:"bytes,2,opt,name=redis,proto3" json:"redis,omitempty"`
 	Snowflake     *Data_Snowflake        `protobuf:"bytes,3,opt,name=snowflake,proto3" json:"snowflake,omitempty"`
 	Elasticsearch *Data_Elasticsearch    `protobuf:"bytes,4,opt,name=elasticsearch,proto3" json:"elasticsearch,omitempty"`
+	Cache         *Data_Cache            `protobuf:"bytes,5,opt,name=cache,proto3" json:"cache,omitempty"`
+	Sharding      *Data_Sharding         `protobuf:"bytes,6,opt,name=sharding,proto3" json:"sharding,omitempty"`
+	Search        *Data_Search           `protobuf:"bytes,7,opt,name=search,proto3" json:"search,omitempty"`
+	AsyncCreate   *Data_AsyncCreate      `protobuf:"bytes,8,opt,name=async_create,json=asyncCreate,proto3" json:"async_create,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -202,6 +206,34 @@ func (x *Data) GetElasticsearch() *Data_Elasticsearch {
 	return nil
 }
 
+func (x *Data) GetCache() *Data_Cache {
+	if x != nil {
+		return x.Cache
+	}
+	return nil
+}
+
+func (x *Data) GetSharding() *Data_Sharding {
+	if x != nil {
+		return x.Sharding
+	}
+	return nil
+}
+
+func (x *Data) GetSearch() *Data_Search {
+	if x != nil {
+		return x.Search
+	}
+	return nil
+}
+
+func (x *Data) GetAsyncCreate() *Data_AsyncCreate {
+	if x != nil {
+		return x.AsyncCreate
+	}
+	return nil
+}
+
 type Registry struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Consul        *Registry_Consul       `protobuf:"bytes,1,opt,name=consul,proto3" json:"consul,omitempty"`
@@ -367,9 +399,17 @@ func (x *Server_GRPC) GetTimeout() *durationpb.Duration {
 }
 
 type Data_Database struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Driver        string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
-	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Driver string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
+	Source string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// 只读从库，配置后读操作通过 dbresolver 路由到从库
+	Replicas        []string             `protobuf:"bytes,3,rep,name=replicas,proto3" json:"replicas,omitempty"`
+	MaxOpenConns    int32                `protobuf:"varint,4,opt,name=max_open_conns,json=maxOpenConns,proto3" json:"max_open_conns,omitempty"`
+	MaxIdleConns    int32                `protobuf:"varint,5,opt,name=max_idle_conns,json=maxIdleConns,proto3" json:"max_idle_conns,omitempty"`
+	ConnMaxLifetime *durationpb.Duration `protobuf:"bytes,6,opt,name=conn_max_lifetime,json=connMaxLifetime,proto3" json:"conn_max_lifetime,omitempty"`
+	ConnMaxIdleTime *durationpb.Duration `protobuf:"bytes,7,opt,name=conn_max_idle_time,json=connMaxIdleTime,proto3" json:"conn_max_idle_time,omitempty"`
+	// 慢查询阈值
+	SlowThreshold *durationpb.Duration `protobuf:"bytes,8,opt,name=slow_threshold,json=slowThreshold,proto3" json:"slow_threshold,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -418,6 +458,48 @@ func (x *Data_Database) GetSource() string {
 	return ""
 }
 
+func (x *Data_Database) GetReplicas() []string {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+func (x *Data_Database) GetMaxOpenConns() int32 {
+	if x != nil {
+		return x.MaxOpenConns
+	}
+	return 0
+}
+
+func (x *Data_Database) GetMaxIdleConns() int32 {
+	if x != nil {
+		return x.MaxIdleConns
+	}
+	return 0
+}
+
+func (x *Data_Database) GetConnMaxLifetime() *durationpb.Duration {
+	if x != nil {
+		return x.ConnMaxLifetime
+	}
+	return nil
+}
+
+func (x *Data_Database) GetConnMaxIdleTime() *durationpb.Duration {
+	if x != nil {
+		return x.ConnMaxIdleTime
+	}
+	return nil
+}
+
+func (x *Data_Database) GetSlowThreshold() *durationpb.Duration {
+	if x != nil {
+		return x.SlowThreshold
+	}
+	return nil
+}
+
 type Data_Redis struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
@@ -503,9 +585,15 @@ func (x *Data_Redis) GetDb() int64 {
 }
 
 type Data_Snowflake struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	WorkerID      int64                  `protobuf:"varint,1,opt,name=workerID,proto3" json:"workerID,omitempty"`
-	DataCenterID  int64                  `protobuf:"varint,2,opt,name=dataCenterID,proto3" json:"dataCenterID,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	WorkerID     int64                  `protobuf:"varint,1,opt,name=workerID,proto3" json:"workerID,omitempty"`
+	DataCenterID int64                  `protobuf:"varint,2,opt,name=dataCenterID,proto3" json:"dataCenterID,omitempty"`
+	// 可容忍的时钟回拨时间
+	MaxBackwards *durationpb.Duration `protobuf:"bytes,3,opt,name=max_backwards,json=maxBackwards,proto3" json:"max_backwards,omitempty"`
+	// 开启后从 Redis 租用 workerID / dataCenterID，忽略上面的静态配置
+	Lease         bool                 `protobuf:"varint,4,opt,name=lease,proto3" json:"lease,omitempty"`
+	LeaseTtl      *durationpb.Duration `protobuf:"bytes,5,opt,name=lease_ttl,json=leaseTtl,proto3" json:"lease_ttl,omitempty"`
+	LeasePrefix   string               `protobuf:"bytes,6,opt,name=lease_prefix,json=leasePrefix,proto3" json:"lease_prefix,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -554,10 +642,42 @@ func (x *Data_Snowflake) GetDataCenterID() int64 {
 	return 0
 }
 
+func (x *Data_Snowflake) GetMaxBackwards() *durationpb.Duration {
+	if x != nil {
+		return x.MaxBackwards
+	}
+	return nil
+}
+
+func (x *Data_Snowflake) GetLease() bool {
+	if x != nil {
+		return x.Lease
+	}
+	return false
+}
+
+func (x *Data_Snowflake) GetLeaseTtl() *durationpb.Duration {
+	if x != nil {
+		return x.LeaseTtl
+	}
+	return nil
+}
+
+func (x *Data_Snowflake) GetLeasePrefix() string {
+	if x != nil {
+		return x.LeasePrefix
+	}
+	return ""
+}
+
 type Data_Elasticsearch struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Addr          []string               `protobuf:"bytes,1,rep,name=addr,proto3" json:"addr,omitempty"`
-	Index         string                 `protobuf:"bytes,2,opt,name=index,proto3" json:"index,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Addr  []string               `protobuf:"bytes,1,rep,name=addr,proto3" json:"addr,omitempty"`
+	Index string                 `protobuf:"bytes,2,opt,name=index,proto3" json:"index,omitempty"`
+	// 搜索评价内容时使用的中文分词器，如 ik_smart；为空时使用字段本身的分词器
+	Analyzer string `protobuf:"bytes,3,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	// 搜索结果按创建时间衰减的尺度，如 30d
+	RecencyScale  string `protobuf:"bytes,4,opt,name=recency_scale,json=recencyScale,proto3" json:"recency_scale,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -606,6 +726,350 @@ func (x *Data_Elasticsearch) GetIndex() string {
 	return ""
 }
 
+func (x *Data_Elasticsearch) GetAnalyzer() string {
+	if x != nil {
+		return x.Analyzer
+	}
+	return ""
+}
+
+func (x *Data_Elasticsearch) GetRecencyScale() string {
+	if x != nil {
+		return x.RecencyScale
+	}
+	return ""
+}
+
+type Data_Cache struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 空结果缓存时间，防止缓存穿透
+	NullTtl *durationpb.Duration `protobuf:"bytes,1,opt,name=null_ttl,json=nullTtl,proto3" json:"null_ttl,omitempty"`
+	// 是否在查库前检查布隆过滤器（由 review-job 根据 binlog 维护）
+	BloomEnabled   bool   `protobuf:"varint,2,opt,name=bloom_enabled,json=bloomEnabled,proto3" json:"bloom_enabled,omitempty"`
+	BloomReviewKey string `protobuf:"bytes,3,opt,name=bloom_review_key,json=bloomReviewKey,proto3" json:"bloom_review_key,omitempty"`
+	BloomStoreKey  string `protobuf:"bytes,4,opt,name=bloom_store_key,json=bloomStoreKey,proto3" json:"bloom_store_key,omitempty"`
+	// 过滤器已写入存量数据的标记，由 review-job 的 seed-bloom 写入，默认 bloom:ready；
+	// 标记不存在时过滤器中只有新数据，不检查过滤器
+	BloomReadyKey string `protobuf:"bytes,5,opt,name=bloom_ready_key,json=bloomReadyKey,proto3" json:"bloom_ready_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Cache) Reset() {
+	*x = Data_Cache{}
+	mi := &file_conf_conf_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Cache) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Cache) ProtoMessage() {}
+
+func (x *Data_Cache) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Cache.ProtoReflect.Descriptor instead.
+func (*Data_Cache) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{2, 4}
+}
+
+func (x *Data_Cache) GetNullTtl() *durationpb.Duration {
+	if x != nil {
+		return x.NullTtl
+	}
+	return nil
+}
+
+func (x *Data_Cache) GetBloomEnabled() bool {
+	if x != nil {
+		return x.BloomEnabled
+	}
+	return false
+}
+
+func (x *Data_Cache) GetBloomReviewKey() string {
+	if x != nil {
+		return x.BloomReviewKey
+	}
+	return ""
+}
+
+func (x *Data_Cache) GetBloomStoreKey() string {
+	if x != nil {
+		return x.BloomStoreKey
+	}
+	return ""
+}
+
+func (x *Data_Cache) GetBloomReadyKey() string {
+	if x != nil {
+		return x.BloomReadyKey
+	}
+	return ""
+}
+
+// 评价、回复、申诉表按 store_id 分表
+type Data_Sharding struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Enabled bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// 每张逻辑表的分表数量，物理表名为 review_info_00 ...
+	TableCount int32 `protobuf:"varint,2,opt,name=table_count,json=tableCount,proto3" json:"table_count,omitempty"`
+	// 分库 DSN，第 i 张分表位于 databases[i % len(databases)]；为空时全部位于主库
+	Databases []string `protobuf:"bytes,3,rep,name=databases,proto3" json:"databases,omitempty"`
+	// 分库的从库，replicas[i] 为 databases[i] 的从库；连接池、慢查询配置与 database 相同
+	Replicas      []*Data_Sharding_Replicas `protobuf:"bytes,4,rep,name=replicas,proto3" json:"replicas,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Sharding) Reset() {
+	*x = Data_Sharding{}
+	mi := &file_conf_conf_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Sharding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Sharding) ProtoMessage() {}
+
+func (x *Data_Sharding) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Sharding.ProtoReflect.Descriptor instead.
+func (*Data_Sharding) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{2, 5}
+}
+
+func (x *Data_Sharding) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Data_Sharding) GetTableCount() int32 {
+	if x != nil {
+		return x.TableCount
+	}
+	return 0
+}
+
+func (x *Data_Sharding) GetDatabases() []string {
+	if x != nil {
+		return x.Databases
+	}
+	return nil
+}
+
+func (x *Data_Sharding) GetReplicas() []*Data_Sharding_Replicas {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+// 搜索后端，本地开发可使用内嵌的 bleve，不需要启动 ES
+type Data_Search struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// elasticsearch（默认）或 bleve
+	Backend string `protobuf:"bytes,1,opt,name=backend,proto3" json:"backend,omitempty"`
+	// bleve 索引目录，为空时使用内存索引
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// 写评价时同步更新索引；bleve 不能与 review-job 共享同一个索引目录，本地开发时由服务自己维护索引
+	IndexOnWrite  bool `protobuf:"varint,3,opt,name=index_on_write,json=indexOnWrite,proto3" json:"index_on_write,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Search) Reset() {
+	*x = Data_Search{}
+	mi := &file_conf_conf_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Search) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Search) ProtoMessage() {}
+
+func (x *Data_Search) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Search.ProtoReflect.Descriptor instead.
+func (*Data_Search) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{2, 6}
+}
+
+func (x *Data_Search) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *Data_Search) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Data_Search) GetIndexOnWrite() bool {
+	if x != nil {
+		return x.IndexOnWrite
+	}
+	return false
+}
+
+// 异步创建评价：校验后把创建命令投递到 kafka 并立即返回，由 review-job 写入 MySQL，用于大促时削峰
+type Data_AsyncCreate struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Enabled bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Brokers []string               `protobuf:"bytes,2,rep,name=brokers,proto3" json:"brokers,omitempty"`
+	Topic   string                 `protobuf:"bytes,3,opt,name=topic,proto3" json:"topic,omitempty"`
+	// 创建进度在 redis 中保留的时间，默认 24h
+	StatusTtl     *durationpb.Duration `protobuf:"bytes,4,opt,name=status_ttl,json=statusTtl,proto3" json:"status_ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_AsyncCreate) Reset() {
+	*x = Data_AsyncCreate{}
+	mi := &file_conf_conf_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_AsyncCreate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_AsyncCreate) ProtoMessage() {}
+
+func (x *Data_AsyncCreate) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_AsyncCreate.ProtoReflect.Descriptor instead.
+func (*Data_AsyncCreate) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{2, 7}
+}
+
+func (x *Data_AsyncCreate) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Data_AsyncCreate) GetBrokers() []string {
+	if x != nil {
+		return x.Brokers
+	}
+	return nil
+}
+
+func (x *Data_AsyncCreate) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Data_AsyncCreate) GetStatusTtl() *durationpb.Duration {
+	if x != nil {
+		return x.StatusTtl
+	}
+	return nil
+}
+
+type Data_Sharding_Replicas struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dsn           []string               `protobuf:"bytes,1,rep,name=dsn,proto3" json:"dsn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Sharding_Replicas) Reset() {
+	*x = Data_Sharding_Replicas{}
+	mi := &file_conf_conf_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Sharding_Replicas) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Sharding_Replicas) ProtoMessage() {}
+
+func (x *Data_Sharding_Replicas) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Sharding_Replicas.ProtoReflect.Descriptor instead.
+func (*Data_Sharding_Replicas) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{2, 5, 0}
+}
+
+func (x *Data_Sharding_Replicas) GetDsn() []string {
+	if x != nil {
+		return x.Dsn
+	}
+	return nil
+}
+
 type Registry_Consul struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
@@ -616,7 +1080,7 @@ type Registry_Consul struct {
 
 func (x *Registry_Consul) Reset() {
 	*x = Registry_Consul{}
-	mi := &file_conf_conf_proto_msgTypes[10]
+	mi := &file_conf_conf_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -628,7 +1092,7 @@ func (x *Registry_Consul) String() string {
 func (*Registry_Consul) ProtoMessage() {}
 
 func (x *Registry_Consul) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_conf_proto_msgTypes[10]
+	mi := &file_conf_conf_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -678,28 +1142,68 @@ const file_conf_conf_proto_rawDesc = "" +
 	"\x04GRPC\x12\x18\n" +
 	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
 	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
-	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\x91\x05\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\xaa\x10\n" +
 	"\x04Data\x125\n" +
 	"\bdatabase\x18\x01 \x01(\v2\x19.kratos.api.Data.DatabaseR\bdatabase\x12,\n" +
 	"\x05redis\x18\x02 \x01(\v2\x16.kratos.api.Data.RedisR\x05redis\x128\n" +
 	"\tsnowflake\x18\x03 \x01(\v2\x1a.kratos.api.Data.SnowflakeR\tsnowflake\x12D\n" +
-	"\relasticsearch\x18\x04 \x01(\v2\x1e.kratos.api.Data.ElasticsearchR\relasticsearch\x1a:\n" +
+	"\relasticsearch\x18\x04 \x01(\v2\x1e.kratos.api.Data.ElasticsearchR\relasticsearch\x12,\n" +
+	"\x05cache\x18\x05 \x01(\v2\x16.kratos.api.Data.CacheR\x05cache\x125\n" +
+	"\bsharding\x18\x06 \x01(\v2\x19.kratos.api.Data.ShardingR\bsharding\x12/\n" +
+	"\x06search\x18\a \x01(\v2\x17.kratos.api.Data.SearchR\x06search\x12?\n" +
+	"\fasync_create\x18\b \x01(\v2\x1c.kratos.api.Data.AsyncCreateR\vasyncCreate\x1a\xf3\x02\n" +
 	"\bDatabase\x12\x16\n" +
 	"\x06driver\x18\x01 \x01(\tR\x06driver\x12\x16\n" +
-	"\x06source\x18\x02 \x01(\tR\x06source\x1a\xdf\x01\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1a\n" +
+	"\breplicas\x18\x03 \x03(\tR\breplicas\x12$\n" +
+	"\x0emax_open_conns\x18\x04 \x01(\x05R\fmaxOpenConns\x12$\n" +
+	"\x0emax_idle_conns\x18\x05 \x01(\x05R\fmaxIdleConns\x12E\n" +
+	"\x11conn_max_lifetime\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\x0fconnMaxLifetime\x12F\n" +
+	"\x12conn_max_idle_time\x18\a \x01(\v2\x19.google.protobuf.DurationR\x0fconnMaxIdleTime\x12@\n" +
+	"\x0eslow_threshold\x18\b \x01(\v2\x19.google.protobuf.DurationR\rslowThreshold\x1a\xdf\x01\n" +
 	"\x05Redis\x12\x18\n" +
 	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
 	"\x04addr\x18\x02 \x01(\tR\x04addr\x12<\n" +
 	"\fread_timeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\vreadTimeout\x12>\n" +
 	"\rwrite_timeout\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\fwriteTimeout\x12\x1a\n" +
 	"\bpassword\x18\x05 \x01(\tR\bpassword\x12\x0e\n" +
-	"\x02db\x18\x06 \x01(\x03R\x02db\x1aK\n" +
+	"\x02db\x18\x06 \x01(\x03R\x02db\x1a\xfc\x01\n" +
 	"\tSnowflake\x12\x1a\n" +
 	"\bworkerID\x18\x01 \x01(\x03R\bworkerID\x12\"\n" +
-	"\fdataCenterID\x18\x02 \x01(\x03R\fdataCenterID\x1a9\n" +
+	"\fdataCenterID\x18\x02 \x01(\x03R\fdataCenterID\x12>\n" +
+	"\rmax_backwards\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\fmaxBackwards\x12\x14\n" +
+	"\x05lease\x18\x04 \x01(\bR\x05lease\x126\n" +
+	"\tlease_ttl\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\bleaseTtl\x12!\n" +
+	"\flease_prefix\x18\x06 \x01(\tR\vleasePrefix\x1az\n" +
 	"\rElasticsearch\x12\x12\n" +
 	"\x04addr\x18\x01 \x03(\tR\x04addr\x12\x14\n" +
-	"\x05index\x18\x02 \x01(\tR\x05index\"u\n" +
+	"\x05index\x18\x02 \x01(\tR\x05index\x12\x1a\n" +
+	"\banalyzer\x18\x03 \x01(\tR\banalyzer\x12#\n" +
+	"\rrecency_scale\x18\x04 \x01(\tR\frecencyScale\x1a\xdc\x01\n" +
+	"\x05Cache\x124\n" +
+	"\bnull_ttl\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\anullTtl\x12#\n" +
+	"\rbloom_enabled\x18\x02 \x01(\bR\fbloomEnabled\x12(\n" +
+	"\x10bloom_review_key\x18\x03 \x01(\tR\x0ebloomReviewKey\x12&\n" +
+	"\x0fbloom_store_key\x18\x04 \x01(\tR\rbloomStoreKey\x12&\n" +
+	"\x0fbloom_ready_key\x18\x05 \x01(\tR\rbloomReadyKey\x1a\xc1\x01\n" +
+	"\bSharding\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1f\n" +
+	"\vtable_count\x18\x02 \x01(\x05R\n" +
+	"tableCount\x12\x1c\n" +
+	"\tdatabases\x18\x03 \x03(\tR\tdatabases\x12>\n" +
+	"\breplicas\x18\x04 \x03(\v2\".kratos.api.Data.Sharding.ReplicasR\breplicas\x1a\x1c\n" +
+	"\bReplicas\x12\x10\n" +
+	"\x03dsn\x18\x01 \x03(\tR\x03dsn\x1a\\\n" +
+	"\x06Search\x12\x18\n" +
+	"\abackend\x18\x01 \x01(\tR\abackend\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12$\n" +
+	"\x0eindex_on_write\x18\x03 \x01(\bR\findexOnWrite\x1a\x91\x01\n" +
+	"\vAsyncCreate\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x18\n" +
+	"\abrokers\x18\x02 \x03(\tR\abrokers\x12\x14\n" +
+	"\x05topic\x18\x03 \x01(\tR\x05topic\x128\n" +
+	"\n" +
+	"status_ttl\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\tstatusTtl\"u\n" +
 	"\bRegistry\x123\n" +
 	"\x06consul\x18\x01 \x01(\v2\x1b.kratos.api.Registry.ConsulR\x06consul\x1a4\n" +
 	"\x06Consul\x12\x12\n" +
@@ -718,20 +1222,25 @@ func file_conf_conf_proto_rawDescGZIP() []byte {
 	return file_conf_conf_proto_rawDescData
 }
 
-var file_conf_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_conf_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_conf_conf_proto_goTypes = []any{
-	(*Bootstrap)(nil),           // 0: kratos.api.Bootstrap
-	(*Server)(nil),              // 1: kratos.api.Server
-	(*Data)(nil),                // 2: kratos.api.Data
-	(*Registry)(nil),            // 3: kratos.api.Registry
-	(*Server_HTTP)(nil),         // 4: kratos.api.Server.HTTP
-	(*Server_GRPC)(nil),         // 5: kratos.api.Server.GRPC
-	(*Data_Database)(nil),       // 6: kratos.api.Data.Database
-	(*Data_Redis)(nil),          // 7: kratos.api.Data.Redis
-	(*Data_Snowflake)(nil),      // 8: kratos.api.Data.Snowflake
-	(*Data_Elasticsearch)(nil),  // 9: kratos.api.Data.Elasticsearch
-	(*Registry_Consul)(nil),     // 10: kratos.api.Registry.Consul
-	(*durationpb.Duration)(nil), // 11: google.protobuf.Duration
+	(*Bootstrap)(nil),              // 0: kratos.api.Bootstrap
+	(*Server)(nil),                 // 1: kratos.api.Server
+	(*Data)(nil),                   // 2: kratos.api.Data
+	(*Registry)(nil),               // 3: kratos.api.Registry
+	(*Server_HTTP)(nil),            // 4: kratos.api.Server.HTTP
+	(*Server_GRPC)(nil),            // 5: kratos.api.Server.GRPC
+	(*Data_Database)(nil),          // 6: kratos.api.Data.Database
+	(*Data_Redis)(nil),             // 7: kratos.api.Data.Redis
+	(*Data_Snowflake)(nil),         // 8: kratos.api.Data.Snowflake
+	(*Data_Elasticsearch)(nil),     // 9: kratos.api.Data.Elasticsearch
+	(*Data_Cache)(nil),             // 10: kratos.api.Data.Cache
+	(*Data_Sharding)(nil),          // 11: kratos.api.Data.Sharding
+	(*Data_Search)(nil),            // 12: kratos.api.Data.Search
+	(*Data_AsyncCreate)(nil),       // 13: kratos.api.Data.AsyncCreate
+	(*Data_Sharding_Replicas)(nil), // 14: kratos.api.Data.Sharding.Replicas
+	(*Registry_Consul)(nil),        // 15: kratos.api.Registry.Consul
+	(*durationpb.Duration)(nil),    // 16: google.protobuf.Duration
 }
 var file_conf_conf_proto_depIdxs = []int32{
 	1,  // 0: kratos.api.Bootstrap.server:type_name -> kratos.api.Server
@@ -743,16 +1252,28 @@ var file_conf_conf_proto_depIdxs = []int32{
 	7,  // 6: kratos.api.Data.redis:type_name -> kratos.api.Data.Redis
 	8,  // 7: kratos.api.Data.snowflake:type_name -> kratos.api.Data.Snowflake
 	9,  // 8: kratos.api.Data.elasticsearch:type_name -> kratos.api.Data.Elasticsearch
-	10, // 9: kratos.api.Registry.consul:type_name -> kratos.api.Registry.Consul
-	11, // 10: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
-	11, // 11: kratos.api.Server.GRPC.timeout:type_name -> google.protobuf.Duration
-	11, // 12: kratos.api.Data.Redis.read_timeout:type_name -> google.protobuf.Duration
-	11, // 13: kratos.api.Data.Redis.write_timeout:type_name -> google.protobuf.Duration
-	14, // [14:14] is the sub-list for method output_type
-	14, // [14:14] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	10, // 9: kratos.api.Data.cache:type_name -> kratos.api.Data.Cache
+	11, // 10: kratos.api.Data.sharding:type_name -> kratos.api.Data.Sharding
+	12, // 11: kratos.api.Data.search:type_name -> kratos.api.Data.Search
+	13, // 12: kratos.api.Data.async_create:type_name -> kratos.api.Data.AsyncCreate
+	15, // 13: kratos.api.Registry.consul:type_name -> kratos.api.Registry.Consul
+	16, // 14: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
+	16, // 15: kratos.api.Server.GRPC.timeout:type_name -> google.protobuf.Duration
+	16, // 16: kratos.api.Data.Database.conn_max_lifetime:type_name -> google.protobuf.Duration
+	16, // 17: kratos.api.Data.Database.conn_max_idle_time:type_name -> google.protobuf.Duration
+	16, // 18: kratos.api.Data.Database.slow_threshold:type_name -> google.protobuf.Duration
+	16, // 19: kratos.api.Data.Redis.read_timeout:type_name -> google.protobuf.Duration
+	16, // 20: kratos.api.Data.Redis.write_timeout:type_name -> google.protobuf.Duration
+	16, // 21: kratos.api.Data.Snowflake.max_backwards:type_name -> google.protobuf.Duration
+	16, // 22: kratos.api.Data.Snowflake.lease_ttl:type_name -> google.protobuf.Duration
+	16, // 23: kratos.api.Data.Cache.null_ttl:type_name -> google.protobuf.Duration
+	14, // 24: kratos.api.Data.Sharding.replicas:type_name -> kratos.api.Data.Sharding.Replicas
+	16, // 25: kratos.api.Data.AsyncCreate.status_ttl:type_name -> google.protobuf.Duration
+	26, // [26:26] is the sub-list for method output_type
+	26, // [26:26] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_conf_conf_proto_init() }
@@ -766,7 +1287,7 @@ func file_conf_conf_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_conf_conf_proto_rawDesc), len(file_conf_conf_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
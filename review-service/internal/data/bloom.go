@@ -0,0 +1,108 @@
+package data
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"review-service/internal/conf"
+)
+
+const (
+	defaultNullTTL        = time.Minute
+	defaultBloomReviewKey = "bloom:review_id"
+	defaultBloomStoreKey  = "bloom:store_id"
+	defaultBloomReadyKey  = "bloom:ready"
+
+	// nullCacheValue 空结果占位值
+	nullCacheValue = "null"
+)
+
+// cacheOption 缓存穿透相关配置
+type cacheOption struct {
+	nullTTL        time.Duration
+	bloomEnabled   bool
+	bloomReviewKey string
+	bloomStoreKey  string
+	bloomReadyKey  string
+}
+
+func newCacheOption(c *conf.Data_Cache) *cacheOption {
+	opt := &cacheOption{
+		nullTTL:        defaultNullTTL,
+		bloomReviewKey: defaultBloomReviewKey,
+		bloomStoreKey:  defaultBloomStoreKey,
+		bloomReadyKey:  defaultBloomReadyKey,
+	}
+	if c == nil {
+		return opt
+	}
+	if c.NullTtl != nil && c.NullTtl.AsDuration() > 0 {
+		opt.nullTTL = c.NullTtl.AsDuration()
+	}
+	if c.BloomReviewKey != "" {
+		opt.bloomReviewKey = c.BloomReviewKey
+	}
+	if c.BloomStoreKey != "" {
+		opt.bloomStoreKey = c.BloomStoreKey
+	}
+	if c.BloomReadyKey != "" {
+		opt.bloomReadyKey = c.BloomReadyKey
+	}
+	opt.bloomEnabled = c.BloomEnabled
+	return opt
+}
+
+// mightExist 查询布隆过滤器，返回 false 说明该 ID 一定不存在
+// 未开启布隆过滤器、review-job 还没有写入存量数据（没有 ready 标记）或 Redis 出错时放行，交给后续缓存和数据库判断
+func (r *ReviewerRepo) mightExist(ctx context.Context, key string, id int64) bool {
+	if !r.data.cache.bloomEnabled {
+		return true
+	}
+	pipe := r.data.redis.Pipeline()
+	ready := pipe.Exists(ctx, r.data.cache.bloomReadyKey)
+	exists := pipe.Do(ctx, "BF.EXISTS", key, strconv.FormatInt(id, 10))
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.log.WithContext(ctx).Warnf("bloom filter check failed, key: %s, err: %v", key, err)
+		return true
+	}
+	if ready.Val() == 0 {
+		return true
+	}
+	ok, err := exists.Bool()
+	return err != nil || ok
+}
+
+// addToBloom 新写入的数据立即加入布隆过滤器，避免等待 review-job 同步期间被误拦截
+func (r *ReviewerRepo) addToBloom(ctx context.Context, key string, id int64) {
+	if !r.data.cache.bloomEnabled {
+		return
+	}
+	if err := r.data.redis.Do(ctx, "BF.ADD", key, strconv.FormatInt(id, 10)).Err(); err != nil {
+		r.log.WithContext(ctx).Warnf("bloom filter add failed, key: %s, err: %v", key, err)
+	}
+}
+
+// nullCacheKey 空结果缓存的 key
+// key: review_null:reviewID
+func nullCacheKey(reviewID int64) string {
+	return "review_null:" + strconv.FormatInt(reviewID, 10)
+}
+
+// isNullCached 判断该 reviewID 是否命中空结果缓存
+func (r *ReviewerRepo) isNullCached(ctx context.Context, reviewID int64) bool {
+	v, err := r.data.redis.Get(ctx, nullCacheKey(reviewID)).Result()
+	return err == nil && v == nullCacheValue
+}
+
+func (r *ReviewerRepo) setNullCache(ctx context.Context, reviewID int64) {
+	if err := r.data.redis.Set(ctx, nullCacheKey(reviewID), nullCacheValue, r.data.cache.nullTTL).Err(); err != nil {
+		r.log.WithContext(ctx).Warnf("set null cache failed, reviewID: %v, err: %v", reviewID, err)
+	}
+}
+
+func (r *ReviewerRepo) delNullCache(ctx context.Context, reviewID int64) {
+	if err := r.data.redis.Del(ctx, nullCacheKey(reviewID)).Err(); err != nil {
+		r.log.WithContext(ctx).Warnf("delete null cache failed, reviewID: %v, err: %v", reviewID, err)
+	}
+}
@@ -2,41 +2,51 @@ package data
 
 import (
 	"context"
-	"github.com/elastic/go-elasticsearch/v8"
+	"fmt"
 	"github.com/go-redis/redis/v8"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 	"review-service/internal/conf"
 	"review-service/internal/data/query"
+	"review-service/pkg/search"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/google/wire"
 )
 
 // ProviderSet is data providers.
-var ProviderSet = wire.NewSet(NewData, NewReviewerRepo, NewDB, NewRedis, NewESClient)
+var ProviderSet = wire.NewSet(NewData, NewReviewerRepo, NewReviewQueue, NewDB, NewRedis, NewSearchIndex, NewSnowflake, NewSharding)
 
 // Data .
 type Data struct {
 	// TODO wrapped database client
 	//db    *gorm.DB
-	query *query.Query
-	redis *redis.Client
-	log   *log.Helper
-	es    *elasticsearch.TypedClient
+	query  *query.Query
+	shards *Sharding
+	redis  *redis.Client
+	log    *log.Helper
+	index  search.Index
+	cache  *cacheOption
+	search *searchOption
 }
 
 // NewData .
-func NewData(db *gorm.DB, redis *redis.Client, esClient *elasticsearch.TypedClient, c *conf.Data, logger log.Logger) (*Data, func(), error) {
+func NewData(db *gorm.DB, shards *Sharding, redis *redis.Client, index search.Index, c *conf.Data, logger log.Logger) (*Data, func(), error) {
 	// 为生成的代码制定对象
 	query.SetDefault(db)
 
 	// 创建返回数据库连接实例
 	dbInstance := &Data{
-		query: query.Q,
-		redis: redis,
-		log:   log.NewHelper(logger),
-		es:    esClient,
+		query:  query.Q,
+		shards: shards,
+		redis:  redis,
+		log:    log.NewHelper(logger),
+		index:  index,
+		cache:  newCacheOption(c.Cache),
+		search: newSearchOption(c),
 	}
 
 	// 关闭连接
@@ -51,15 +61,66 @@ func NewData(db *gorm.DB, redis *redis.Client, esClient *elasticsearch.TypedClie
 
 // 连接 MySQL
 func NewDB(c *conf.Data, logger log.Logger) (*gorm.DB, error) {
+	return OpenDB(c.Database, c.Database.Source, c.Database.Replicas, logger)
+}
+
+// OpenDB 连接 source 及其从库 replicas，连接池和慢查询配置取自 c
+// 主库和分库使用相同的配置，读写分离都由 dbresolver 完成
+func OpenDB(c *conf.Data_Database, source string, replicaDSNs []string, logger log.Logger) (*gorm.DB, error) {
 	helper := log.NewHelper(logger)
-	db, err := gorm.Open(mysql.Open(c.Database.Source), &gorm.Config{})
+	slowThreshold := 200 * time.Millisecond
+	if c.GetSlowThreshold() != nil {
+		slowThreshold = c.SlowThreshold.AsDuration()
+	}
+	db, err := gorm.Open(mysql.Open(source), &gorm.Config{
+		Logger: gormLogger.New(gormWriter{helper}, gormLogger.Config{
+			SlowThreshold:             slowThreshold,
+			LogLevel:                  gormLogger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+	})
 	if err != nil {
 		return nil, err
 	}
-	helper.Infof("connect mysql success: %s", c.Database.Source)
+
+	// 配置从库，读写分离
+	replicas := make([]gorm.Dialector, 0, len(replicaDSNs))
+	for _, dsn := range replicaDSNs {
+		replicas = append(replicas, mysql.Open(dsn))
+	}
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	})
+	if err := db.Use(resolver); err != nil {
+		return nil, err
+	}
+	// 连接池配置，同时作用于主库和从库，需要在注册插件之后设置
+	if c.GetMaxOpenConns() > 0 {
+		resolver.SetMaxOpenConns(int(c.MaxOpenConns))
+	}
+	if c.GetMaxIdleConns() > 0 {
+		resolver.SetMaxIdleConns(int(c.MaxIdleConns))
+	}
+	if c.GetConnMaxLifetime() != nil {
+		resolver.SetConnMaxLifetime(c.ConnMaxLifetime.AsDuration())
+	}
+	if c.GetConnMaxIdleTime() != nil {
+		resolver.SetConnMaxIdleTime(c.ConnMaxIdleTime.AsDuration())
+	}
+	helper.Infof("connect mysql success: %s, replicas: %d", source, len(replicas))
 	return db, nil
 }
 
+// gormWriter 将 gorm 日志（包括慢查询）输出到 kratos logger
+type gormWriter struct {
+	helper *log.Helper
+}
+
+func (w gormWriter) Printf(format string, args ...interface{}) {
+	w.helper.Warn(fmt.Sprintf(format, args...))
+}
+
 // 连接 Redis
 // NewRedis creates a new redis.Client instance.
 func NewRedis(c *conf.Data, logger log.Logger) (*redis.Client, error) {
@@ -75,14 +136,3 @@ func NewRedis(c *conf.Data, logger log.Logger) (*redis.Client, error) {
 	helper.Infof("connect redis success: %s", c.Redis.Addr)
 	return rdb, nil
 }
-
-// ESClient 构造函数
-func NewESClient(c *conf.Data, logger log.Logger) (*elasticsearch.TypedClient, error) {
-	//helper := log.NewHelper(logger)
-	// ES 配置
-	cfg := elasticsearch.Config{
-		Addresses: c.Elasticsearch.Addr,
-	}
-
-	return elasticsearch.NewTypedClient(cfg)
-}
@@ -0,0 +1,342 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	v1 "review-service/api/review/v1"
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/pkg/event"
+)
+
+// ReviewerRepo biz.ReviewerRepo 的内存实现
+// 用于单元测试和不依赖 MySQL/Redis/ES 的本地开发，行为与 data.ReviewerRepo 保持一致，
+// 两者通过 repotest 中的契约用例校验
+type ReviewerRepo struct {
+	mu      sync.RWMutex
+	nextID  int64
+	reviews []*model.ReviewInfo
+	replies []*model.ReviewReplyInfo
+	appeals []*model.ReviewAppealInfo
+	events  []*event.Envelope
+}
+
+func NewReviewerRepo() *ReviewerRepo {
+	return &ReviewerRepo{}
+}
+
+var _ biz.ReviewerRepo = (*ReviewerRepo)(nil)
+
+// autoID 模拟数据库自增主键
+func (r *ReviewerRepo) autoID() int64 {
+	r.nextID++
+	return r.nextID
+}
+
+// Events 按写入顺序返回发件箱中的事件
+func (r *ReviewerRepo) Events() []*event.Envelope {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*event.Envelope(nil), r.events...)
+}
+
+func (r *ReviewerRepo) SaveReview(ctx context.Context, review *model.ReviewInfo, events ...*event.Envelope) (*model.ReviewInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, events...)
+	if review.ID == 0 {
+		review.ID = r.autoID()
+		r.reviews = append(r.reviews, clone(review))
+		return review, nil
+	}
+	for i, rv := range r.reviews {
+		if rv.ID == review.ID {
+			r.reviews[i] = clone(review)
+			return review, nil
+		}
+	}
+	r.reviews = append(r.reviews, clone(review))
+	return review, nil
+}
+
+func (r *ReviewerRepo) Update(ctx context.Context, g *biz.Reviewer) (*biz.Reviewer, error) {
+	return g, nil
+}
+
+func (r *ReviewerRepo) GetReviewByOrderID(ctx context.Context, orderID int64) ([]*model.ReviewInfo, error) {
+	return r.findReviews(func(rv *model.ReviewInfo) bool { return rv.OrderID == orderID }), nil
+}
+
+func (r *ReviewerRepo) ListByHello(context.Context, string) ([]*biz.Reviewer, error) {
+	return nil, nil
+}
+
+func (r *ReviewerRepo) ListAll(context.Context) ([]*biz.Reviewer, error) {
+	return nil, nil
+}
+
+// DeleteReview 按 review_id 逻辑删除
+func (r *ReviewerRepo) DeleteReview(ctx context.Context, review *model.ReviewInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, rv := range r.reviews {
+		if rv.ReviewID == review.ReviewID {
+			rv.DeleteAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *ReviewerRepo) GetReviewByID(ctx context.Context, storeID int64, ID int64) (*model.ReviewInfo, error) {
+	info := r.findReviews(func(rv *model.ReviewInfo) bool { return rv.ID == ID && rv.StoreID == storeID })
+	if len(info) == 0 {
+		return nil, v1.ErrorIdErr("Do not exist ID: %v", ID)
+	}
+	return info[0], nil
+}
+
+func (r *ReviewerRepo) ListReviewByID(ctx context.Context, ID int64) ([]*model.ReviewInfo, error) {
+	return r.findReviews(func(rv *model.ReviewInfo) bool { return rv.ID == ID }), nil
+}
+
+func (r *ReviewerRepo) GetReviewByReviewID(ctx context.Context, reviewID int64) ([]*model.ReviewInfo, error) {
+	return r.findReviews(func(rv *model.ReviewInfo) bool { return rv.ReviewID == reviewID }), nil
+}
+
+// UpdateReviewByReviewID 只更新用户可修改的非零字段，与 gorm Updates 的语义一致
+func (r *ReviewerRepo) UpdateReviewByReviewID(ctx context.Context, rv *model.ReviewInfo) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, old := range r.reviews {
+		if old.ReviewID != rv.ReviewID {
+			continue
+		}
+		setString(&old.Content, rv.Content)
+		setInt32(&old.Score, rv.Score)
+		setInt32(&old.ServiceScore, rv.ServiceScore)
+		setInt32(&old.ExpressScore, rv.ExpressScore)
+		setString(&old.PicInfo, rv.PicInfo)
+		setString(&old.VideoInfo, rv.VideoInfo)
+		setInt32(&old.Anonymous, rv.Anonymous)
+	}
+	return rv.ReviewID, nil
+}
+
+// AuditReview 更新审核结果的非零字段
+func (r *ReviewerRepo) AuditReview(ctx context.Context, review *model.ReviewInfo, events ...*event.Envelope) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, old := range r.reviews {
+		if old.ReviewID != review.ReviewID {
+			continue
+		}
+		setString(&old.UpdateBy, review.UpdateBy)
+		setInt32(&old.Status, review.Status)
+		setString(&old.OpReason, review.OpReason)
+		setString(&old.OpRemarks, review.OpRemarks)
+		setString(&old.OpUser, review.OpUser)
+	}
+	r.events = append(r.events, events...)
+	return nil
+}
+
+// GetReviewByUID 按创建时间排序
+func (r *ReviewerRepo) GetReviewByUID(ctx context.Context, uid int64) ([]*model.ReviewInfo, error) {
+	data := r.findReviews(func(rv *model.ReviewInfo) bool { return rv.UserID == uid })
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].CreateAt.Before(data[j].CreateAt)
+	})
+	return data, nil
+}
+
+func (r *ReviewerRepo) AddReviewReply(ctx context.Context, reply *model.ReviewReplyInfo) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var review *model.ReviewInfo
+	for _, rv := range r.reviews {
+		if rv.ReviewID == reply.ReviewID {
+			review = rv
+			break
+		}
+	}
+	if review == nil {
+		return 0, v1.ErrorReviewidErr("Do not exist ReviewID: %v", reply.ReviewID)
+	}
+	// 处理 StoreID 和评论不一致的情况
+	if review.StoreID != reply.StoreID {
+		return 0, v1.ErrorStoreidReviewidMismatch("Store ID mismatch with View's, StoreID: %v, View's StoreID: %v", reply.StoreID, review.StoreID)
+	}
+	reply.ID = r.autoID()
+	cp := *reply
+	r.replies = append(r.replies, &cp)
+	return reply.ReplyID, nil
+}
+
+func (r *ReviewerRepo) AddAppealReview(ctx context.Context, appeal *model.ReviewAppealInfo) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	appeal.ID = r.autoID()
+	cp := *appeal
+	r.appeals = append(r.appeals, &cp)
+	return appeal.AppealID, nil
+}
+
+func (r *ReviewerRepo) GetAppealByReviewID(ctx context.Context, reviewID int64) ([]*model.ReviewAppealInfo, error) {
+	return r.findAppeals(func(a *model.ReviewAppealInfo) bool { return a.ReviewID == reviewID }), nil
+}
+
+// UpdateAppealByAppealID 更新非零字段，与 gorm Updates 的语义一致
+func (r *ReviewerRepo) UpdateAppealByAppealID(ctx context.Context, appeal *model.ReviewAppealInfo, events ...*event.Envelope) (*model.ReviewAppealInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, old := range r.appeals {
+		if old.AppealID != appeal.AppealID {
+			continue
+		}
+		r.events = append(r.events, events...)
+		setString(&old.UpdateBy, appeal.UpdateBy)
+		setInt32(&old.Status, appeal.Status)
+		setString(&old.Reason, appeal.Reason)
+		setString(&old.Content, appeal.Content)
+		setString(&old.PicInfo, appeal.PicInfo)
+		setString(&old.VideoInfo, appeal.VideoInfo)
+		setString(&old.OpRemarks, appeal.OpRemarks)
+		setString(&old.OpUser, appeal.OpUser)
+		setString(&old.ExtJSON, appeal.ExtJSON)
+		setString(&old.CtrlJSON, appeal.CtrlJSON)
+	}
+	return appeal, nil
+}
+
+func (r *ReviewerRepo) GetAppealByAppealID(ctx context.Context, appealID int64) ([]*model.ReviewAppealInfo, error) {
+	return r.findAppeals(func(a *model.ReviewAppealInfo) bool { return a.AppealID == appealID }), nil
+}
+
+// ListReviewByStoreID 代替 ES 查询，按店铺分页返回评价
+func (r *ReviewerRepo) ListReviewByStoreID(ctx context.Context, storeID int64, offset int32, limit int32) ([]*biz.MyReviewInfo, error) {
+	data := r.findReviews(func(rv *model.ReviewInfo) bool { return rv.StoreID == storeID && visible(rv) })
+	if int(offset) >= len(data) {
+		return []*biz.MyReviewInfo{}, nil
+	}
+	data = data[offset:]
+	if int(limit) < len(data) {
+		data = data[:limit]
+	}
+	list := make([]*biz.MyReviewInfo, 0, len(data))
+	for _, rv := range data {
+		list = append(list, toMyReviewInfo(rv))
+	}
+	return list, nil
+}
+
+// SearchReviews 按内容子串匹配，可见性规则与 ES 实现一致，结果按创建时间倒序
+// 游标为下一页的起始下标
+func (r *ReviewerRepo) SearchReviews(ctx context.Context, param *biz.SearchParam) (*biz.SearchResult, error) {
+	data := r.findReviews(func(rv *model.ReviewInfo) bool {
+		switch {
+		case !visible(rv):
+			return false
+		case param.StoreID > 0 && rv.StoreID != param.StoreID:
+			return false
+		case param.SpuID > 0 && rv.SpuID != param.SpuID:
+			return false
+		case param.MinScore > 0 && rv.Score < param.MinScore:
+			return false
+		case param.HasMedia && rv.HasMedia != 1:
+			return false
+		}
+		return strings.Contains(rv.Content, param.Query)
+	})
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].CreateAt.After(data[j].CreateAt)
+	})
+
+	start := 0
+	if param.Cursor != "" {
+		n, err := strconv.Atoi(param.Cursor)
+		if err != nil || n < 0 {
+			return nil, errors.BadRequest("INVALID_ARGUMENT", "invalid cursor")
+		}
+		start = n
+	}
+	result := &biz.SearchResult{Hits: make([]*biz.SearchHit, 0), Total: int64(len(data))}
+	if start >= len(data) {
+		return result, nil
+	}
+	end := start + int(param.Size)
+	if end < len(data) {
+		result.NextCursor = strconv.Itoa(end)
+	} else {
+		end = len(data)
+	}
+	for _, rv := range data[start:end] {
+		result.Hits = append(result.Hits, &biz.SearchHit{
+			MyReviewInfo: toMyReviewInfo(rv),
+			Highlight:    []string{strings.ReplaceAll(rv.Content, param.Query, "<em>"+param.Query+"</em>")},
+		})
+	}
+	return result, nil
+}
+
+// findReviews 返回副本，调用方修改结果不影响存储
+func (r *ReviewerRepo) findReviews(match func(*model.ReviewInfo) bool) []*model.ReviewInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]*model.ReviewInfo, 0)
+	for _, rv := range r.reviews {
+		if match(rv) {
+			list = append(list, clone(rv))
+		}
+	}
+	return list
+}
+
+func (r *ReviewerRepo) findAppeals(match func(*model.ReviewAppealInfo) bool) []*model.ReviewAppealInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]*model.ReviewAppealInfo, 0)
+	for _, a := range r.appeals {
+		if match(a) {
+			cp := *a
+			list = append(list, &cp)
+		}
+	}
+	return list
+}
+
+// visible 列表和搜索的可见性规则：排除已删除、审核不通过(30)和隐藏(40)的评价
+func visible(rv *model.ReviewInfo) bool {
+	return rv.DeleteAt == nil && rv.Status != 30 && rv.Status != 40
+}
+
+func toMyReviewInfo(rv *model.ReviewInfo) *biz.MyReviewInfo {
+	return &biz.MyReviewInfo{ReviewInfo: rv}
+}
+
+func clone(rv *model.ReviewInfo) *model.ReviewInfo {
+	cp := *rv
+	if rv.DeleteAt != nil {
+		t := *rv.DeleteAt
+		cp.DeleteAt = &t
+	}
+	return &cp
+}
+
+func setString(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+func setInt32(dst *int32, v int32) {
+	if v != 0 {
+		*dst = v
+	}
+}
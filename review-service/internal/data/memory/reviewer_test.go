@@ -0,0 +1,13 @@
+package memory_test
+
+import (
+	"testing"
+
+	"review-service/internal/biz"
+	"review-service/internal/data/memory"
+	"review-service/internal/data/repotest"
+)
+
+func TestReviewerRepo(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) biz.ReviewerRepo { return memory.NewReviewerRepo() })
+}
@@ -1,12 +1,11 @@
-CREATE TABLE review_info (
+CREATE TABLE IF NOT EXISTS review_info (
     `id` bigint(32) unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
     `create_by` varchar(48) NOT NULL DEFAULT '' COMMENT '创建⽅标识',
     `update_by` varchar(48) NOT NULL DEFAULT '' COMMENT '更新⽅标识',
-    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间'
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     `update_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
-    `delete_at` timestamp COMMENT '逻辑删除标记',
+    `delete_at` timestamp NULL DEFAULT NULL COMMENT '逻辑删除标记',
     `version` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '乐观锁标记',
-
     `review_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '评价id',
     `content` varchar(512) NOT NULL COMMENT '评价内容',
     `score` tinyint(4) NOT NULL DEFAULT '0' COMMENT '评分',
@@ -22,14 +21,13 @@ CREATE TABLE review_info (
     `tags` varchar(1024) NOT NULL DEFAULT '' COMMENT '标签json',
     `pic_info` varchar(1024) NOT NULL DEFAULT '' COMMENT '媒体信息：图⽚',
     `video_info` varchar(1024) NOT NULL DEFAULT '' COMMENT '媒体信息：视频',
-    `status` tinyint(4) NOT NULL DEFAULT '10' COMMENT '状态:10待审核；20审核通过；30审核
-    不通过；40隐藏',
+    `status` tinyint(4) NOT NULL DEFAULT '10' COMMENT '状态:10待审核；20审核通过；30审核不通过；40隐藏',
     `is_default` tinyint(4) NOT NULL DEFAULT '0' COMMENT '是否默认评价',
     `has_reply` tinyint(4) NOT NULL DEFAULT '0' COMMENT '是否有商家回复:0⽆;1有',
     `op_reason` varchar(512) NOT NULL DEFAULT '' COMMENT '运营审核拒绝原因',
     `op_remarks` varchar(512) NOT NULL DEFAULT '' COMMENT '运营备注',
     `op_user` varchar(64) NOT NULL DEFAULT '' COMMENT '运营者标识',
-    `goods_snapshoot` varchar(2048) NOT NULL DEFAULT '' COMMENT '商品快照信息'
+    `goods_snapshoot` varchar(2048) NOT NULL DEFAULT '' COMMENT '商品快照信息',
     `ext_json` varchar(1024) NOT NULL DEFAULT '' COMMENT '信息扩展',
     `ctrl_json` varchar(1024) NOT NULL DEFAULT '' COMMENT '控制扩展',
     PRIMARY KEY (`id`),
@@ -37,4 +35,4 @@ CREATE TABLE review_info (
     UNIQUE KEY `uk_review_id` (`review_id`) COMMENT '评价id索引',
     KEY `idx_order_id` (`order_id`) COMMENT '订单id索引',
     KEY `idx_user_id` (`user_id`) COMMENT '⽤户id索引'
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT= '评价表';
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='评价表';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS review_outbox (
+    `id` bigint(32) unsigned NOT NULL AUTO_INCREMENT COMMENT '主键，即事件写入的顺序',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `published_at` timestamp NULL DEFAULT NULL COMMENT '投递到kafka的时间，未投递为NULL',
+    `event_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '事件id',
+    `event_type` varchar(64) NOT NULL DEFAULT '' COMMENT '事件类型',
+    `aggregate_type` varchar(32) NOT NULL DEFAULT '' COMMENT '聚合类型',
+    `aggregate_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '聚合id',
+    `payload` blob NOT NULL COMMENT 'proto编码的事件',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_event_id` (`event_id`) COMMENT '事件id索引',
+    KEY `idx_published_at` (`published_at`) COMMENT '投递状态索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='评价领域事件发件箱';
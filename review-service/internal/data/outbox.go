@@ -0,0 +1,35 @@
+package data
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+	"review-service/internal/data/model"
+	"review-service/internal/data/query"
+	"review-service/pkg/event"
+)
+
+// 发件箱 review_outbox 不分表，每个数据库一张，与同库分表上的业务数据在同一个事务中写入；
+// 同一条评价的数据位于同一个分片，它的事件按 id 的顺序由 review-job 投递
+
+// saveEvents 在事务 tx 中把事件写入发件箱
+func saveEvents(ctx context.Context, tx *query.Query, events []*event.Envelope) error {
+	if len(events) == 0 {
+		return nil
+	}
+	rows := make([]*model.ReviewOutbox, 0, len(events))
+	for _, ev := range events {
+		payload, err := proto.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, &model.ReviewOutbox{
+			EventID:       ev.GetEventId(),
+			EventType:     ev.GetEventType(),
+			AggregateType: ev.GetAggregateType(),
+			AggregateID:   ev.GetAggregateId(),
+			Payload:       payload,
+		})
+	}
+	return tx.ReviewOutbox.WithContext(ctx).Create(rows...)
+}
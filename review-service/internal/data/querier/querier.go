@@ -0,0 +1,29 @@
+package querier
+
+import "gorm.io/gen"
+
+// gorm/gen 自定义查询接口
+// 由 cmd/gen 生成到 internal/data/query 中，参数和返回值在编译期检查
+// 使用 where(...) 形式生成，条件拼接在 Do 当前的表上，开启分表后同样适用；
+// 不要写 @@table，生成时会被替换成固定的逻辑表名
+
+// ReviewIDQuerier 评价、回复、申诉三张表共用的按评价 ID 查询
+type ReviewIDQuerier interface {
+	// where("review_id=@reviewID")
+	FindByReviewID(reviewID int64) ([]*gen.T, error)
+}
+
+// ReviewQuerier 评价表查询
+type ReviewQuerier interface {
+	// where("order_id=@orderID")
+	FindByOrderID(orderID int64) ([]*gen.T, error)
+
+	// where("user_id=@userID")
+	FindByUserID(userID int64) ([]*gen.T, error)
+}
+
+// AppealQuerier 申诉表查询
+type AppealQuerier interface {
+	// where("appeal_id=@appealID")
+	FindByAppealID(appealID int64) ([]*gen.T, error)
+}
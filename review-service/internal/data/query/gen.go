@@ -19,6 +19,7 @@ var (
 	Q                = new(Query)
 	ReviewAppealInfo *reviewAppealInfo
 	ReviewInfo       *reviewInfo
+	ReviewOutbox     *reviewOutbox
 	ReviewReplyInfo  *reviewReplyInfo
 )
 
@@ -26,6 +27,7 @@ func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 	*Q = *Use(db, opts...)
 	ReviewAppealInfo = &Q.ReviewAppealInfo
 	ReviewInfo = &Q.ReviewInfo
+	ReviewOutbox = &Q.ReviewOutbox
 	ReviewReplyInfo = &Q.ReviewReplyInfo
 }
 
@@ -34,6 +36,7 @@ func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 		db:               db,
 		ReviewAppealInfo: newReviewAppealInfo(db, opts...),
 		ReviewInfo:       newReviewInfo(db, opts...),
+		ReviewOutbox:     newReviewOutbox(db, opts...),
 		ReviewReplyInfo:  newReviewReplyInfo(db, opts...),
 	}
 }
@@ -43,6 +46,7 @@ type Query struct {
 
 	ReviewAppealInfo reviewAppealInfo
 	ReviewInfo       reviewInfo
+	ReviewOutbox     reviewOutbox
 	ReviewReplyInfo  reviewReplyInfo
 }
 
@@ -53,6 +57,7 @@ func (q *Query) clone(db *gorm.DB) *Query {
 		db:               db,
 		ReviewAppealInfo: q.ReviewAppealInfo.clone(db),
 		ReviewInfo:       q.ReviewInfo.clone(db),
+		ReviewOutbox:     q.ReviewOutbox.clone(db),
 		ReviewReplyInfo:  q.ReviewReplyInfo.clone(db),
 	}
 }
@@ -70,6 +75,7 @@ func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 		db:               db,
 		ReviewAppealInfo: q.ReviewAppealInfo.replaceDB(db),
 		ReviewInfo:       q.ReviewInfo.replaceDB(db),
+		ReviewOutbox:     q.ReviewOutbox.replaceDB(db),
 		ReviewReplyInfo:  q.ReviewReplyInfo.replaceDB(db),
 	}
 }
@@ -77,6 +83,7 @@ func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 type queryCtx struct {
 	ReviewAppealInfo IReviewAppealInfoDo
 	ReviewInfo       IReviewInfoDo
+	ReviewOutbox     IReviewOutboxDo
 	ReviewReplyInfo  IReviewReplyInfoDo
 }
 
@@ -84,6 +91,7 @@ func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
 		ReviewAppealInfo: q.ReviewAppealInfo.WithContext(ctx),
 		ReviewInfo:       q.ReviewInfo.WithContext(ctx),
+		ReviewOutbox:     q.ReviewOutbox.WithContext(ctx),
 		ReviewReplyInfo:  q.ReviewReplyInfo.WithContext(ctx),
 	}
 }
@@ -7,6 +7,7 @@ package query
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -235,6 +236,39 @@ type IReviewAppealInfoDo interface {
 	Returning(value interface{}, columns ...string) IReviewAppealInfoDo
 	UnderlyingDB() *gorm.DB
 	schema.Tabler
+
+	FindByReviewID(reviewID int64) (result []*model.ReviewAppealInfo, err error)
+	FindByAppealID(appealID int64) (result []*model.ReviewAppealInfo, err error)
+}
+
+// where("review_id=@reviewID")
+func (r reviewAppealInfoDo) FindByReviewID(reviewID int64) (result []*model.ReviewAppealInfo, err error) {
+	var params []interface{}
+
+	var generateSQL strings.Builder
+	params = append(params, reviewID)
+	generateSQL.WriteString("review_id=? ")
+
+	var executeSQL *gorm.DB
+	executeSQL = r.UnderlyingDB().Where(generateSQL.String(), params...).Find(&result) // ignore_security_alert
+	err = executeSQL.Error
+
+	return
+}
+
+// where("appeal_id=@appealID")
+func (r reviewAppealInfoDo) FindByAppealID(appealID int64) (result []*model.ReviewAppealInfo, err error) {
+	var params []interface{}
+
+	var generateSQL strings.Builder
+	params = append(params, appealID)
+	generateSQL.WriteString("appeal_id=? ")
+
+	var executeSQL *gorm.DB
+	executeSQL = r.UnderlyingDB().Where(generateSQL.String(), params...).Find(&result) // ignore_security_alert
+	err = executeSQL.Error
+
+	return
 }
 
 func (r reviewAppealInfoDo) Debug() IReviewAppealInfoDo {
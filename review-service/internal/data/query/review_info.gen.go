@@ -7,6 +7,7 @@ package query
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -285,6 +286,55 @@ type IReviewInfoDo interface {
 	Returning(value interface{}, columns ...string) IReviewInfoDo
 	UnderlyingDB() *gorm.DB
 	schema.Tabler
+
+	FindByReviewID(reviewID int64) (result []*model.ReviewInfo, err error)
+	FindByOrderID(orderID int64) (result []*model.ReviewInfo, err error)
+	FindByUserID(userID int64) (result []*model.ReviewInfo, err error)
+}
+
+// where("review_id=@reviewID")
+func (r reviewInfoDo) FindByReviewID(reviewID int64) (result []*model.ReviewInfo, err error) {
+	var params []interface{}
+
+	var generateSQL strings.Builder
+	params = append(params, reviewID)
+	generateSQL.WriteString("review_id=? ")
+
+	var executeSQL *gorm.DB
+	executeSQL = r.UnderlyingDB().Where(generateSQL.String(), params...).Find(&result) // ignore_security_alert
+	err = executeSQL.Error
+
+	return
+}
+
+// where("order_id=@orderID")
+func (r reviewInfoDo) FindByOrderID(orderID int64) (result []*model.ReviewInfo, err error) {
+	var params []interface{}
+
+	var generateSQL strings.Builder
+	params = append(params, orderID)
+	generateSQL.WriteString("order_id=? ")
+
+	var executeSQL *gorm.DB
+	executeSQL = r.UnderlyingDB().Where(generateSQL.String(), params...).Find(&result) // ignore_security_alert
+	err = executeSQL.Error
+
+	return
+}
+
+// where("user_id=@userID")
+func (r reviewInfoDo) FindByUserID(userID int64) (result []*model.ReviewInfo, err error) {
+	var params []interface{}
+
+	var generateSQL strings.Builder
+	params = append(params, userID)
+	generateSQL.WriteString("user_id=? ")
+
+	var executeSQL *gorm.DB
+	executeSQL = r.UnderlyingDB().Where(generateSQL.String(), params...).Find(&result) // ignore_security_alert
+	err = executeSQL.Error
+
+	return
 }
 
 func (r reviewInfoDo) Debug() IReviewInfoDo {
@@ -7,6 +7,7 @@ package query
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -219,6 +220,23 @@ type IReviewReplyInfoDo interface {
 	Returning(value interface{}, columns ...string) IReviewReplyInfoDo
 	UnderlyingDB() *gorm.DB
 	schema.Tabler
+
+	FindByReviewID(reviewID int64) (result []*model.ReviewReplyInfo, err error)
+}
+
+// where("review_id=@reviewID")
+func (r reviewReplyInfoDo) FindByReviewID(reviewID int64) (result []*model.ReviewReplyInfo, err error) {
+	var params []interface{}
+
+	var generateSQL strings.Builder
+	params = append(params, reviewID)
+	generateSQL.WriteString("review_id=? ")
+
+	var executeSQL *gorm.DB
+	executeSQL = r.UnderlyingDB().Where(generateSQL.String(), params...).Find(&result) // ignore_security_alert
+	err = executeSQL.Error
+
+	return
 }
 
 func (r reviewReplyInfoDo) Debug() IReviewReplyInfoDo {
@@ -0,0 +1,111 @@
+package data
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+	"review-service/pkg/event"
+)
+
+const defaultCreateStatusTTL = 24 * time.Hour
+
+// ReviewQueue 将创建评价的命令写入 kafka，由 review-job 消费后写入 MySQL
+// 受理状态和订单占用记录在 redis，review-job 写入后更新状态，失败时释放订单
+type ReviewQueue struct {
+	data   *Data
+	writer *kafka.Writer
+	ttl    time.Duration
+	log    *log.Helper
+}
+
+// NewReviewQueue 未开启 data.async_create 时返回的队列 Enabled 为 false，评价同步创建
+func NewReviewQueue(c *conf.Data, data *Data, logger log.Logger) (biz.ReviewQueue, func(), error) {
+	q := &ReviewQueue{
+		data: data,
+		ttl:  defaultCreateStatusTTL,
+		log:  log.NewHelper(logger),
+	}
+	ac := c.GetAsyncCreate()
+	if ac.GetStatusTtl().AsDuration() > 0 {
+		q.ttl = ac.GetStatusTtl().AsDuration()
+	}
+	if !ac.GetEnabled() {
+		return q, func() {}, nil
+	}
+	q.writer = &kafka.Writer{
+		Addr:         kafka.TCP(ac.GetBrokers()...),
+		Topic:        ac.GetTopic(),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
+	}
+	q.log.Infof("async create enabled, topic: %s", ac.GetTopic())
+	cleanup := func() {
+		if err := q.writer.Close(); err != nil {
+			q.log.Errorf("close review queue writer failed, err:%v", err)
+		}
+	}
+	return q, cleanup, nil
+}
+
+func (q *ReviewQueue) Enabled() bool {
+	return q.writer != nil
+}
+
+func (q *ReviewQueue) Accept(ctx context.Context, reviewID, orderID int64) (bool, error) {
+	ok, err := q.data.redis.SetNX(ctx, event.CreateOrderKey(orderID), reviewID, q.ttl).Result()
+	if err != nil || !ok {
+		return false, err
+	}
+	key := event.CreateStatusKey(reviewID)
+	pipe := q.data.redis.TxPipeline()
+	pipe.HSet(ctx, key, "status", event.CreateAccepted)
+	pipe.Expire(ctx, key, q.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		q.Release(ctx, reviewID, orderID)
+		return false, err
+	}
+	return true, nil
+}
+
+func (q *ReviewQueue) Release(ctx context.Context, reviewID, orderID int64) {
+	if err := q.data.redis.Del(ctx, event.CreateOrderKey(orderID), event.CreateStatusKey(reviewID)).Err(); err != nil {
+		q.log.WithContext(ctx).Errorf("release order %d failed, err:%v", orderID, err)
+	}
+}
+
+// Publish 以 review_id 为 key 写入，kafka 确认后返回
+func (q *ReviewQueue) Publish(ctx context.Context, cmd *event.CreateReviewCommand) error {
+	value, err := proto.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	return q.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strconv.FormatInt(cmd.GetReviewId(), 10)),
+		Value: value,
+	})
+}
+
+// Status 已创建时同时清理轮询期间可能写入的空结果缓存
+func (q *ReviewQueue) Status(ctx context.Context, reviewID int64) (*biz.CreateStatus, error) {
+	fields, err := q.data.redis.HGetAll(ctx, event.CreateStatusKey(reviewID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	status := &biz.CreateStatus{ReviewID: reviewID, Status: fields["status"], Reason: fields["reason"]}
+	if status.Status == event.CreateCreated {
+		if err := q.data.redis.Del(ctx, nullCacheKey(reviewID)).Err(); err != nil {
+			q.log.WithContext(ctx).Errorf("delete null cache of %d failed, err:%v", reviewID, err)
+		}
+	}
+	return status, nil
+}
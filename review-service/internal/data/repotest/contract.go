@@ -0,0 +1,427 @@
+package repotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	v1 "review-service/api/review/v1"
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/pkg/snowflake"
+)
+
+// ReviewerRepo 契约用例
+// 同一组用例分别跑在内存实现和 gorm 实现上，保证两者行为一致：
+//
+//	func TestMemoryRepo(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) biz.ReviewerRepo { return memory.NewReviewerRepo() })
+//	}
+//
+//	func TestGormRepo(t *testing.T) {
+//		repotest.Run(t, repotest.NewGormRepo)
+//		repotest.Run(t, repotest.NewShardedGormRepo)
+//	}
+
+// NewRepo 每个用例调用一次，返回一个空的仓储
+type NewRepo func(t *testing.T) biz.ReviewerRepo
+
+// Run 执行仓储契约用例和依赖仓储的 ReviewerUsecase 业务规则用例
+func Run(t *testing.T, newRepo NewRepo) {
+	t.Run("repo", func(t *testing.T) { runRepo(t, newRepo) })
+	t.Run("usecase", func(t *testing.T) { runUsecase(t, newRepo) })
+}
+
+// NewReview 构造一条评价，未设置的字段使用默认值
+func NewReview(reviewID, orderID, storeID, userID int64) *model.ReviewInfo {
+	now := time.Now().Truncate(time.Second)
+	return &model.ReviewInfo{
+		CreateBy: "repotest",
+		UpdateBy: "repotest",
+		CreateAt: now,
+		UpdateAt: now,
+		ReviewID: reviewID,
+		Content:  "content",
+		Score:    5,
+		OrderID:  orderID,
+		StoreID:  storeID,
+		UserID:   userID,
+		Status:   10,
+	}
+}
+
+func runRepo(t *testing.T, newRepo NewRepo) {
+	ctx := context.Background()
+
+	t.Run("save and get by review id", func(t *testing.T) {
+		repo := newRepo(t)
+		saved := mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		if saved.ID == 0 {
+			t.Fatal("SaveReview should set primary key")
+		}
+		got, err := repo.GetReviewByReviewID(ctx, 1001)
+		if err != nil {
+			t.Fatalf("GetReviewByReviewID: %v", err)
+		}
+		if len(got) != 1 || got[0].OrderID != 2001 || got[0].StoreID != 3001 {
+			t.Fatalf("GetReviewByReviewID got %+v", got)
+		}
+	})
+
+	t.Run("missing review id returns empty", func(t *testing.T) {
+		repo := newRepo(t)
+		got, err := repo.GetReviewByReviewID(ctx, 999)
+		if err != nil || len(got) != 0 {
+			t.Fatalf("GetReviewByReviewID got %v, %v; want empty", got, err)
+		}
+	})
+
+	t.Run("get by order id", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		mustSave(t, repo, NewReview(1002, 2002, 3001, 4001))
+		got, err := repo.GetReviewByOrderID(ctx, 2002)
+		if err != nil || len(got) != 1 || got[0].ReviewID != 1002 {
+			t.Fatalf("GetReviewByOrderID got %v, %v", got, err)
+		}
+	})
+
+	t.Run("get by id", func(t *testing.T) {
+		repo := newRepo(t)
+		saved := mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		got, err := repo.GetReviewByID(ctx, 3001, saved.ID)
+		if err != nil || got.ReviewID != 1001 {
+			t.Fatalf("GetReviewByID got %v, %v", got, err)
+		}
+		if _, err := repo.GetReviewByID(ctx, 3001, saved.ID+100); !v1.IsIdErr(err) {
+			t.Fatalf("GetReviewByID missing id err = %v, want IdErr", err)
+		}
+		if _, err := repo.GetReviewByID(ctx, 3002, saved.ID); !v1.IsIdErr(err) {
+			t.Fatalf("GetReviewByID other store err = %v, want IdErr", err)
+		}
+	})
+
+	t.Run("get by uid sorted by create time", func(t *testing.T) {
+		repo := newRepo(t)
+		later := NewReview(1001, 2001, 3001, 4001)
+		later.CreateAt = later.CreateAt.Add(time.Hour)
+		mustSave(t, repo, later)
+		mustSave(t, repo, NewReview(1002, 2002, 3002, 4001))
+		mustSave(t, repo, NewReview(1003, 2003, 3003, 4002))
+		got, err := repo.GetReviewByUID(ctx, 4001)
+		if err != nil || len(got) != 2 {
+			t.Fatalf("GetReviewByUID got %v, %v", got, err)
+		}
+		if got[0].ReviewID != 1002 || got[1].ReviewID != 1001 {
+			t.Fatalf("GetReviewByUID order = [%d %d], want [1002 1001]", got[0].ReviewID, got[1].ReviewID)
+		}
+	})
+
+	t.Run("delete marks delete_at", func(t *testing.T) {
+		repo := newRepo(t)
+		saved := mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		if err := repo.DeleteReview(ctx, saved); err != nil {
+			t.Fatalf("DeleteReview: %v", err)
+		}
+		got, err := repo.GetReviewByID(ctx, 3001, saved.ID)
+		if err != nil || got.DeleteAt == nil {
+			t.Fatalf("GetReviewByID after delete got %v, %v; want delete_at set", got, err)
+		}
+	})
+
+	// 开启分表时两个店铺的评价位于不同的分片，自增主键可能相同
+	t.Run("delete only the given review", func(t *testing.T) {
+		repo := newRepo(t)
+		deleted := mustSave(t, repo, NewReview(1001, 2001, 3000, 4001))
+		mustSave(t, repo, NewReview(1002, 2002, 3001, 4001))
+		if err := repo.DeleteReview(ctx, deleted); err != nil {
+			t.Fatalf("DeleteReview: %v", err)
+		}
+		got, err := repo.GetReviewByReviewID(ctx, 1002)
+		if err != nil || len(got) != 1 || got[0].DeleteAt != nil {
+			t.Fatalf("review of another store got %v, %v; want not deleted", got, err)
+		}
+	})
+
+	t.Run("update by review id", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		id, err := repo.UpdateReviewByReviewID(ctx, &model.ReviewInfo{ReviewID: 1001, Content: "updated", Score: 3})
+		if err != nil || id != 1001 {
+			t.Fatalf("UpdateReviewByReviewID got %v, %v", id, err)
+		}
+		got, _ := repo.GetReviewByReviewID(ctx, 1001)
+		if len(got) != 1 || got[0].Content != "updated" || got[0].Score != 3 || got[0].OrderID != 2001 {
+			t.Fatalf("after update got %+v", got)
+		}
+	})
+
+	t.Run("reply", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		id, err := repo.AddReviewReply(ctx, &model.ReviewReplyInfo{ReplyID: 5001, ReviewID: 1001, StoreID: 3001, Content: "thanks"})
+		if err != nil || id != 5001 {
+			t.Fatalf("AddReviewReply got %v, %v", id, err)
+		}
+		_, err = repo.AddReviewReply(ctx, &model.ReviewReplyInfo{ReplyID: 5002, ReviewID: 1001, StoreID: 3002})
+		if !v1.IsStoreidReviewidMismatch(err) {
+			t.Fatalf("AddReviewReply store mismatch err = %v", err)
+		}
+		_, err = repo.AddReviewReply(ctx, &model.ReviewReplyInfo{ReplyID: 5003, ReviewID: 999, StoreID: 3001})
+		if !v1.IsReviewidErr(err) {
+			t.Fatalf("AddReviewReply missing review err = %v", err)
+		}
+	})
+
+	t.Run("list by store excludes hidden and deleted", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		hidden := NewReview(1002, 2002, 3001, 4001)
+		hidden.Status = 40
+		mustSave(t, repo, hidden)
+		deleted := mustSave(t, repo, NewReview(1003, 2003, 3001, 4001))
+		if err := repo.DeleteReview(ctx, deleted); err != nil {
+			t.Fatalf("DeleteReview: %v", err)
+		}
+		mustSave(t, repo, NewReview(1004, 2004, 3002, 4001))
+		got, err := repo.ListReviewByStoreID(ctx, 3001, 0, 10)
+		if err != nil || len(got) != 1 || got[0].ReviewID != 1001 {
+			t.Fatalf("ListReviewByStoreID got %v, %v; want [1001]", got, err)
+		}
+	})
+
+	t.Run("search by keyword", func(t *testing.T) {
+		repo := newRepo(t)
+		fast := NewReview(1001, 2001, 3001, 4001)
+		fast.Content = "物流很快，包装完好"
+		mustSave(t, repo, fast)
+		slow := NewReview(1002, 2002, 3001, 4001)
+		slow.Content = "味道一般"
+		mustSave(t, repo, slow)
+		other := NewReview(1003, 2003, 3002, 4001)
+		other.Content = "物流很快"
+		mustSave(t, repo, other)
+		got, err := repo.SearchReviews(ctx, &biz.SearchParam{Query: "物流", StoreID: 3001, Size: 10})
+		if err != nil || len(got.Hits) != 1 || got.Hits[0].ReviewID != 1001 {
+			t.Fatalf("SearchReviews got %+v, %v; want [1001]", got, err)
+		}
+		if len(got.Hits[0].Highlight) == 0 || !strings.Contains(got.Hits[0].Highlight[0], "<em>") {
+			t.Fatalf("SearchReviews highlight = %v", got.Hits[0].Highlight)
+		}
+	})
+
+	t.Run("appeal", func(t *testing.T) {
+		repo := newRepo(t)
+		id, err := repo.AddAppealReview(ctx, &model.ReviewAppealInfo{AppealID: 6001, ReviewID: 1001, StoreID: 3001, Status: 10, Reason: "spam"})
+		if err != nil || id != 6001 {
+			t.Fatalf("AddAppealReview got %v, %v", id, err)
+		}
+		byReview, err := repo.GetAppealByReviewID(ctx, 1001)
+		if err != nil || len(byReview) != 1 || byReview[0].AppealID != 6001 {
+			t.Fatalf("GetAppealByReviewID got %v, %v", byReview, err)
+		}
+		_, err = repo.UpdateAppealByAppealID(ctx, &model.ReviewAppealInfo{AppealID: 6001, Status: 20, OpUser: "op"})
+		if err != nil {
+			t.Fatalf("UpdateAppealByAppealID: %v", err)
+		}
+		byAppeal, err := repo.GetAppealByAppealID(ctx, 6001)
+		if err != nil || len(byAppeal) != 1 {
+			t.Fatalf("GetAppealByAppealID got %v, %v", byAppeal, err)
+		}
+		if a := byAppeal[0]; a.Status != 20 || a.OpUser != "op" || a.Reason != "spam" {
+			t.Fatalf("after update got %+v", a)
+		}
+		if got, err := repo.GetAppealByAppealID(ctx, 999); err != nil || len(got) != 0 {
+			t.Fatalf("GetAppealByAppealID missing got %v, %v; want empty", got, err)
+		}
+	})
+}
+
+func runUsecase(t *testing.T, newRepo NewRepo) {
+	ctx := context.Background()
+	sf, err := snowflake.NewSnowflake(1, 1)
+	if err != nil {
+		t.Fatalf("NewSnowflake: %v", err)
+	}
+	newUsecase := func(t *testing.T) (*biz.ReviewerUsecase, biz.ReviewerRepo) {
+		repo := newRepo(t)
+		return biz.NewReviewerUsecase(repo, nil, sf, log.DefaultLogger), repo
+	}
+
+	t.Run("create review", func(t *testing.T) {
+		uc, repo := newUsecase(t)
+		rv, err := uc.CreateReviewer(ctx, NewReview(0, 2001, 3001, 4001))
+		if err != nil || rv.ReviewID == 0 {
+			t.Fatalf("CreateReviewer got %v, %v", rv, err)
+		}
+		got, _ := repo.GetReviewByReviewID(ctx, rv.ReviewID)
+		if len(got) != 1 {
+			t.Fatalf("created review not found: %d", rv.ReviewID)
+		}
+	})
+
+	t.Run("duplicate order", func(t *testing.T) {
+		uc, _ := newUsecase(t)
+		if _, err := uc.CreateReviewer(ctx, NewReview(0, 2001, 3001, 4001)); err != nil {
+			t.Fatalf("CreateReviewer: %v", err)
+		}
+		_, err := uc.CreateReviewer(ctx, NewReview(0, 2001, 3001, 4001))
+		if !v1.IsOrderReviewed(err) {
+			t.Fatalf("duplicate order err = %v, want OrderReviewed", err)
+		}
+	})
+
+	t.Run("delete twice", func(t *testing.T) {
+		uc, repo := newUsecase(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		if err := uc.DeleteReviewer(ctx, 1001); err != nil {
+			t.Fatalf("DeleteReviewer: %v", err)
+		}
+		if err := uc.DeleteReviewer(ctx, 1001); !v1.IsReviewHasBeenDeleted(err) {
+			t.Fatalf("second delete err = %v, want ReviewHasBeenDeleted", err)
+		}
+		if err := uc.DeleteReviewer(ctx, 999); !v1.IsReviewidErr(err) {
+			t.Fatalf("delete missing review err = %v, want ReviewidErr", err)
+		}
+	})
+
+	// 只传主键的调用方按主键删除
+	t.Run("delete by id", func(t *testing.T) {
+		uc, repo := newUsecase(t)
+		saved := mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		reviewID, err := uc.DeleteReviewByID(ctx, saved.ID)
+		if err != nil || reviewID != 1001 {
+			t.Fatalf("DeleteReviewByID got %v, %v; want 1001", reviewID, err)
+		}
+		got, err := repo.GetReviewByReviewID(ctx, 1001)
+		if err != nil || len(got) != 1 || got[0].DeleteAt == nil {
+			t.Fatalf("review after delete by id got %v, %v; want delete_at set", got, err)
+		}
+		if _, err := uc.DeleteReviewByID(ctx, saved.ID); !v1.IsReviewHasBeenDeleted(err) {
+			t.Fatalf("second delete err = %v, want ReviewHasBeenDeleted", err)
+		}
+		if _, err := uc.DeleteReviewByID(ctx, saved.ID+100); !v1.IsIdErr(err) {
+			t.Fatalf("delete missing id err = %v, want IdErr", err)
+		}
+	})
+
+	// 开启分表后两个分片中的评价主键可能相同，无法确定要删除哪一条
+	t.Run("delete by ambiguous id", func(t *testing.T) {
+		uc, repo := newUsecase(t)
+		first := mustSave(t, repo, NewReview(1001, 2001, 3000, 4001))
+		second := mustSave(t, repo, NewReview(1002, 2002, 3001, 4001))
+		if first.ID != second.ID {
+			t.Skip("reviews of both stores have different primary keys")
+		}
+		if _, err := uc.DeleteReviewByID(ctx, first.ID); !v1.IsIdErr(err) {
+			t.Fatalf("delete ambiguous id err = %v, want IdErr", err)
+		}
+		for _, reviewID := range []int64{1001, 1002} {
+			if got, _ := repo.GetReviewByReviewID(ctx, reviewID); len(got) != 1 || got[0].DeleteAt != nil {
+				t.Fatalf("review %d got %v; want not deleted", reviewID, got)
+			}
+		}
+	})
+
+	t.Run("update deleted review", func(t *testing.T) {
+		uc, repo := newUsecase(t)
+		saved := mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		if err := repo.DeleteReview(ctx, saved); err != nil {
+			t.Fatalf("DeleteReview: %v", err)
+		}
+		_, err := uc.UpdateReviewByReviewID(ctx, &model.ReviewInfo{ReviewID: 1001, Content: "x"})
+		if !v1.IsReviewidErr(err) {
+			t.Fatalf("update deleted review err = %v, want ReviewidErr", err)
+		}
+		_, err = uc.UpdateReviewByReviewID(ctx, &model.ReviewInfo{ReviewID: 999, Content: "x"})
+		if !v1.IsReviewidErr(err) {
+			t.Fatalf("update missing review err = %v, want ReviewidErr", err)
+		}
+	})
+
+	t.Run("reply to missing review", func(t *testing.T) {
+		uc, _ := newUsecase(t)
+		_, err := uc.AddReplyReview(ctx, &model.ReviewReplyInfo{ReviewID: 999, StoreID: 3001})
+		if !v1.IsReviewidErr(err) {
+			t.Fatalf("reply missing review err = %v, want ReviewidErr", err)
+		}
+	})
+
+	t.Run("appeal store mismatch", func(t *testing.T) {
+		uc, repo := newUsecase(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		_, err := uc.AppealReview(ctx, &model.ReviewAppealInfo{ReviewID: 1001, StoreID: 3002})
+		if !v1.IsStoreidReviewidMismatch(err) {
+			t.Fatalf("appeal store mismatch err = %v, want StoreidReviewidMismatch", err)
+		}
+	})
+
+	t.Run("appeal exists", func(t *testing.T) {
+		uc, repo := newUsecase(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		if _, err := uc.AppealReview(ctx, &model.ReviewAppealInfo{ReviewID: 1001, StoreID: 3001, Reason: "spam"}); err != nil {
+			t.Fatalf("AppealReview: %v", err)
+		}
+		_, err := uc.AppealReview(ctx, &model.ReviewAppealInfo{ReviewID: 1001, StoreID: 3001, Reason: "spam"})
+		if !v1.IsErrorAppealExists(err) {
+			t.Fatalf("second appeal err = %v, want ErrorAppealExists", err)
+		}
+	})
+
+	t.Run("audit review", func(t *testing.T) {
+		uc, repo := newUsecase(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		if err := uc.AuditReview(ctx, &model.ReviewInfo{ReviewID: 1001, Status: 40}); err == nil {
+			t.Fatal("audit with status 40 should fail")
+		}
+		if err := uc.AuditReview(ctx, &model.ReviewInfo{ReviewID: 999, Status: 20}); !v1.IsReviewidErr(err) {
+			t.Fatalf("audit missing review err = %v, want ReviewidErr", err)
+		}
+		if err := uc.AuditReview(ctx, &model.ReviewInfo{ReviewID: 1001, Status: 20, OpUser: "op"}); err != nil {
+			t.Fatalf("AuditReview: %v", err)
+		}
+		got, _ := repo.GetReviewByReviewID(ctx, 1001)
+		if got[0].Status != 20 || got[0].OpUser != "op" {
+			t.Fatalf("audited review = status %d, op_user %q", got[0].Status, got[0].OpUser)
+		}
+	})
+
+	t.Run("handle appeal", func(t *testing.T) {
+		uc, repo := newUsecase(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		appealID, err := uc.AppealReview(ctx, &model.ReviewAppealInfo{ReviewID: 1001, StoreID: 3001, Reason: "spam"})
+		if err != nil {
+			t.Fatalf("AppealReview: %v", err)
+		}
+		appeals, _ := repo.GetAppealByAppealID(ctx, appealID)
+		if len(appeals) != 1 {
+			t.Fatalf("appeal %d not found", appealID)
+		}
+		_, err = uc.HandleAppeal(ctx, &model.ReviewAppealInfo{ID: appeals[0].ID + 1, AppealID: appealID, Status: 20})
+		if !v1.IsErrorAppealExists(err) {
+			t.Fatalf("handle appeal id mismatch err = %v", err)
+		}
+		if _, err := uc.HandleAppeal(ctx, &model.ReviewAppealInfo{ID: appeals[0].ID, AppealID: appealID, Status: 20}); err != nil {
+			t.Fatalf("HandleAppeal: %v", err)
+		}
+		appeals, _ = repo.GetAppealByAppealID(ctx, appealID)
+		if appeals[0].Status != 20 {
+			t.Fatalf("appeal status = %d, want 20", appeals[0].Status)
+		}
+		_, err = uc.HandleAppeal(ctx, &model.ReviewAppealInfo{AppealID: 999})
+		if !v1.IsErrorAppealExists(err) {
+			t.Fatalf("handle missing appeal err = %v", err)
+		}
+	})
+}
+
+func mustSave(t *testing.T, repo biz.ReviewerRepo, rv *model.ReviewInfo) *model.ReviewInfo {
+	t.Helper()
+	saved, err := repo.SaveReview(context.Background(), rv)
+	if err != nil {
+		t.Fatalf("SaveReview: %v", err)
+	}
+	return saved
+}
@@ -0,0 +1,108 @@
+package repotest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+	"review-service/internal/data"
+	"review-service/internal/data/model"
+)
+
+// shardedTableCount NewShardedGormRepo 的分表数量
+const shardedTableCount = 2
+
+// NewGormRepo 基于 SQLite 和 miniredis 创建 data.ReviewerRepo
+// 不需要外部的 MySQL、Redis、ES，表结构由 model 自动建表；搜索使用内存中的 bleve 索引，写入时同步更新
+func NewGormRepo(t *testing.T) biz.ReviewerRepo {
+	t.Helper()
+	repo, _ := newGormRepo(t, nil)
+	return repo
+}
+
+// NewShardedGormRepo 与 NewGormRepo 相同，但开启分表，评价、回复、申诉按 store_id 写入 2 张分表，
+// 各分表的自增主键互相独立
+func NewShardedGormRepo(t *testing.T) biz.ReviewerRepo {
+	t.Helper()
+	repo, _ := newGormRepo(t, &conf.Data_Sharding{Enabled: true, TableCount: shardedTableCount})
+	return repo
+}
+
+// NewGormOutboxRepo 与 NewGormRepo 相同，同时返回读取发件箱的函数
+func NewGormOutboxRepo(t *testing.T) (biz.ReviewerRepo, Outbox) {
+	t.Helper()
+	repo, db := newGormRepo(t, nil)
+	return repo, gormOutbox(db)
+}
+
+// NewShardedGormOutboxRepo 与 NewShardedGormRepo 相同，同时返回读取发件箱的函数
+func NewShardedGormOutboxRepo(t *testing.T) (biz.ReviewerRepo, Outbox) {
+	t.Helper()
+	repo, db := newGormRepo(t, &conf.Data_Sharding{Enabled: true, TableCount: shardedTableCount})
+	return repo, gormOutbox(db)
+}
+
+// gormOutbox 按写入顺序读出发件箱中的事件
+func gormOutbox(db *gorm.DB) Outbox {
+	return func(t *testing.T) []*model.ReviewOutbox {
+		t.Helper()
+		var rows []*model.ReviewOutbox
+		if err := db.Order("id").Find(&rows).Error; err != nil {
+			t.Fatalf("read review_outbox: %v", err)
+		}
+		return rows
+	}
+}
+
+func newGormRepo(t *testing.T, sharding *conf.Data_Sharding) (biz.ReviewerRepo, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "review.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&model.ReviewInfo{}, &model.ReviewReplyInfo{}, &model.ReviewAppealInfo{}, &model.ReviewOutbox{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	for i := 0; sharding.GetEnabled() && i < int(sharding.GetTableCount()); i++ {
+		tables := map[string]interface{}{
+			model.TableNameReviewInfo:       &model.ReviewInfo{},
+			model.TableNameReviewReplyInfo:  &model.ReviewReplyInfo{},
+			model.TableNameReviewAppealInfo: &model.ReviewAppealInfo{},
+		}
+		for name, m := range tables {
+			if err := db.Table(fmt.Sprintf("%s_%02d", name, i)).AutoMigrate(m); err != nil {
+				t.Fatalf("auto migrate %s_%02d: %v", name, i, err)
+			}
+		}
+	}
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	c := &conf.Data{Search: &conf.Data_Search{Backend: "bleve", IndexOnWrite: true}, Sharding: sharding}
+	shards, _, err := data.NewSharding(c, db, log.DefaultLogger)
+	if err != nil {
+		t.Fatalf("new sharding: %v", err)
+	}
+	index, closeIndex, err := data.NewSearchIndex(c, log.DefaultLogger)
+	if err != nil {
+		t.Fatalf("new search index: %v", err)
+	}
+	t.Cleanup(closeIndex)
+	d, cleanup, err := data.NewData(db, shards, rdb, index, c, log.DefaultLogger)
+	if err != nil {
+		t.Fatalf("new data: %v", err)
+	}
+	t.Cleanup(cleanup)
+	return data.NewReviewerRepo(d, log.DefaultLogger), db
+}
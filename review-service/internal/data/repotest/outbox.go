@@ -0,0 +1,136 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/protobuf/proto"
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/pkg/event"
+	"review-service/pkg/snowflake"
+)
+
+// Outbox 按写入顺序读出发件箱 review_outbox 中的全部事件
+type Outbox func(t *testing.T) []*model.ReviewOutbox
+
+// NewOutboxRepo 每个用例调用一次，返回一个空的仓储和读取它的发件箱的函数
+type NewOutboxRepo func(t *testing.T) (biz.ReviewerRepo, Outbox)
+
+// RunOutbox 发件箱用例：带有事件的写操作把事件和业务数据写入同一个事务，业务数据写入失败时事件也不会写入
+// 内存实现没有发件箱，只在 gorm 实现上运行
+//
+//	func TestReviewOutbox(t *testing.T) {
+//		repotest.RunOutbox(t, repotest.NewGormOutboxRepo)
+//	}
+func RunOutbox(t *testing.T, newRepo NewOutboxRepo) {
+	ctx := context.Background()
+	sf, err := snowflake.NewSnowflake(1, 1)
+	if err != nil {
+		t.Fatalf("NewSnowflake: %v", err)
+	}
+	newUsecase := func(t *testing.T) (*biz.ReviewerUsecase, biz.ReviewerRepo, Outbox) {
+		repo, outbox := newRepo(t)
+		return biz.NewReviewerUsecase(repo, nil, sf, log.DefaultLogger), repo, outbox
+	}
+
+	t.Run("create review writes review.created", func(t *testing.T) {
+		uc, _, outbox := newUsecase(t)
+		rv, err := uc.CreateReviewer(ctx, NewReview(0, 2001, 3001, 4001))
+		if err != nil {
+			t.Fatalf("CreateReviewer: %v", err)
+		}
+		rows := outbox(t)
+		if len(rows) != 1 {
+			t.Fatalf("outbox has %d events, want 1", len(rows))
+		}
+		ev := mustEvent(t, rows[0], event.TypeReviewCreated, rv.ReviewID)
+		created := ev.GetReviewCreated()
+		if created.GetReviewId() != rv.ReviewID || created.GetOrderId() != 2001 || created.GetStoreId() != 3001 || created.GetUserId() != 4001 {
+			t.Fatalf("review.created payload = %v", created)
+		}
+	})
+
+	t.Run("approve review writes review.approved", func(t *testing.T) {
+		uc, repo, outbox := newUsecase(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		if err := uc.AuditReview(ctx, &model.ReviewInfo{ReviewID: 1001, Status: biz.ReviewStatusApproved, OpUser: "op", OpRemarks: "ok"}); err != nil {
+			t.Fatalf("AuditReview: %v", err)
+		}
+		rows := outbox(t)
+		if len(rows) != 1 {
+			t.Fatalf("outbox has %d events, want 1", len(rows))
+		}
+		approved := mustEvent(t, rows[0], event.TypeReviewApproved, 1001).GetReviewApproved()
+		if approved.GetReviewId() != 1001 || approved.GetStoreId() != 3001 || approved.GetUserId() != 4001 || approved.GetOpUser() != "op" || approved.GetOpRemarks() != "ok" {
+			t.Fatalf("review.approved payload = %v", approved)
+		}
+
+		// 已经通过的评价再次审核通过不产生事件
+		if err := uc.AuditReview(ctx, &model.ReviewInfo{ReviewID: 1001, Status: biz.ReviewStatusApproved, OpUser: "op"}); err != nil {
+			t.Fatalf("AuditReview: %v", err)
+		}
+		if rows := outbox(t); len(rows) != 1 {
+			t.Fatalf("outbox has %d events after approving again, want 1", len(rows))
+		}
+	})
+
+	t.Run("decide appeal writes appeal.decided", func(t *testing.T) {
+		uc, repo, outbox := newUsecase(t)
+		mustSave(t, repo, NewReview(1001, 2001, 3001, 4001))
+		if _, err := uc.AppealReview(ctx, &model.ReviewAppealInfo{ReviewID: 1001, StoreID: 3001, Reason: "spam"}); err != nil {
+			t.Fatalf("AppealReview: %v", err)
+		}
+		appeals, err := repo.GetAppealByReviewID(ctx, 1001)
+		if err != nil || len(appeals) != 1 {
+			t.Fatalf("GetAppealByReviewID got %v, %v", appeals, err)
+		}
+		decision := &model.ReviewAppealInfo{ID: appeals[0].ID, AppealID: appeals[0].AppealID, Status: biz.AppealStatusApproved, OpUser: "op", OpRemarks: "approved"}
+		if _, err := uc.HandleAppeal(ctx, decision); err != nil {
+			t.Fatalf("HandleAppeal: %v", err)
+		}
+		rows := outbox(t)
+		if len(rows) != 1 {
+			t.Fatalf("outbox has %d events, want 1", len(rows))
+		}
+		decided := mustEvent(t, rows[0], event.TypeAppealDecided, 1001).GetAppealDecided()
+		if decided.GetAppealId() != appeals[0].AppealID || decided.GetReviewId() != 1001 || decided.GetStoreId() != 3001 ||
+			decided.GetStatus() != biz.AppealStatusApproved || decided.GetOpUser() != "op" || decided.GetOpRemarks() != "approved" {
+			t.Fatalf("appeal.decided payload = %v", decided)
+		}
+	})
+
+	t.Run("failed write leaves no event", func(t *testing.T) {
+		_, repo, outbox := newUsecase(t)
+		// 评价写入后事件编码失败（proto 的 string 字段不能是非法的 UTF-8），整个事务回滚
+		ev := &event.Envelope{EventId: 1, EventType: event.TypeReviewCreated, AggregateType: event.AggregateReview, AggregateId: 1001,
+			Payload: &event.Envelope_ReviewCreated{ReviewCreated: &event.ReviewCreated{ReviewId: 1001, Content: "\xff"}}}
+		if _, err := repo.SaveReview(ctx, NewReview(1001, 2001, 3001, 4001), ev); err == nil {
+			t.Fatal("SaveReview with an invalid event should fail")
+		}
+		if rows := outbox(t); len(rows) != 0 {
+			t.Fatalf("outbox has %d events after a rolled back write, want 0", len(rows))
+		}
+		if got, err := repo.GetReviewByReviewID(ctx, 1001); err != nil || len(got) != 0 {
+			t.Fatalf("GetReviewByReviewID got %v, %v; want the review rolled back", got, err)
+		}
+	})
+}
+
+// mustEvent 检查发件箱中的一行并解码其中的事件
+func mustEvent(t *testing.T, row *model.ReviewOutbox, eventType string, aggregateID int64) *event.Envelope {
+	t.Helper()
+	if row.EventType != eventType || row.AggregateType != event.AggregateReview || row.AggregateID != aggregateID || row.PublishedAt != nil {
+		t.Fatalf("outbox row = %s %s %d published at %v, want unpublished %s %s %d",
+			row.EventType, row.AggregateType, row.AggregateID, row.PublishedAt, eventType, event.AggregateReview, aggregateID)
+	}
+	ev := new(event.Envelope)
+	if err := proto.Unmarshal(row.Payload, ev); err != nil {
+		t.Fatalf("decode outbox payload: %v", err)
+	}
+	if ev.GetEventId() != row.EventID || ev.GetEventType() != eventType || ev.GetAggregateId() != aggregateID {
+		t.Fatalf("outbox payload = %v, want event %d %s of %d", ev, row.EventID, eventType, aggregateID)
+	}
+	return ev
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 	"github.com/go-redis/redis/v8"
 	"golang.org/x/sync/singleflight"
 	v1 "review-service/api/review/v1"
 	"review-service/internal/data/model"
+	"review-service/internal/data/query"
+	"review-service/pkg/event"
+	"review-service/pkg/search"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -31,9 +34,39 @@ func NewReviewerRepo(data *Data, logger log.Logger) biz.ReviewerRepo {
 	}
 }
 
-func (r *ReviewerRepo) SaveReview(ctx context.Context, review *model.ReviewInfo) (*model.ReviewInfo, error) {
-	err := r.data.query.ReviewInfo.WithContext(ctx).Save(review)
-	return review, err
+// reader 读操作默认路由到从库，ctx 经 biz.WithPrimary 标记后强制走主库
+// shard 为分片下标，未开启分表时只有 0 号分片
+func (r *ReviewerRepo) reader(ctx context.Context, shard int) *query.Query {
+	return r.data.shards.Query(shard, !biz.UsePrimary(ctx))
+}
+
+// writer 写操作走主库
+func (r *ReviewerRepo) writer(shard int) *query.Query {
+	return r.data.shards.Query(shard, false)
+}
+
+func (r *ReviewerRepo) SaveReview(ctx context.Context, review *model.ReviewInfo, events ...*event.Envelope) (*model.ReviewInfo, error) {
+	q := r.writer(r.data.shards.ShardIndex(review.StoreID))
+	err := q.Transaction(func(tx *query.Query) error {
+		if err := tx.ReviewInfo.WithContext(ctx).Save(review); err != nil {
+			return err
+		}
+		return saveEvents(ctx, tx, events)
+	})
+	if err != nil {
+		return review, err
+	}
+	// 写入成功后清理空结果缓存并更新布隆过滤器
+	r.delNullCache(ctx, review.ReviewID)
+	r.addToBloom(ctx, r.data.cache.bloomReviewKey, review.ReviewID)
+	r.addToBloom(ctx, r.data.cache.bloomStoreKey, review.StoreID)
+	if r.data.search.indexOnWrite {
+		// 重新读出数据库填充的默认值（如创建时间）后写入索引
+		if saved, err := q.ReviewInfo.WithContext(ctx).FindByReviewID(review.ReviewID); err == nil {
+			r.refreshIndex(ctx, saved...)
+		}
+	}
+	return review, nil
 }
 
 func (r *ReviewerRepo) Update(ctx context.Context, g *biz.Reviewer) (*biz.Reviewer, error) {
@@ -41,7 +74,10 @@ func (r *ReviewerRepo) Update(ctx context.Context, g *biz.Reviewer) (*biz.Review
 }
 
 func (r *ReviewerRepo) GetReviewByOrderID(ctx context.Context, orderId int64) ([]*model.ReviewInfo, error) {
-	find, err := r.data.query.ReviewInfo.WithContext(ctx).Where(r.data.query.ReviewInfo.OrderID.Eq(orderId)).Find()
+	find, err := scatter(ctx, r.data.shards, func(ctx context.Context, shard int) ([]*model.ReviewInfo, error) {
+		q := r.reader(ctx, shard)
+		return q.ReviewInfo.WithContext(ctx).FindByOrderID(orderId)
+	})
 	if err != nil {
 		return nil, v1.ErrorDbFailed("DB Find error")
 	}
@@ -56,30 +92,73 @@ func (r *ReviewerRepo) ListAll(context.Context) ([]*biz.Reviewer, error) {
 	return nil, nil
 }
 
-func (r *ReviewerRepo) DeleteReview(ctx context.Context, ID int64) error {
-	_, err := r.data.query.ReviewInfo.
+// DeleteReview 按 review_id 逻辑删除，只写入 review.StoreID 所在的分片
+func (r *ReviewerRepo) DeleteReview(ctx context.Context, review *model.ReviewInfo) error {
+	q := r.writer(r.data.shards.ShardIndex(review.StoreID))
+	_, err := q.ReviewInfo.
 		WithContext(ctx).
-		Where(r.data.query.ReviewInfo.ID.Eq(ID)).
-		Update(r.data.query.ReviewInfo.DeleteAt, time.Now())
-	return err
+		Where(q.ReviewInfo.ReviewID.Eq(review.ReviewID)).
+		Update(q.ReviewInfo.DeleteAt, time.Now())
+	if err != nil {
+		return err
+	}
+	if r.data.search.indexOnWrite {
+		if deleted, err := q.ReviewInfo.WithContext(ctx).FindByReviewID(review.ReviewID); err == nil {
+			r.refreshIndex(ctx, deleted...)
+		}
+	}
+	return nil
 }
 
-func (r *ReviewerRepo) GetReviewByID(ctx context.Context, ID int64) (*model.ReviewInfo, error) {
-	info, err := r.data.query.ReviewInfo.
+// GetReviewByID 开启分表后主键只在分片内唯一，只查询 storeID 所在的分片
+func (r *ReviewerRepo) GetReviewByID(ctx context.Context, storeID int64, ID int64) (*model.ReviewInfo, error) {
+	q := r.reader(ctx, r.data.shards.ShardIndex(storeID))
+	info, err := q.ReviewInfo.
 		WithContext(ctx).
-		Where(r.data.query.ReviewInfo.ID.Eq(ID)).
-		First()
-	if err != nil {
+		Where(q.ReviewInfo.ID.Eq(ID), q.ReviewInfo.StoreID.Eq(storeID)).
+		Find()
+	if err != nil || len(info) == 0 {
 		return nil, v1.ErrorIdErr("Do not exist ID: %v", ID)
 	}
+	return info[0], nil
+}
+
+func (r *ReviewerRepo) ListReviewByID(ctx context.Context, ID int64) ([]*model.ReviewInfo, error) {
+	info, err := scatter(ctx, r.data.shards, func(ctx context.Context, shard int) ([]*model.ReviewInfo, error) {
+		q := r.reader(ctx, shard)
+		return q.ReviewInfo.WithContext(ctx).Where(q.ReviewInfo.ID.Eq(ID)).Find()
+	})
+	if err != nil {
+		return nil, v1.ErrorDbFailed("DB error while searching ID: %v", ID)
+	}
 	return info, nil
 }
 
+// GetReviewByReviewID 布隆过滤器和空结果缓存拦截不存在的 reviewID，防止缓存穿透
+// 走主库的查询（写前校验、写后立即读）不经过两者，刚创建的评价可能还没有加入布隆过滤器；
+// 从库没有查到时再到主库确认，确认不存在后才写入空结果缓存，避免从库延迟把新评价缓存为不存在
 func (r *ReviewerRepo) GetReviewByReviewID(ctx context.Context, reviewId int64) ([]*model.ReviewInfo, error) {
-	info, err := r.data.query.ReviewInfo.
-		WithContext(ctx).
-		Where(r.data.query.ReviewInfo.ReviewID.Eq(reviewId)).
-		Find()
+	primary := biz.UsePrimary(ctx)
+	if !primary && (!r.mightExist(ctx, r.data.cache.bloomReviewKey, reviewId) || r.isNullCached(ctx, reviewId)) {
+		return []*model.ReviewInfo{}, nil
+	}
+	info, err := r.findByReviewID(ctx, reviewId)
+	if err != nil || len(info) > 0 || primary {
+		return info, err
+	}
+	info, err = r.findByReviewID(biz.WithPrimary(ctx), reviewId)
+	if err == nil && len(info) == 0 {
+		r.setNullCache(ctx, reviewId)
+	}
+	return info, err
+}
+
+// findByReviewID 在所有分片上按 review_id 查询，review_id 不含 store_id，无法确定分片
+func (r *ReviewerRepo) findByReviewID(ctx context.Context, reviewId int64) ([]*model.ReviewInfo, error) {
+	info, err := scatter(ctx, r.data.shards, func(ctx context.Context, shard int) ([]*model.ReviewInfo, error) {
+		q := r.reader(ctx, shard)
+		return q.ReviewInfo.WithContext(ctx).FindByReviewID(reviewId)
+	})
 	if err != nil {
 		return nil, v1.ErrorDbFailed("DB error while searching reviewID: %v", reviewId)
 	}
@@ -96,38 +175,87 @@ func (r *ReviewerRepo) UpdateReviewByReviewID(ctx context.Context, rv *model.Rev
 		VideoInfo:    rv.VideoInfo,
 		Anonymous:    rv.Anonymous,
 	}
-	_, err := r.data.query.ReviewInfo.
-		WithContext(ctx).
-		Where(r.data.query.ReviewInfo.ReviewID.Eq(rv.ReviewID)).
-		Updates(updateReviewData)
+	updated, err := scatter(ctx, r.data.shards, func(ctx context.Context, shard int) ([]*model.ReviewInfo, error) {
+		q := r.writer(shard)
+		_, err := q.ReviewInfo.
+			WithContext(ctx).
+			Where(q.ReviewInfo.ReviewID.Eq(rv.ReviewID)).
+			Updates(updateReviewData)
+		if err != nil || !r.data.search.indexOnWrite {
+			return nil, err
+		}
+		return q.ReviewInfo.WithContext(ctx).FindByReviewID(rv.ReviewID)
+	})
 	if err != nil {
 		return 0, v1.ErrorIdErr("Do not exist reviewed: %v", rv.ReviewID)
 	}
+	r.refreshIndex(ctx, updated...)
 	return rv.ReviewID, nil
 
 }
 
+// AuditReview 更新评价的审核结果，review.StoreID 为评价所在的分片
+func (r *ReviewerRepo) AuditReview(ctx context.Context, review *model.ReviewInfo, events ...*event.Envelope) error {
+	q := r.writer(r.data.shards.ShardIndex(review.StoreID))
+	err := q.Transaction(func(tx *query.Query) error {
+		_, err := tx.ReviewInfo.
+			WithContext(ctx).
+			Where(tx.ReviewInfo.ReviewID.Eq(review.ReviewID)).
+			Updates(model.ReviewInfo{
+				UpdateBy:  review.UpdateBy,
+				Status:    review.Status,
+				OpReason:  review.OpReason,
+				OpRemarks: review.OpRemarks,
+				OpUser:    review.OpUser,
+			})
+		if err != nil {
+			return err
+		}
+		return saveEvents(ctx, tx, events)
+	})
+	if err != nil {
+		return v1.ErrorDbFailed("DB error while auditing reviewID: %v", review.ReviewID)
+	}
+	if r.data.search.indexOnWrite {
+		if audited, err := q.ReviewInfo.WithContext(ctx).FindByReviewID(review.ReviewID); err == nil {
+			r.refreshIndex(ctx, audited...)
+		}
+	}
+	return nil
+}
+
+// GetReviewByUID 用户的评价分散在各个分片上，需要 scatter-gather
 func (r *ReviewerRepo) GetReviewByUID(ctx context.Context, uid int64) ([]*model.ReviewInfo, error) {
-	data, err := r.data.query.ReviewInfo.
-		WithContext(ctx).
-		Where(r.data.query.ReviewInfo.UserID.Eq(uid)).
-		Find()
+	data, err := scatter(ctx, r.data.shards, func(ctx context.Context, shard int) ([]*model.ReviewInfo, error) {
+		q := r.reader(ctx, shard)
+		return q.ReviewInfo.WithContext(ctx).FindByUserID(uid)
+	})
 	if err != nil {
 		return nil, v1.ErrorIdErr("DB error while finding %v", uid)
 	}
+	// 各分片结果合并后按创建时间排序，与单表时的顺序保持一致
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].CreateAt.Before(data[j].CreateAt)
+	})
 	return data, nil
 }
 
 // 创建一条评论
 func (r *ReviewerRepo) AddReviewReply(ctx context.Context, reply *model.ReviewReplyInfo) (int64, error) {
+	// 回复和评价位于同一个 store_id 分片
+	q := r.writer(r.data.shards.ShardIndex(reply.StoreID))
 	// 查询 ShoreID 是否与评论的 Review 中的一致
-	rv, err := r.data.query.ReviewInfo.
-		WithContext(ctx).
-		Where(r.data.query.ReviewInfo.ReviewID.Eq(reply.ReviewID)).
-		Find()
+	rv, err := q.ReviewInfo.WithContext(ctx).FindByReviewID(reply.ReviewID)
 	if err != nil {
 		return 0, v1.ErrorDbFailed("DB error while searching reviewID: %v", reply.ReviewID)
 	}
+	if len(rv) == 0 && r.data.shards.Enabled() {
+		// 不在 StoreID 所在的分片时评价可能属于其他店铺，在所有分片上确认一次
+		rv, err = r.GetReviewByReviewID(biz.WithPrimary(ctx), reply.ReviewID)
+		if err != nil {
+			return 0, err
+		}
+	}
 	if len(rv) == 0 {
 		return 0, v1.ErrorReviewidErr("Do not exist ReviewID: %v", reply.ReviewID)
 	}
@@ -136,7 +264,7 @@ func (r *ReviewerRepo) AddReviewReply(ctx context.Context, reply *model.ReviewRe
 		return 0, v1.ErrorStoreidReviewidMismatch("Store ID mismatch with View's, StoreID: %v, View's StoreID: %v", reply.StoreID, rv[0].StoreID)
 	}
 	// 核心逻辑
-	err = r.data.query.ReviewReplyInfo.WithContext(ctx).Save(reply)
+	err = q.ReviewReplyInfo.WithContext(ctx).Save(reply)
 	if err != nil {
 		return 0, v1.ErrorDbFailed("DB Save error")
 	}
@@ -145,7 +273,7 @@ func (r *ReviewerRepo) AddReviewReply(ctx context.Context, reply *model.ReviewRe
 
 func (r *ReviewerRepo) AddAppealReview(ctx context.Context, appeal *model.ReviewAppealInfo) (int64, error) {
 	// 插入一条申诉记录
-	err := r.data.query.ReviewAppealInfo.
+	err := r.writer(r.data.shards.ShardIndex(appeal.StoreID)).ReviewAppealInfo.
 		WithContext(ctx).
 		Save(appeal)
 	if err != nil {
@@ -155,21 +283,34 @@ func (r *ReviewerRepo) AddAppealReview(ctx context.Context, appeal *model.Review
 }
 
 func (r *ReviewerRepo) GetAppealByReviewID(ctx context.Context, reviewID int64) ([]*model.ReviewAppealInfo, error) {
-	data, err := r.data.query.ReviewAppealInfo.
-		WithContext(ctx).
-		Where(r.data.query.ReviewAppealInfo.ReviewID.Eq(reviewID)).
-		Find()
+	data, err := scatter(ctx, r.data.shards, func(ctx context.Context, shard int) ([]*model.ReviewAppealInfo, error) {
+		q := r.reader(ctx, shard)
+		return q.ReviewAppealInfo.WithContext(ctx).FindByReviewID(reviewID)
+	})
 	if err != nil {
 		return nil, v1.ErrorDbFailed("DB error while finding %v", reviewID)
 	}
 	return data, nil
 }
 
-func (r *ReviewerRepo) UpdateAppealByAppealID(ctx context.Context, appeal *model.ReviewAppealInfo) (*model.ReviewAppealInfo, error) {
-	_, err := r.data.query.ReviewAppealInfo.
-		WithContext(ctx).
-		Where(r.data.query.ReviewAppealInfo.AppealID.Eq(appeal.AppealID)).
-		Updates(appeal)
+// UpdateAppealByAppealID 申诉所在的分片未知，在所有分片上执行，事件只写入申诉所在的分片
+func (r *ReviewerRepo) UpdateAppealByAppealID(ctx context.Context, appeal *model.ReviewAppealInfo, events ...*event.Envelope) (*model.ReviewAppealInfo, error) {
+	_, err := scatter(ctx, r.data.shards, func(ctx context.Context, shard int) ([]int64, error) {
+		return nil, r.writer(shard).Transaction(func(tx *query.Query) error {
+			exist, err := tx.ReviewAppealInfo.WithContext(ctx).FindByAppealID(appeal.AppealID)
+			if err != nil || len(exist) == 0 {
+				return err
+			}
+			_, err = tx.ReviewAppealInfo.
+				WithContext(ctx).
+				Where(tx.ReviewAppealInfo.AppealID.Eq(appeal.AppealID)).
+				Updates(appeal)
+			if err != nil {
+				return err
+			}
+			return saveEvents(ctx, tx, events)
+		})
+	})
 	if err != nil {
 		return &model.ReviewAppealInfo{}, err
 	}
@@ -178,10 +319,10 @@ func (r *ReviewerRepo) UpdateAppealByAppealID(ctx context.Context, appeal *model
 
 // 通过申诉 ID 获取申诉信息
 func (r *ReviewerRepo) GetAppealByAppealID(ctx context.Context, appealID int64) ([]*model.ReviewAppealInfo, error) {
-	info, err := r.data.query.ReviewAppealInfo.
-		WithContext(ctx).
-		Where(r.data.query.ReviewAppealInfo.AppealID.Eq(appealID)).
-		Find()
+	info, err := scatter(ctx, r.data.shards, func(ctx context.Context, shard int) ([]*model.ReviewAppealInfo, error) {
+		q := r.reader(ctx, shard)
+		return q.ReviewAppealInfo.WithContext(ctx).FindByAppealID(appealID)
+	})
 	if err != nil {
 		return nil, v1.ErrorIdErr("Do not exist AppealID: %v", appealID)
 	}
@@ -190,6 +331,10 @@ func (r *ReviewerRepo) GetAppealByAppealID(ctx context.Context, appealID int64)
 
 // 根据 StoreID offset 和 limit 获取评论列表
 func (r *ReviewerRepo) ListReviewByStoreID(ctx context.Context, storeID int64, offset int32, limit int32) ([]*biz.MyReviewInfo, error) {
+	// 布隆过滤器中不存在的 storeID 直接返回，不再访问 ES
+	if !r.mightExist(ctx, r.data.cache.bloomStoreKey, storeID) {
+		return []*biz.MyReviewInfo{}, nil
+	}
 	return r.getData(ctx, storeID, offset, limit)
 	//// 去 ES 中查询评价
 	//resp, err := r.data.es.Search().
@@ -228,21 +373,20 @@ var g singleflight.Group
 
 func (r *ReviewerRepo) getData(ctx context.Context, storeID int64, offset int32, limit int32) ([]*biz.MyReviewInfo, error) {
 	// 使用 singleflight 取数据，防止缓存击穿
-	data, err := r.getDataFromSingleFlight(ctx, "review:"+strconv.FormatInt(storeID, 10)+":"+strconv.Itoa(int(offset))+":"+strconv.Itoa(int(limit)))
+	data, err := r.getDataFromSingleFlight(ctx, "review_list:"+strconv.FormatInt(storeID, 10)+":"+strconv.Itoa(int(offset))+":"+strconv.Itoa(int(limit)))
 	if err != nil {
 		return nil, err
 	}
-	hm := new(types.HitsMetadata)
-	err = json.Unmarshal(data, hm)
+	result := new(search.Result)
+	err = json.Unmarshal(data, result)
 	if err != nil {
 		return nil, err
 	}
-	rv := make([]*biz.MyReviewInfo, 0, hm.Total.Value)
+	rv := make([]*biz.MyReviewInfo, 0, len(result.Hits))
 
 	// 反序列化数据
-	for _, hit := range hm.Hits {
-		temp := &biz.MyReviewInfo{}
-		err := json.Unmarshal(hit.Source_, temp)
+	for _, hit := range result.Hits {
+		temp, err := decodeReview(hit.Source)
 		if err != nil {
 			r.log.Errorf("json unmarshal error: %v", err)
 			continue
@@ -253,7 +397,7 @@ func (r *ReviewerRepo) getData(ctx context.Context, storeID int64, offset int32,
 }
 
 // 使用 singleflight 防止缓存击穿
-// key: review:storeID:page:pageSize
+// key: review_list:storeID:offset:limit
 func (r *ReviewerRepo) getDataFromSingleFlight(ctx context.Context, key string) ([]byte, error) {
 	v, err, _ := g.Do(key, func() (interface{}, error) {
 		// 查询数据库
@@ -263,12 +407,16 @@ func (r *ReviewerRepo) getDataFromSingleFlight(ctx context.Context, key string)
 			return data, nil
 		}
 		if errors.Is(err, redis.Nil) {
-			// 缓存中没有此数据，需要查询 ES
-			esData, err := r.getDataES(ctx, key)
+			// 缓存中没有此数据，需要查询索引
+			indexData, empty, err := r.getDataFromIndex(ctx, key)
 			if err != nil {
 				return nil, err
 			}
-			return esData, r.setCache(ctx, key, esData)
+			// 空结果只缓存较短时间
+			if empty {
+				return indexData, r.setCacheWithTTL(ctx, key, indexData, r.data.cache.nullTTL)
+			}
+			return indexData, r.setCache(ctx, key, indexData)
 		}
 		// redis 查询出错
 		return nil, err
@@ -285,43 +433,42 @@ func (r *ReviewerRepo) getDataFromCache(ctx context.Context, key string) ([]byte
 }
 
 func (r *ReviewerRepo) setCache(ctx context.Context, key string, data []byte) error {
-	return r.data.redis.Set(ctx, key, data, time.Minute*5).Err()
+	return r.setCacheWithTTL(ctx, key, data, time.Minute*5)
 }
 
-func (r *ReviewerRepo) getDataES(ctx context.Context, key string) ([]byte, error) {
+func (r *ReviewerRepo) setCacheWithTTL(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return r.data.redis.Set(ctx, key, data, ttl).Err()
+}
+
+// getDataFromIndex 查询搜索索引，同时返回结果是否为空
+func (r *ReviewerRepo) getDataFromIndex(ctx context.Context, key string) ([]byte, bool, error) {
 	value := strings.Split(key, ":")
 	// 对 key 的长度进行检查
-	if len(strings.Split(key, ":")) != 4 {
-		return nil, errors.New("key format error")
+	if len(value) != 4 {
+		return nil, false, errors.New("key format error")
 	}
-	index, storeIDStr, offsetStr, limitStr := value[0], value[1], value[2], value[3]
+	storeIDStr, offsetStr, limitStr := value[1], value[2], value[3]
 	// 进行类型转换
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil {
-		return nil, errors.New("offset format error")
+		return nil, false, errors.New("offset format error")
 	}
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil {
-		return nil, errors.New("limit format error")
-	}
-	// 去 ES 中查询评价
-	resp, err := r.data.es.Search().
-		Index(index).
-		From(int(offset)).
-		Size(int(limit)).Query(&types.Query{
-		Bool: &types.BoolQuery{
-			Filter: []types.Query{
-				{
-					Term: map[string]types.TermQuery{
-						"store_id": {Value: storeIDStr},
-					},
-				},
-			},
-		},
-	}).Do(ctx)
+		return nil, false, errors.New("limit format error")
+	}
+	// 去索引中查询评价
+	resp, err := r.data.index.Query(ctx, &search.Query{
+		Filters:  []search.Filter{{Field: "store_id", Values: []string{storeIDStr}}},
+		Excludes: visibleFilter(),
+		From:     offset,
+		Size:     limit,
+	})
 	if err != nil {
-		return nil, v1.ErrorDbFailed("ES search error")
+		r.log.WithContext(ctx).Errorf("list reviews from index failed, err: %v", err)
+		return nil, false, v1.ErrorDbFailed("ES search error")
 	}
 
-	return json.Marshal(resp.Hits)
+	data, err := json.Marshal(resp)
+	return data, len(resp.Hits) == 0, err
 }
@@ -0,0 +1,27 @@
+package data_test
+
+import (
+	"testing"
+
+	"review-service/internal/data/repotest"
+)
+
+// TestReviewerRepo 在 SQLite 和 miniredis 上运行 gorm 实现的契约用例
+func TestReviewerRepo(t *testing.T) {
+	repotest.Run(t, repotest.NewGormRepo)
+}
+
+// TestShardedReviewerRepo 开启分表后行为与单表一致
+func TestShardedReviewerRepo(t *testing.T) {
+	repotest.Run(t, repotest.NewShardedGormRepo)
+}
+
+// TestReviewOutbox 带有事件的写操作与事件在同一个事务中写入发件箱
+func TestReviewOutbox(t *testing.T) {
+	repotest.RunOutbox(t, repotest.NewGormOutboxRepo)
+}
+
+// TestShardedReviewOutbox 开启分表后事件写入评价所在分片的发件箱
+func TestShardedReviewOutbox(t *testing.T) {
+	repotest.RunOutbox(t, repotest.NewShardedGormOutboxRepo)
+}
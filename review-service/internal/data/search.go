@@ -0,0 +1,299 @@
+package data
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	v1 "review-service/api/review/v1"
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+	"review-service/internal/data/model"
+	"review-service/pkg/search"
+)
+
+const (
+	defaultESIndex      = "review"
+	defaultRecencyScale = "30d"
+	backendBleve        = "bleve"
+)
+
+// hiddenStatus 不对外展示的评价状态：30 审核不通过，40 隐藏
+var hiddenStatus = []string{"30", "40"}
+
+// searchOption 搜索相关配置
+type searchOption struct {
+	analyzer     string
+	recencyScale string
+	indexOnWrite bool
+}
+
+func newSearchOption(c *conf.Data) *searchOption {
+	opt := &searchOption{recencyScale: defaultRecencyScale}
+	if es := c.Elasticsearch; es != nil {
+		if es.RecencyScale != "" {
+			opt.recencyScale = es.RecencyScale
+		}
+		opt.analyzer = es.Analyzer
+	}
+	opt.indexOnWrite = c.Search.GetIndexOnWrite()
+	return opt
+}
+
+// NewSearchIndex 按配置创建搜索后端，默认使用 ES
+func NewSearchIndex(c *conf.Data, logger log.Logger) (search.Index, func(), error) {
+	helper := log.NewHelper(logger)
+	var (
+		index search.Index
+		err   error
+	)
+	if c.Search.GetBackend() == backendBleve {
+		index, err = search.NewBleve(c.Search.GetPath(), search.ReviewMapping)
+		if err != nil {
+			return nil, nil, err
+		}
+		helper.Infof("open bleve index success: %q", c.Search.GetPath())
+	} else {
+		// ES 配置
+		client, err := elasticsearch.NewTypedClient(elasticsearch.Config{
+			Addresses: c.Elasticsearch.GetAddr(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		name := defaultESIndex
+		if c.Elasticsearch.GetIndex() != "" {
+			name = c.Elasticsearch.GetIndex()
+		}
+		index = search.NewElasticsearch(client, name)
+	}
+	cleanup := func() {
+		if err := index.Close(); err != nil {
+			helper.Errorf("close search index failed, err: %v", err)
+		}
+	}
+	return index, cleanup, nil
+}
+
+// visibleFilter 列表和搜索共用的可见性规则：排除已删除、审核不通过和隐藏的评价
+func visibleFilter() []search.Filter {
+	return []search.Filter{
+		{Field: "delete_at", Exists: true},
+		{Field: "status", Values: hiddenStatus},
+	}
+}
+
+// SearchReviews 按关键词搜索评价
+// 相关度得分乘以按 create_at 的高斯衰减，越新的评价越靠前；使用 search_after 游标翻页，
+// 衰减的原点固定为第一页的查询时间并记录在游标中，翻页时得分不变，不会跳过或重复结果
+func (r *ReviewerRepo) SearchReviews(ctx context.Context, param *biz.SearchParam) (*biz.SearchResult, error) {
+	opt := r.data.search
+	origin := time.Now()
+	q := &search.Query{
+		Match:    &search.Match{Field: "content", Text: param.Query, Analyzer: opt.analyzer},
+		Excludes: visibleFilter(),
+		Sort: []search.Sort{
+			{Field: search.ScoreField, Desc: true},
+			{Field: "review_id", Desc: true},
+		},
+		Size:      int(param.Size),
+		Highlight: "content",
+	}
+	if param.StoreID > 0 {
+		q.Filters = append(q.Filters, search.Filter{Field: "store_id", Values: []string{strconv.FormatInt(param.StoreID, 10)}})
+	}
+	if param.SpuID > 0 {
+		q.Filters = append(q.Filters, search.Filter{Field: "spu_id", Values: []string{strconv.FormatInt(param.SpuID, 10)}})
+	}
+	if param.MinScore > 0 {
+		gte := float64(param.MinScore)
+		q.Filters = append(q.Filters, search.Filter{Field: "score", Gte: &gte})
+	}
+	if param.HasMedia {
+		q.Filters = append(q.Filters, search.Filter{Field: "has_media", Values: []string{"true"}})
+	}
+	if param.Cursor != "" {
+		after, at, err := decodeCursor(param.Cursor)
+		if err != nil {
+			return nil, errors.BadRequest("INVALID_ARGUMENT", "invalid cursor")
+		}
+		q.After, origin = after, at
+	}
+	q.Decay = &search.Decay{Field: "create_at", Scale: opt.recencyScale, Origin: origin}
+
+	resp, err := r.data.index.Query(ctx, q)
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("search reviews failed, err: %v", err)
+		return nil, v1.ErrorDbFailed("ES search error")
+	}
+
+	result := &biz.SearchResult{Hits: make([]*biz.SearchHit, 0, len(resp.Hits)), Total: resp.Total}
+	var last *biz.SearchHit
+	for _, hit := range resp.Hits {
+		info, err := decodeReview(hit.Source)
+		if err != nil {
+			r.log.Errorf("json unmarshal error: %v", err)
+			continue
+		}
+		h := &biz.SearchHit{MyReviewInfo: info, Highlight: hit.Highlight, Relevance: hit.Score}
+		result.Hits = append(result.Hits, h)
+		last = h
+	}
+	if last != nil && len(resp.Hits) == int(param.Size) {
+		result.NextCursor = encodeCursor(last.Relevance, last.ReviewID, origin)
+	}
+	return result, nil
+}
+
+// refreshIndex 开启 index_on_write 时将写入后的评价同步到索引
+// 以 upsert 合并写入，保留 review-job 从回复表合并进来的字段；
+// 索引只是数据库的副本，失败时只记录日志，不影响写操作的结果
+func (r *ReviewerRepo) refreshIndex(ctx context.Context, reviews ...*model.ReviewInfo) {
+	ops := make([]search.Op, 0, len(reviews))
+	for _, rv := range reviews {
+		id := strconv.FormatInt(rv.ReviewID, 10)
+		doc, err := reviewDocument(rv)
+		if err != nil {
+			r.log.WithContext(ctx).Errorf("convert review %s failed, err: %v", id, err)
+			continue
+		}
+		ops = append(ops, search.Op{Action: search.ActionUpsert, ID: id, Doc: doc})
+	}
+	if len(ops) == 0 {
+		return
+	}
+	errs, err := r.data.index.Bulk(ctx, ops)
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("index %d reviews failed, err: %v", len(ops), err)
+		return
+	}
+	for i, err := range errs {
+		if err != nil {
+			r.log.WithContext(ctx).Errorf("index review %s failed, err: %v", ops[i].ID, err)
+		}
+	}
+}
+
+// reviewDocument 将评价转换为与 review-job 写入一致的文档
+// has_reply 由 review-job 根据回复表维护，不在这里写入
+func reviewDocument(rv *model.ReviewInfo) (search.Document, error) {
+	doc := &search.ReviewDoc{
+		ID:             rv.ID,
+		CreateBy:       rv.CreateBy,
+		UpdateBy:       rv.UpdateBy,
+		CreateAt:       rv.CreateAt,
+		UpdateAt:       rv.UpdateAt,
+		DeleteAt:       rv.DeleteAt,
+		Version:        rv.Version,
+		ReviewID:       rv.ReviewID,
+		Content:        rv.Content,
+		Score:          rv.Score,
+		ServiceScore:   rv.ServiceScore,
+		ExpressScore:   rv.ExpressScore,
+		HasMedia:       rv.HasMedia == 1,
+		OrderID:        rv.OrderID,
+		SkuID:          rv.SkuID,
+		SpuID:          rv.SpuID,
+		StoreID:        rv.StoreID,
+		UserID:         rv.UserID,
+		Anonymous:      rv.Anonymous == 1,
+		Tags:           search.ParseJSON(rv.Tags),
+		PicInfo:        search.ParseJSON(rv.PicInfo),
+		VideoInfo:      search.ParseJSON(rv.VideoInfo),
+		Status:         rv.Status,
+		IsDefault:      rv.IsDefault == 1,
+		OpReason:       rv.OpReason,
+		OpRemarks:      rv.OpRemarks,
+		OpUser:         rv.OpUser,
+		GoodsSnapshoot: rv.GoodsSnapshoot,
+		ExtJSON:        rv.ExtJSON,
+		CtrlJSON:       rv.CtrlJSON,
+	}
+	return doc.Document()
+}
+
+// decodeReview 将索引中的文档还原为评价
+func decodeReview(source json.RawMessage) (*biz.MyReviewInfo, error) {
+	doc := new(search.ReviewDoc)
+	if err := json.Unmarshal(source, doc); err != nil {
+		return nil, err
+	}
+	return &biz.MyReviewInfo{ReviewInfo: &model.ReviewInfo{
+		ID:             doc.ID,
+		CreateBy:       doc.CreateBy,
+		UpdateBy:       doc.UpdateBy,
+		CreateAt:       doc.CreateAt,
+		UpdateAt:       doc.UpdateAt,
+		DeleteAt:       doc.DeleteAt,
+		Version:        doc.Version,
+		ReviewID:       doc.ReviewID,
+		Content:        doc.Content,
+		Score:          doc.Score,
+		ServiceScore:   doc.ServiceScore,
+		ExpressScore:   doc.ExpressScore,
+		HasMedia:       flag(doc.HasMedia),
+		OrderID:        doc.OrderID,
+		SkuID:          doc.SkuID,
+		SpuID:          doc.SpuID,
+		StoreID:        doc.StoreID,
+		UserID:         doc.UserID,
+		Anonymous:      flag(doc.Anonymous),
+		Tags:           search.JSONText(doc.Tags),
+		PicInfo:        search.JSONText(doc.PicInfo),
+		VideoInfo:      search.JSONText(doc.VideoInfo),
+		Status:         doc.Status,
+		IsDefault:      flag(doc.IsDefault),
+		HasReply:       flag(doc.HasReply),
+		OpReason:       doc.OpReason,
+		OpRemarks:      doc.OpRemarks,
+		OpUser:         doc.OpUser,
+		GoodsSnapshoot: doc.GoodsSnapshoot,
+		ExtJSON:        doc.ExtJSON,
+		CtrlJSON:       doc.CtrlJSON,
+	}}, nil
+}
+
+func flag(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// encodeCursor 游标为最后一条结果的排序值 [_score, review_id] 和衰减的原点（毫秒时间戳）
+// review_id 取自文档本身而不是响应中的 sort 字段，避免雪花 ID 经 float64 解析丢失精度
+func encodeCursor(score float64, reviewID int64, origin time.Time) string {
+	data, _ := json.Marshal([]interface{}{score, json.Number(strconv.FormatInt(reviewID, 10)), origin.UnixMilli()})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 返回游标中的排序值和衰减的原点；没有原点的旧游标以当前时间为原点
+func decodeCursor(cursor string) ([]string, time.Time, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var after []json.Number
+	if err := json.Unmarshal(data, &after); err != nil {
+		return nil, time.Time{}, err
+	}
+	origin := time.Now()
+	switch len(after) {
+	case 2:
+	case 3:
+		ms, err := after[2].Int64()
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		origin = time.UnixMilli(ms)
+	default:
+		return nil, time.Time{}, fmt.Errorf("cursor has %d values", len(after))
+	}
+	return []string{after[0].String(), after[1].String()}, origin, nil
+}
@@ -0,0 +1,37 @@
+package data
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestCursorKeepsDecayOrigin 游标记录第一页的衰减原点，翻页时得分不随查询时间变化
+func TestCursorKeepsDecayOrigin(t *testing.T) {
+	origin := time.Date(2026, 10, 19, 8, 30, 15, 123456789, time.UTC)
+	after, got, err := decodeCursor(encodeCursor(1.25, 530000000000000123, origin))
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if want := []string{"1.25", "530000000000000123"}; !reflect.DeepEqual(after, want) {
+		t.Errorf("after = %v, want %v", after, want)
+	}
+	if want := origin.Truncate(time.Millisecond); !got.Equal(want) {
+		t.Errorf("origin = %v, want %v", got, want)
+	}
+
+	// 没有原点的旧游标仍然可以使用，以当前时间为原点
+	before := time.Now()
+	after, got, err = decodeCursor(base64.RawURLEncoding.EncodeToString([]byte(`[1.25,530000000000000123]`)))
+	if err != nil {
+		t.Fatalf("decodeCursor old cursor: %v", err)
+	}
+	if len(after) != 2 || got.Before(before) {
+		t.Errorf("old cursor got %v, %v; want the sort values and the current time", after, got)
+	}
+
+	if _, _, err := decodeCursor(base64.RawURLEncoding.EncodeToString([]byte(`[1.25]`))); err == nil {
+		t.Error("decodeCursor accepted a cursor without review_id")
+	}
+}
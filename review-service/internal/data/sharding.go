@@ -0,0 +1,156 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"review-service/internal/conf"
+	"review-service/internal/data/model"
+	"review-service/internal/data/query"
+)
+
+// Sharding 按 store_id 将评价、回复、申诉表路由到 N 张物理分表
+// 分表 i 位于 databases[i % len(databases)]，未配置分库时全部位于主库；
+// 分库与主库一样配置连接池，并通过 dbresolver 把读操作路由到各自的从库
+// 未开启时只有一个分片，直接使用原表
+type Sharding struct {
+	enabled bool
+	count   int
+	dbs     []*gorm.DB
+	queries []*query.Query
+}
+
+func NewSharding(c *conf.Data, db *gorm.DB, logger log.Logger) (*Sharding, func(), error) {
+	helper := log.NewHelper(logger)
+	s := &Sharding{
+		count:   1,
+		dbs:     []*gorm.DB{db},
+		queries: []*query.Query{query.Use(db)},
+	}
+	cleanup := func() {}
+	sc := c.GetSharding()
+	if sc == nil || !sc.Enabled {
+		return s, cleanup, nil
+	}
+	if sc.TableCount <= 0 {
+		return nil, nil, fmt.Errorf("sharding table_count must be positive, got %d", sc.TableCount)
+	}
+	s.enabled = true
+	s.count = int(sc.TableCount)
+	if len(sc.Databases) > 0 {
+		s.dbs = s.dbs[:0]
+		s.queries = s.queries[:0]
+		for i, dsn := range sc.Databases {
+			var replicas []string
+			if i < len(sc.Replicas) {
+				replicas = sc.Replicas[i].GetDsn()
+			}
+			shardDB, err := OpenDB(c.GetDatabase(), dsn, replicas, logger)
+			if err != nil {
+				s.close()
+				return nil, nil, err
+			}
+			s.dbs = append(s.dbs, shardDB)
+			s.queries = append(s.queries, query.Use(shardDB))
+		}
+		cleanup = s.close
+	}
+	helper.Infof("sharding enabled, tables: %d, databases: %d", s.count, len(s.dbs))
+	return s, cleanup, nil
+}
+
+// close 关闭分库连接
+func (s *Sharding) close() {
+	for _, db := range s.dbs {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+}
+
+// Enabled 是否开启分表
+func (s *Sharding) Enabled() bool {
+	return s.enabled
+}
+
+// Count 分片数量
+func (s *Sharding) Count() int {
+	return s.count
+}
+
+// ShardIndex 计算 storeID 所在分片
+func (s *Sharding) ShardIndex(storeID int64) int {
+	if !s.enabled {
+		return 0
+	}
+	idx := storeID % int64(s.count)
+	if idx < 0 {
+		idx = -idx
+	}
+	return int(idx)
+}
+
+// TableName 逻辑表在第 idx 个分片上的物理表名
+func (s *Sharding) TableName(logical string, idx int) string {
+	if !s.enabled {
+		return logical
+	}
+	return fmt.Sprintf("%s_%02d", logical, idx)
+}
+
+// DB 第 idx 个分片所在的数据库
+func (s *Sharding) DB(idx int) *gorm.DB {
+	return s.dbs[idx%len(s.dbs)]
+}
+
+// Query 第 idx 个分片的查询对象，read 为 true 时走从库
+// 注意 ReadDB/WriteDB 会重置表名，所以分表名要在之后设置；发件箱 review_outbox 每个库一张，不分表
+func (s *Sharding) Query(idx int, read bool) *query.Query {
+	q := s.queries[idx%len(s.queries)]
+	if read {
+		q = q.ReadDB()
+	} else {
+		q = q.WriteDB()
+	}
+	if !s.enabled {
+		return q
+	}
+	q.ReviewInfo = *q.ReviewInfo.Table(s.TableName(model.TableNameReviewInfo, idx))
+	q.ReviewReplyInfo = *q.ReviewReplyInfo.Table(s.TableName(model.TableNameReviewReplyInfo, idx))
+	q.ReviewAppealInfo = *q.ReviewAppealInfo.Table(s.TableName(model.TableNameReviewAppealInfo, idx))
+	return q
+}
+
+// scatter 在所有分片上并发执行 fn，合并各分片的结果
+// 用于 user_id、review_id 等无法确定分片的查询
+func scatter[T any](ctx context.Context, s *Sharding, fn func(ctx context.Context, idx int) ([]T, error)) ([]T, error) {
+	if s.count == 1 {
+		return fn(ctx, 0)
+	}
+	var (
+		mu  sync.Mutex
+		all []T
+	)
+	eg, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < s.count; i++ {
+		idx := i
+		eg.Go(func() error {
+			rows, err := fn(ctx, idx)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			all = append(all, rows...)
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
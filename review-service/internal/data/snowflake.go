@@ -0,0 +1,42 @@
+package data
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+	"review-service/internal/conf"
+	"review-service/pkg/snowflake"
+)
+
+// NewSnowflake 创建并返回雪花ID生成器
+// 开启租约时从 Redis 动态获取 workerID，退出时释放
+func NewSnowflake(c *conf.Data, rdb *redis.Client, logger log.Logger) (*snowflake.Snowflake, func(), error) {
+	helper := log.NewHelper(logger)
+	var opts []snowflake.Option
+	if c.Snowflake.MaxBackwards != nil {
+		opts = append(opts, snowflake.WithMaxBackwards(c.Snowflake.MaxBackwards.AsDuration()))
+	}
+	if !c.Snowflake.Lease {
+		sf, err := snowflake.NewSnowflake(c.Snowflake.WorkerID, c.Snowflake.DataCenterID, opts...)
+		return sf, func() {}, err
+	}
+
+	lease, err := snowflake.AcquireLease(context.Background(), rdb, c.Snowflake.LeasePrefix, c.Snowflake.LeaseTtl.AsDuration())
+	if err != nil {
+		return nil, nil, err
+	}
+	helper.Infof("snowflake lease acquired, workerID: %d, dataCenterID: %d", lease.WorkerID(), lease.DataCenterID())
+	sf, err := snowflake.NewSnowflakeWithLease(lease, opts...)
+	if err != nil {
+		_ = lease.Release(context.Background())
+		return nil, nil, err
+	}
+	cleanup := func() {
+		helper.Info("releasing the snowflake lease")
+		if err := lease.Release(context.Background()); err != nil {
+			helper.Errorf("release snowflake lease failed: %v", err)
+		}
+	}
+	return sf, cleanup, nil
+}
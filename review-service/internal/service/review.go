@@ -26,8 +26,8 @@ func (s *ReviewService) CreateReview(ctx context.Context, req *pb.CreateReviewRe
 		anonymous = 1
 	}
 
-	// 信息填入结构体
-	data, err := s.uc.CreateReviewer(ctx, &model.ReviewInfo{
+	// 信息填入结构体，开启异步创建时只受理，通过 GetReviewStatus 查询进度
+	data, status, err := s.uc.SubmitReview(ctx, &model.ReviewInfo{
 		CreateBy:     strconv.FormatInt(req.UserID, 10),
 		UpdateBy:     strconv.FormatInt(req.UserID, 10),
 		CreateAt:     time.Now(),
@@ -51,6 +51,20 @@ func (s *ReviewService) CreateReview(ctx context.Context, req *pb.CreateReviewRe
 	// 返回数据
 	return &pb.CreateReviewReply{
 		ReviewID: data.ReviewID,
+		Status:   status,
+	}, nil
+}
+
+// 查询评价的创建进度：accepted 已受理，created 已创建，failed 创建失败
+func (s *ReviewService) GetReviewStatus(ctx context.Context, req *pb.GetReviewStatusRequest) (*pb.GetReviewStatusReply, error) {
+	status, err := s.uc.GetReviewStatus(ctx, req.ReviewID)
+	if err != nil {
+		return &pb.GetReviewStatusReply{}, err
+	}
+	return &pb.GetReviewStatusReply{
+		ReviewID: status.ReviewID,
+		Status:   status.Status,
+		Reason:   status.Reason,
 	}, nil
 }
 
@@ -77,8 +91,19 @@ func (s *ReviewService) UpdateReview(ctx context.Context, req *pb.UpdateReviewRe
 		ReviewID: reviewId,
 	}, nil
 }
+// 删除评价：与之前一样按主键 ID 删除，ID 为 0 时按 ReviewID 删除
+// 开启分表后主键只在分片内唯一，新的调用方应传 ReviewID
 func (s *ReviewService) DeleteReview(ctx context.Context, req *pb.DeleteReviewRequest) (*pb.DeleteReviewReply, error) {
-	err := s.uc.DeleteReviewer(ctx, req.ID)
+	if req.ID != 0 {
+		reviewID, err := s.uc.DeleteReviewByID(ctx, req.ID)
+		if err != nil {
+			return &pb.DeleteReviewReply{}, err
+		}
+		return &pb.DeleteReviewReply{
+			ReviewID: reviewID,
+		}, nil
+	}
+	err := s.uc.DeleteReviewer(ctx, req.ReviewID)
 	if err != nil {
 		return &pb.DeleteReviewReply{}, err
 	}
@@ -234,3 +259,48 @@ func (s *ReviewService) ListReviewByStoreID(ctx context.Context, req *pb.ListRev
 		Reviews: list,
 	}, nil
 }
+
+// 按关键词搜索店铺或商品的评价
+func (s *ReviewService) SearchReviews(ctx context.Context, req *pb.SearchReviewsRequest) (*pb.SearchReviewsReply, error) {
+	result, err := s.uc.SearchReviews(ctx, &biz.SearchParam{
+		Query:    req.Query,
+		StoreID:  req.StoreID,
+		SpuID:    req.SpuID,
+		MinScore: req.MinScore,
+		HasMedia: req.HasMedia,
+		Cursor:   req.Cursor,
+		Size:     req.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*pb.SearchReviewHit, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		var anonymous bool
+		if hit.Anonymous == 1 {
+			anonymous = true
+		}
+		list = append(list, &pb.SearchReviewHit{
+			Review: &pb.ReviewInfo{
+				UserID:       hit.UserID,
+				OrderID:      hit.OrderID,
+				Score:        hit.Score,
+				ServiceScore: hit.ServiceScore,
+				ExpressScore: hit.ExpressScore,
+				Content:      hit.Content,
+				PicInfo:      hit.PicInfo,
+				VideoInfo:    hit.VideoInfo,
+				Anonymous:    anonymous,
+				CreateTime:   timestamppb.New(hit.CreateAt),
+				UpdateTime:   timestamppb.New(hit.UpdateAt),
+			},
+			Highlight: hit.Highlight,
+			Relevance: hit.Relevance,
+		})
+	}
+	return &pb.SearchReviewsReply{
+		Reviews:    list,
+		Total:      result.Total,
+		NextCursor: result.NextCursor,
+	}, nil
+}
@@ -0,0 +1,20 @@
+package event
+
+import "strconv"
+
+// 异步创建评价的进度，记录在 redis 的 hash 中，字段为 status 和 reason
+const (
+	CreateAccepted = "accepted" // 已受理，等待 review-job 写入
+	CreateCreated  = "created"  // 已写入 MySQL
+	CreateFailed   = "failed"   // 无法写入，原因见 reason，如订单已经评价过
+)
+
+// CreateStatusKey 评价创建进度的 redis key
+func CreateStatusKey(reviewID int64) string {
+	return "review:create:" + strconv.FormatInt(reviewID, 10)
+}
+
+// CreateOrderKey 受理中的评价占用的订单，防止同一个订单重复提交；写入失败时由 review-job 释放
+func CreateOrderKey(orderID int64) string {
+	return "review:create:order:" + strconv.FormatInt(orderID, 10)
+}
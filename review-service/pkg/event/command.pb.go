@@ -0,0 +1,287 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v4.25.3
+// source: pkg/event/command.proto
+
+package event
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CreateReviewCommand 创建一条评价，字段与 review_info 表一致
+type CreateReviewCommand struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ReviewId     int64                  `protobuf:"varint,1,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
+	OrderId      int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	StoreId      int64                  `protobuf:"varint,3,opt,name=store_id,json=storeId,proto3" json:"store_id,omitempty"`
+	UserId       int64                  `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	SkuId        int64                  `protobuf:"varint,5,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	SpuId        int64                  `protobuf:"varint,6,opt,name=spu_id,json=spuId,proto3" json:"spu_id,omitempty"`
+	Score        int32                  `protobuf:"varint,7,opt,name=score,proto3" json:"score,omitempty"`
+	ServiceScore int32                  `protobuf:"varint,8,opt,name=service_score,json=serviceScore,proto3" json:"service_score,omitempty"`
+	ExpressScore int32                  `protobuf:"varint,9,opt,name=express_score,json=expressScore,proto3" json:"express_score,omitempty"`
+	Content      string                 `protobuf:"bytes,10,opt,name=content,proto3" json:"content,omitempty"`
+	PicInfo      string                 `protobuf:"bytes,11,opt,name=pic_info,json=picInfo,proto3" json:"pic_info,omitempty"`
+	VideoInfo    string                 `protobuf:"bytes,12,opt,name=video_info,json=videoInfo,proto3" json:"video_info,omitempty"`
+	HasMedia     int32                  `protobuf:"varint,13,opt,name=has_media,json=hasMedia,proto3" json:"has_media,omitempty"`
+	Anonymous    int32                  `protobuf:"varint,14,opt,name=anonymous,proto3" json:"anonymous,omitempty"`
+	Status       int32                  `protobuf:"varint,15,opt,name=status,proto3" json:"status,omitempty"`
+	CreateBy     string                 `protobuf:"bytes,16,opt,name=create_by,json=createBy,proto3" json:"create_by,omitempty"`
+	// 受理的时间，作为评价的创建时间
+	AcceptedAt *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=accepted_at,json=acceptedAt,proto3" json:"accepted_at,omitempty"`
+	// 与评价在同一个事务中写入发件箱的 review.created 事件
+	Created       *Envelope `protobuf:"bytes,18,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateReviewCommand) Reset() {
+	*x = CreateReviewCommand{}
+	mi := &file_pkg_event_command_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateReviewCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReviewCommand) ProtoMessage() {}
+
+func (x *CreateReviewCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_event_command_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReviewCommand.ProtoReflect.Descriptor instead.
+func (*CreateReviewCommand) Descriptor() ([]byte, []int) {
+	return file_pkg_event_command_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateReviewCommand) GetReviewId() int64 {
+	if x != nil {
+		return x.ReviewId
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetStoreId() int64 {
+	if x != nil {
+		return x.StoreId
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetSkuId() int64 {
+	if x != nil {
+		return x.SkuId
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetSpuId() int64 {
+	if x != nil {
+		return x.SpuId
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetServiceScore() int32 {
+	if x != nil {
+		return x.ServiceScore
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetExpressScore() int32 {
+	if x != nil {
+		return x.ExpressScore
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *CreateReviewCommand) GetPicInfo() string {
+	if x != nil {
+		return x.PicInfo
+	}
+	return ""
+}
+
+func (x *CreateReviewCommand) GetVideoInfo() string {
+	if x != nil {
+		return x.VideoInfo
+	}
+	return ""
+}
+
+func (x *CreateReviewCommand) GetHasMedia() int32 {
+	if x != nil {
+		return x.HasMedia
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetAnonymous() int32 {
+	if x != nil {
+		return x.Anonymous
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *CreateReviewCommand) GetCreateBy() string {
+	if x != nil {
+		return x.CreateBy
+	}
+	return ""
+}
+
+func (x *CreateReviewCommand) GetAcceptedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AcceptedAt
+	}
+	return nil
+}
+
+func (x *CreateReviewCommand) GetCreated() *Envelope {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+var File_pkg_event_command_proto protoreflect.FileDescriptor
+
+const file_pkg_event_command_proto_rawDesc = "" +
+	"\n" +
+	"\x17pkg/event/command.proto\x12\x0freview.event.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x15pkg/event/event.proto\"\xc5\x04\n" +
+	"\x13CreateReviewCommand\x12\x1b\n" +
+	"\treview_id\x18\x01 \x01(\x03R\breviewId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12\x19\n" +
+	"\bstore_id\x18\x03 \x01(\x03R\astoreId\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\x03R\x06userId\x12\x15\n" +
+	"\x06sku_id\x18\x05 \x01(\x03R\x05skuId\x12\x15\n" +
+	"\x06spu_id\x18\x06 \x01(\x03R\x05spuId\x12\x14\n" +
+	"\x05score\x18\a \x01(\x05R\x05score\x12#\n" +
+	"\rservice_score\x18\b \x01(\x05R\fserviceScore\x12#\n" +
+	"\rexpress_score\x18\t \x01(\x05R\fexpressScore\x12\x18\n" +
+	"\acontent\x18\n" +
+	" \x01(\tR\acontent\x12\x19\n" +
+	"\bpic_info\x18\v \x01(\tR\apicInfo\x12\x1d\n" +
+	"\n" +
+	"video_info\x18\f \x01(\tR\tvideoInfo\x12\x1b\n" +
+	"\thas_media\x18\r \x01(\x05R\bhasMedia\x12\x1c\n" +
+	"\tanonymous\x18\x0e \x01(\x05R\tanonymous\x12\x16\n" +
+	"\x06status\x18\x0f \x01(\x05R\x06status\x12\x1b\n" +
+	"\tcreate_by\x18\x10 \x01(\tR\bcreateBy\x12;\n" +
+	"\vaccepted_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"acceptedAt\x123\n" +
+	"\acreated\x18\x12 \x01(\v2\x19.review.event.v1.EnvelopeR\acreatedB Z\x1ereview-service/pkg/event;eventb\x06proto3"
+
+var (
+	file_pkg_event_command_proto_rawDescOnce sync.Once
+	file_pkg_event_command_proto_rawDescData []byte
+)
+
+func file_pkg_event_command_proto_rawDescGZIP() []byte {
+	file_pkg_event_command_proto_rawDescOnce.Do(func() {
+		file_pkg_event_command_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_event_command_proto_rawDesc), len(file_pkg_event_command_proto_rawDesc)))
+	})
+	return file_pkg_event_command_proto_rawDescData
+}
+
+var file_pkg_event_command_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_pkg_event_command_proto_goTypes = []any{
+	(*CreateReviewCommand)(nil),   // 0: review.event.v1.CreateReviewCommand
+	(*timestamppb.Timestamp)(nil), // 1: google.protobuf.Timestamp
+	(*Envelope)(nil),              // 2: review.event.v1.Envelope
+}
+var file_pkg_event_command_proto_depIdxs = []int32{
+	1, // 0: review.event.v1.CreateReviewCommand.accepted_at:type_name -> google.protobuf.Timestamp
+	2, // 1: review.event.v1.CreateReviewCommand.created:type_name -> review.event.v1.Envelope
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_pkg_event_command_proto_init() }
+func file_pkg_event_command_proto_init() {
+	if File_pkg_event_command_proto != nil {
+		return
+	}
+	file_pkg_event_event_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_event_command_proto_rawDesc), len(file_pkg_event_command_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_pkg_event_command_proto_goTypes,
+		DependencyIndexes: file_pkg_event_command_proto_depIdxs,
+		MessageInfos:      file_pkg_event_command_proto_msgTypes,
+	}.Build()
+	File_pkg_event_command_proto = out.File
+	file_pkg_event_command_proto_goTypes = nil
+	file_pkg_event_command_proto_depIdxs = nil
+}
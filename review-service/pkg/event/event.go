@@ -0,0 +1,25 @@
+package event
+
+// 事件类型，与 Envelope.payload 中的类型一一对应
+const (
+	TypeReviewCreated  = "review.created"
+	TypeReviewApproved = "review.approved"
+	TypeAppealDecided  = "appeal.decided"
+)
+
+// AggregateReview 评价聚合，评价及其回复、申诉的事件都以 review_id 作为聚合 ID
+const AggregateReview = "review"
+
+// 触发事件的一方
+const (
+	ActorUser     = "user"
+	ActorStore    = "store"
+	ActorOperator = "operator"
+)
+
+// 投递到 kafka 时的消息头，消息的 key 为聚合 ID，value 为 proto 编码的 Envelope
+const (
+	HeaderEventID       = "event_id"
+	HeaderEventType     = "event_type"
+	HeaderAggregateType = "aggregate_type"
+)
@@ -0,0 +1,649 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v4.25.3
+// source: pkg/event/event.proto
+
+package event
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Envelope 事件的公共字段，每种事件对应 payload 中的一个类型
+type Envelope struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 事件 ID，雪花算法生成
+	EventId int64 `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	// 事件类型，如 review.created
+	EventType string `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	// 聚合类型，评价、回复和申诉的事件都归属于评价，为 review
+	AggregateType string `protobuf:"bytes,3,opt,name=aggregate_type,json=aggregateType,proto3" json:"aggregate_type,omitempty"`
+	// 聚合 ID，即 review_id
+	AggregateId int64                  `protobuf:"varint,4,opt,name=aggregate_id,json=aggregateId,proto3" json:"aggregate_id,omitempty"`
+	Actor       *Actor                 `protobuf:"bytes,5,opt,name=actor,proto3" json:"actor,omitempty"`
+	OccurredAt  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Envelope_ReviewCreated
+	//	*Envelope_ReviewApproved
+	//	*Envelope_AppealDecided
+	Payload       isEnvelope_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_pkg_event_event_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_event_event_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_pkg_event_event_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Envelope) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *Envelope) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *Envelope) GetAggregateType() string {
+	if x != nil {
+		return x.AggregateType
+	}
+	return ""
+}
+
+func (x *Envelope) GetAggregateId() int64 {
+	if x != nil {
+		return x.AggregateId
+	}
+	return 0
+}
+
+func (x *Envelope) GetActor() *Actor {
+	if x != nil {
+		return x.Actor
+	}
+	return nil
+}
+
+func (x *Envelope) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *Envelope) GetPayload() isEnvelope_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Envelope) GetReviewCreated() *ReviewCreated {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ReviewCreated); ok {
+			return x.ReviewCreated
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetReviewApproved() *ReviewApproved {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ReviewApproved); ok {
+			return x.ReviewApproved
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetAppealDecided() *AppealDecided {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_AppealDecided); ok {
+			return x.AppealDecided
+		}
+	}
+	return nil
+}
+
+type isEnvelope_Payload interface {
+	isEnvelope_Payload()
+}
+
+type Envelope_ReviewCreated struct {
+	ReviewCreated *ReviewCreated `protobuf:"bytes,10,opt,name=review_created,json=reviewCreated,proto3,oneof"`
+}
+
+type Envelope_ReviewApproved struct {
+	ReviewApproved *ReviewApproved `protobuf:"bytes,11,opt,name=review_approved,json=reviewApproved,proto3,oneof"`
+}
+
+type Envelope_AppealDecided struct {
+	AppealDecided *AppealDecided `protobuf:"bytes,12,opt,name=appeal_decided,json=appealDecided,proto3,oneof"`
+}
+
+func (*Envelope_ReviewCreated) isEnvelope_Payload() {}
+
+func (*Envelope_ReviewApproved) isEnvelope_Payload() {}
+
+func (*Envelope_AppealDecided) isEnvelope_Payload() {}
+
+// Actor 触发事件的一方
+type Actor struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user：用户，store：商家，operator：运营
+	Type          string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Actor) Reset() {
+	*x = Actor{}
+	mi := &file_pkg_event_event_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Actor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Actor) ProtoMessage() {}
+
+func (x *Actor) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_event_event_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Actor.ProtoReflect.Descriptor instead.
+func (*Actor) Descriptor() ([]byte, []int) {
+	return file_pkg_event_event_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Actor) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Actor) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// ReviewCreated 用户发表了评价
+type ReviewCreated struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ReviewId     int64                  `protobuf:"varint,1,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
+	OrderId      int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	StoreId      int64                  `protobuf:"varint,3,opt,name=store_id,json=storeId,proto3" json:"store_id,omitempty"`
+	UserId       int64                  `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	SkuId        int64                  `protobuf:"varint,5,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	SpuId        int64                  `protobuf:"varint,6,opt,name=spu_id,json=spuId,proto3" json:"spu_id,omitempty"`
+	Score        int32                  `protobuf:"varint,7,opt,name=score,proto3" json:"score,omitempty"`
+	ServiceScore int32                  `protobuf:"varint,8,opt,name=service_score,json=serviceScore,proto3" json:"service_score,omitempty"`
+	ExpressScore int32                  `protobuf:"varint,9,opt,name=express_score,json=expressScore,proto3" json:"express_score,omitempty"`
+	Content      string                 `protobuf:"bytes,10,opt,name=content,proto3" json:"content,omitempty"`
+	HasMedia     bool                   `protobuf:"varint,11,opt,name=has_media,json=hasMedia,proto3" json:"has_media,omitempty"`
+	Anonymous    bool                   `protobuf:"varint,12,opt,name=anonymous,proto3" json:"anonymous,omitempty"`
+	// 评价的状态，新发表的评价为 10 待审核
+	Status        int32 `protobuf:"varint,13,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewCreated) Reset() {
+	*x = ReviewCreated{}
+	mi := &file_pkg_event_event_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewCreated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewCreated) ProtoMessage() {}
+
+func (x *ReviewCreated) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_event_event_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewCreated.ProtoReflect.Descriptor instead.
+func (*ReviewCreated) Descriptor() ([]byte, []int) {
+	return file_pkg_event_event_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ReviewCreated) GetReviewId() int64 {
+	if x != nil {
+		return x.ReviewId
+	}
+	return 0
+}
+
+func (x *ReviewCreated) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *ReviewCreated) GetStoreId() int64 {
+	if x != nil {
+		return x.StoreId
+	}
+	return 0
+}
+
+func (x *ReviewCreated) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ReviewCreated) GetSkuId() int64 {
+	if x != nil {
+		return x.SkuId
+	}
+	return 0
+}
+
+func (x *ReviewCreated) GetSpuId() int64 {
+	if x != nil {
+		return x.SpuId
+	}
+	return 0
+}
+
+func (x *ReviewCreated) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ReviewCreated) GetServiceScore() int32 {
+	if x != nil {
+		return x.ServiceScore
+	}
+	return 0
+}
+
+func (x *ReviewCreated) GetExpressScore() int32 {
+	if x != nil {
+		return x.ExpressScore
+	}
+	return 0
+}
+
+func (x *ReviewCreated) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ReviewCreated) GetHasMedia() bool {
+	if x != nil {
+		return x.HasMedia
+	}
+	return false
+}
+
+func (x *ReviewCreated) GetAnonymous() bool {
+	if x != nil {
+		return x.Anonymous
+	}
+	return false
+}
+
+func (x *ReviewCreated) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+// ReviewApproved 运营审核通过了评价
+type ReviewApproved struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReviewId      int64                  `protobuf:"varint,1,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
+	StoreId       int64                  `protobuf:"varint,2,opt,name=store_id,json=storeId,proto3" json:"store_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	OpUser        string                 `protobuf:"bytes,4,opt,name=op_user,json=opUser,proto3" json:"op_user,omitempty"`
+	OpRemarks     string                 `protobuf:"bytes,5,opt,name=op_remarks,json=opRemarks,proto3" json:"op_remarks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewApproved) Reset() {
+	*x = ReviewApproved{}
+	mi := &file_pkg_event_event_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewApproved) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewApproved) ProtoMessage() {}
+
+func (x *ReviewApproved) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_event_event_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewApproved.ProtoReflect.Descriptor instead.
+func (*ReviewApproved) Descriptor() ([]byte, []int) {
+	return file_pkg_event_event_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReviewApproved) GetReviewId() int64 {
+	if x != nil {
+		return x.ReviewId
+	}
+	return 0
+}
+
+func (x *ReviewApproved) GetStoreId() int64 {
+	if x != nil {
+		return x.StoreId
+	}
+	return 0
+}
+
+func (x *ReviewApproved) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ReviewApproved) GetOpUser() string {
+	if x != nil {
+		return x.OpUser
+	}
+	return ""
+}
+
+func (x *ReviewApproved) GetOpRemarks() string {
+	if x != nil {
+		return x.OpRemarks
+	}
+	return ""
+}
+
+// AppealDecided 运营处理了商家的申诉
+type AppealDecided struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	AppealId int64                  `protobuf:"varint,1,opt,name=appeal_id,json=appealId,proto3" json:"appeal_id,omitempty"`
+	ReviewId int64                  `protobuf:"varint,2,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
+	StoreId  int64                  `protobuf:"varint,3,opt,name=store_id,json=storeId,proto3" json:"store_id,omitempty"`
+	// 20 申诉通过；30 申诉驳回
+	Status        int32  `protobuf:"varint,4,opt,name=status,proto3" json:"status,omitempty"`
+	OpUser        string `protobuf:"bytes,5,opt,name=op_user,json=opUser,proto3" json:"op_user,omitempty"`
+	OpRemarks     string `protobuf:"bytes,6,opt,name=op_remarks,json=opRemarks,proto3" json:"op_remarks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppealDecided) Reset() {
+	*x = AppealDecided{}
+	mi := &file_pkg_event_event_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppealDecided) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppealDecided) ProtoMessage() {}
+
+func (x *AppealDecided) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_event_event_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppealDecided.ProtoReflect.Descriptor instead.
+func (*AppealDecided) Descriptor() ([]byte, []int) {
+	return file_pkg_event_event_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AppealDecided) GetAppealId() int64 {
+	if x != nil {
+		return x.AppealId
+	}
+	return 0
+}
+
+func (x *AppealDecided) GetReviewId() int64 {
+	if x != nil {
+		return x.ReviewId
+	}
+	return 0
+}
+
+func (x *AppealDecided) GetStoreId() int64 {
+	if x != nil {
+		return x.StoreId
+	}
+	return 0
+}
+
+func (x *AppealDecided) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *AppealDecided) GetOpUser() string {
+	if x != nil {
+		return x.OpUser
+	}
+	return ""
+}
+
+func (x *AppealDecided) GetOpRemarks() string {
+	if x != nil {
+		return x.OpRemarks
+	}
+	return ""
+}
+
+var File_pkg_event_event_proto protoreflect.FileDescriptor
+
+const file_pkg_event_event_proto_rawDesc = "" +
+	"\n" +
+	"\x15pkg/event/event.proto\x12\x0freview.event.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe2\x03\n" +
+	"\bEnvelope\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12%\n" +
+	"\x0eaggregate_type\x18\x03 \x01(\tR\raggregateType\x12!\n" +
+	"\faggregate_id\x18\x04 \x01(\x03R\vaggregateId\x12,\n" +
+	"\x05actor\x18\x05 \x01(\v2\x16.review.event.v1.ActorR\x05actor\x12;\n" +
+	"\voccurred_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12G\n" +
+	"\x0ereview_created\x18\n" +
+	" \x01(\v2\x1e.review.event.v1.ReviewCreatedH\x00R\rreviewCreated\x12J\n" +
+	"\x0freview_approved\x18\v \x01(\v2\x1f.review.event.v1.ReviewApprovedH\x00R\x0ereviewApproved\x12G\n" +
+	"\x0eappeal_decided\x18\f \x01(\v2\x1e.review.event.v1.AppealDecidedH\x00R\rappealDecidedB\t\n" +
+	"\apayload\"+\n" +
+	"\x05Actor\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\xf6\x02\n" +
+	"\rReviewCreated\x12\x1b\n" +
+	"\treview_id\x18\x01 \x01(\x03R\breviewId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12\x19\n" +
+	"\bstore_id\x18\x03 \x01(\x03R\astoreId\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\x03R\x06userId\x12\x15\n" +
+	"\x06sku_id\x18\x05 \x01(\x03R\x05skuId\x12\x15\n" +
+	"\x06spu_id\x18\x06 \x01(\x03R\x05spuId\x12\x14\n" +
+	"\x05score\x18\a \x01(\x05R\x05score\x12#\n" +
+	"\rservice_score\x18\b \x01(\x05R\fserviceScore\x12#\n" +
+	"\rexpress_score\x18\t \x01(\x05R\fexpressScore\x12\x18\n" +
+	"\acontent\x18\n" +
+	" \x01(\tR\acontent\x12\x1b\n" +
+	"\thas_media\x18\v \x01(\bR\bhasMedia\x12\x1c\n" +
+	"\tanonymous\x18\f \x01(\bR\tanonymous\x12\x16\n" +
+	"\x06status\x18\r \x01(\x05R\x06status\"\x99\x01\n" +
+	"\x0eReviewApproved\x12\x1b\n" +
+	"\treview_id\x18\x01 \x01(\x03R\breviewId\x12\x19\n" +
+	"\bstore_id\x18\x02 \x01(\x03R\astoreId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12\x17\n" +
+	"\aop_user\x18\x04 \x01(\tR\x06opUser\x12\x1d\n" +
+	"\n" +
+	"op_remarks\x18\x05 \x01(\tR\topRemarks\"\xb4\x01\n" +
+	"\rAppealDecided\x12\x1b\n" +
+	"\tappeal_id\x18\x01 \x01(\x03R\bappealId\x12\x1b\n" +
+	"\treview_id\x18\x02 \x01(\x03R\breviewId\x12\x19\n" +
+	"\bstore_id\x18\x03 \x01(\x03R\astoreId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\x05R\x06status\x12\x17\n" +
+	"\aop_user\x18\x05 \x01(\tR\x06opUser\x12\x1d\n" +
+	"\n" +
+	"op_remarks\x18\x06 \x01(\tR\topRemarksB Z\x1ereview-service/pkg/event;eventb\x06proto3"
+
+var (
+	file_pkg_event_event_proto_rawDescOnce sync.Once
+	file_pkg_event_event_proto_rawDescData []byte
+)
+
+func file_pkg_event_event_proto_rawDescGZIP() []byte {
+	file_pkg_event_event_proto_rawDescOnce.Do(func() {
+		file_pkg_event_event_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_event_event_proto_rawDesc), len(file_pkg_event_event_proto_rawDesc)))
+	})
+	return file_pkg_event_event_proto_rawDescData
+}
+
+var file_pkg_event_event_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_pkg_event_event_proto_goTypes = []any{
+	(*Envelope)(nil),              // 0: review.event.v1.Envelope
+	(*Actor)(nil),                 // 1: review.event.v1.Actor
+	(*ReviewCreated)(nil),         // 2: review.event.v1.ReviewCreated
+	(*ReviewApproved)(nil),        // 3: review.event.v1.ReviewApproved
+	(*AppealDecided)(nil),         // 4: review.event.v1.AppealDecided
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+}
+var file_pkg_event_event_proto_depIdxs = []int32{
+	1, // 0: review.event.v1.Envelope.actor:type_name -> review.event.v1.Actor
+	5, // 1: review.event.v1.Envelope.occurred_at:type_name -> google.protobuf.Timestamp
+	2, // 2: review.event.v1.Envelope.review_created:type_name -> review.event.v1.ReviewCreated
+	3, // 3: review.event.v1.Envelope.review_approved:type_name -> review.event.v1.ReviewApproved
+	4, // 4: review.event.v1.Envelope.appeal_decided:type_name -> review.event.v1.AppealDecided
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_pkg_event_event_proto_init() }
+func file_pkg_event_event_proto_init() {
+	if File_pkg_event_event_proto != nil {
+		return
+	}
+	file_pkg_event_event_proto_msgTypes[0].OneofWrappers = []any{
+		(*Envelope_ReviewCreated)(nil),
+		(*Envelope_ReviewApproved)(nil),
+		(*Envelope_AppealDecided)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_event_event_proto_rawDesc), len(file_pkg_event_event_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_pkg_event_event_proto_goTypes,
+		DependencyIndexes: file_pkg_event_event_proto_depIdxs,
+		MessageInfos:      file_pkg_event_event_proto_msgTypes,
+	}.Build()
+	File_pkg_event_event_proto = out.File
+	file_pkg_event_event_proto_goTypes = nil
+	file_pkg_event_event_proto_depIdxs = nil
+}
@@ -0,0 +1,365 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/registry"
+	"github.com/blevesearch/bleve/v2/search/highlight"
+	htmlFormatter "github.com/blevesearch/bleve/v2/search/highlight/format/html"
+	simpleFragmenter "github.com/blevesearch/bleve/v2/search/highlight/fragmenter/simple"
+	simpleHighlighter "github.com/blevesearch/bleve/v2/search/highlight/highlighter/simple"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+const (
+	// sourceField 原始文档以 JSON 形式保存在该字段中，只存储不索引
+	sourceField = "__source"
+	// versionsField 各 VersionField 的版本号，以 JSON 形式只存储不索引
+	versionsField = "__versions"
+	// emHighlighter 与 ES 默认一致，用 <em></em> 包裹关键词
+	emHighlighter = "em"
+	defaultSize   = 10
+	wildcardAny   = "*"
+)
+
+func init() {
+	err := registry.RegisterHighlighter(emHighlighter, func(config map[string]interface{}, cache *registry.Cache) (highlight.Highlighter, error) {
+		fragmenter, err := cache.FragmenterNamed(simpleFragmenter.Name)
+		if err != nil {
+			return nil, err
+		}
+		formatter := htmlFormatter.NewFragmentFormatter("<em>", "</em>")
+		return simpleHighlighter.NewHighlighter(fragmenter, formatter, simpleHighlighter.DefaultSeparator), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Bleve 内嵌的本地索引，不依赖外部服务
+// 只索引 Mapping 中声明的字段：Text 使用 cjk 分词，Keyword 和 Date 不分词，Numeric 转换为数值；
+// 不支持自定义分词器和时间衰减，这两项查询条件会被忽略
+type Bleve struct {
+	index   bleve.Index
+	mapping Mapping
+	// Update 需要先读后写，串行执行避免并发更新丢失字段
+	mu sync.Mutex
+}
+
+// NewBleve path 为空时使用内存索引，否则在 path 目录下打开或创建索引
+func NewBleve(path string, m Mapping) (*Bleve, error) {
+	var (
+		index bleve.Index
+		err   error
+	)
+	switch {
+	case path == "":
+		index, err = bleve.NewMemOnly(bleveMapping(m))
+	case exists(path):
+		index, err = bleve.Open(path)
+	default:
+		index, err = bleve.New(path, bleveMapping(m))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open bleve index %q: %w", path, err)
+	}
+	return &Bleve{index: index, mapping: m}, nil
+}
+
+var _ Index = (*Bleve)(nil)
+
+func bleveMapping(m Mapping) *mapping.IndexMappingImpl {
+	doc := mapping.NewDocumentStaticMapping()
+	for field, typ := range m {
+		var fm *mapping.FieldMapping
+		switch typ {
+		case Text:
+			fm = mapping.NewTextFieldMapping()
+			fm.Analyzer = cjk.AnalyzerName
+		case Numeric:
+			fm = mapping.NewNumericFieldMapping()
+			fm.Store = false
+		default:
+			fm = mapping.NewKeywordFieldMapping()
+			fm.Store = false
+			fm.IncludeTermVectors = false
+		}
+		doc.AddFieldMappingsAt(field, fm)
+	}
+	for _, field := range []string{sourceField, versionsField} {
+		stored := mapping.NewTextFieldMapping()
+		stored.Index = false
+		stored.IncludeTermVectors = false
+		stored.IncludeInAll = false
+		stored.DocValues = false
+		doc.AddFieldMappingsAt(field, stored)
+	}
+
+	im := bleve.NewIndexMapping()
+	im.DefaultMapping = doc
+	im.DefaultAnalyzer = keyword.Name
+	return im
+}
+
+func (b *Bleve) Index(ctx context.Context, id string, doc Document) error {
+	return b.put(id, doc, nil)
+}
+
+// put 写入整篇文档，versions 与文档一起保存
+func (b *Bleve) put(id string, doc Document, versions map[string]int64) error {
+	source, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	data := make(map[string]interface{}, len(doc)+2)
+	for field, v := range doc {
+		if v == nil {
+			continue
+		}
+		if b.mapping[field] == Numeric {
+			f, err := strconv.ParseFloat(fmt.Sprint(v), 64)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field, err)
+			}
+			data[field] = f
+			continue
+		}
+		data[field] = fmt.Sprint(v)
+	}
+	data[sourceField] = string(source)
+	if len(versions) > 0 {
+		v, err := json.Marshal(versions)
+		if err != nil {
+			return err
+		}
+		data[versionsField] = string(v)
+	}
+	return b.index.Index(id, data)
+}
+
+// get 读出原始文档和版本号，文档不存在时返回 ErrNotFound
+func (b *Bleve) get(ctx context.Context, id string) (Document, map[string]int64, error) {
+	req := bleve.NewSearchRequest(query.NewDocIDQuery([]string{id}))
+	req.Fields = []string{sourceField, versionsField}
+	res, err := b.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(res.Hits) == 0 {
+		return nil, nil, ErrNotFound
+	}
+	doc := Document{}
+	if s, ok := res.Hits[0].Fields[sourceField].(string); ok {
+		// 整数按 json.Number 读出，重写时雪花 ID 不会丢失精度
+		if doc, err = decodeDocument([]byte(s)); err != nil {
+			return nil, nil, err
+		}
+	}
+	versions := map[string]int64{}
+	if s, ok := res.Hits[0].Fields[versionsField].(string); ok {
+		if err := json.Unmarshal([]byte(s), &versions); err != nil {
+			return nil, nil, err
+		}
+	}
+	return doc, versions, nil
+}
+
+// Update 读出原文档合并后整篇重写，保留原来的版本号
+func (b *Bleve) Update(ctx context.Context, id string, doc Document) error {
+	return b.write(ctx, Op{Action: ActionUpdate, ID: id, Doc: doc})
+}
+
+func (b *Bleve) Delete(ctx context.Context, id string) error {
+	return b.index.Delete(id)
+}
+
+// Bulk 本地索引没有网络开销，按顺序逐条执行
+func (b *Bleve) Bulk(ctx context.Context, ops []Op) ([]error, error) {
+	errs := make([]error, len(ops))
+	for i, op := range ops {
+		switch {
+		case op.Action == ActionIndex && op.Version == 0:
+			errs[i] = b.Index(ctx, op.ID, op.Doc)
+		case op.Action == ActionDelete && op.Version == 0:
+			errs[i] = b.Delete(ctx, op.ID)
+		case op.Action <= ActionUpsert:
+			errs[i] = b.write(ctx, op)
+		default:
+			return nil, fmt.Errorf("unknown action %d", op.Action)
+		}
+		if errs[i] != nil && !errors.Is(errs[i], ErrNotFound) && !errors.Is(errs[i], ErrConflict) {
+			errs[i] = &ItemError{Status: http.StatusBadRequest, Type: "bleve_exception", Reason: errs[i].Error()}
+		}
+	}
+	return errs, nil
+}
+
+// write 需要先读出原文档的操作：局部更新、upsert 和带版本号的操作，与 ES 的 update 脚本行为一致
+func (b *Bleve) write(ctx context.Context, op Op) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	doc, versions, err := b.get(ctx, op.ID)
+	missing := errors.Is(err, ErrNotFound)
+	if err != nil && !missing {
+		return err
+	}
+	if missing {
+		switch op.Action {
+		case ActionUpdate:
+			return ErrNotFound
+		case ActionDelete:
+			return nil
+		}
+		doc, versions = Document{}, map[string]int64{}
+	}
+
+	field := op.VersionField
+	if field == "" {
+		field = DefaultVersionField
+	}
+	if op.Version > 0 && versions[field] > op.Version {
+		return ErrConflict
+	}
+	switch op.Action {
+	case ActionDelete:
+		return b.index.Delete(op.ID)
+	case ActionIndex:
+		doc, versions = Document{}, map[string]int64{}
+	}
+	for k, v := range op.Doc {
+		doc[k] = v
+	}
+	if op.Version > 0 {
+		versions[field] = op.Version
+	}
+	return b.put(op.ID, doc, versions)
+}
+
+func (b *Bleve) Query(ctx context.Context, q *Query) (*Result, error) {
+	size := q.Size
+	if size <= 0 {
+		size = defaultSize
+	}
+	req := bleve.NewSearchRequestOptions(b.query(q), size, q.From, false)
+	req.Fields = []string{sourceField}
+	if len(q.Sort) > 0 {
+		order := make([]string, 0, len(q.Sort))
+		for _, s := range q.Sort {
+			if s.Desc {
+				order = append(order, "-"+s.Field)
+				continue
+			}
+			order = append(order, s.Field)
+		}
+		req.SortBy(order)
+	}
+	if len(q.After) > 0 {
+		req.SetSearchAfter(q.After)
+	}
+	if q.Highlight != "" {
+		req.Highlight = bleve.NewHighlightWithStyle(emHighlighter)
+		req.Highlight.AddField(q.Highlight)
+	}
+
+	res, err := b.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Total: int64(res.Total), Hits: make([]*Hit, 0, len(res.Hits))}
+	for _, hit := range res.Hits {
+		h := &Hit{ID: hit.ID, Score: hit.Score}
+		if s, ok := hit.Fields[sourceField].(string); ok {
+			h.Source = json.RawMessage(s)
+		}
+		if q.Highlight != "" {
+			h.Highlight = hit.Fragments[q.Highlight]
+		}
+		result.Hits = append(result.Hits, h)
+	}
+	return result, nil
+}
+
+func (b *Bleve) Aggregate(ctx context.Context, q *Query, field string, size int) ([]Bucket, error) {
+	req := bleve.NewSearchRequestOptions(b.query(q), 0, 0, false)
+	req.AddFacet(field, bleve.NewFacetRequest(field, size))
+	res, err := b.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]Bucket, 0, size)
+	if facet, ok := res.Facets[field]; ok {
+		for _, t := range facet.Terms.Terms() {
+			buckets = append(buckets, Bucket{Key: t.Term, Count: int64(t.Count)})
+		}
+	}
+	return buckets, nil
+}
+
+func (b *Bleve) Close() error {
+	return b.index.Close()
+}
+
+// query 将 Query 转换为 bleve 的 bool 查询，过滤条件不参与打分
+func (b *Bleve) query(q *Query) query.Query {
+	var must query.Query = query.NewMatchAllQuery()
+	if q.Match != nil {
+		match := query.NewMatchQuery(q.Match.Text)
+		match.SetField(q.Match.Field)
+		must = match
+	}
+	var excludes []query.Query
+	for _, f := range q.Excludes {
+		excludes = append(excludes, bleveFilter(f))
+	}
+	boolQuery := query.NewBooleanQuery([]query.Query{must}, nil, excludes)
+	if len(q.Filters) > 0 {
+		filters := make([]query.Query, 0, len(q.Filters))
+		for _, f := range q.Filters {
+			filters = append(filters, bleveFilter(f))
+		}
+		boolQuery.AddFilter(query.NewConjunctionQuery(filters))
+	}
+	return boolQuery
+}
+
+func bleveFilter(f Filter) query.Query {
+	switch {
+	case f.Exists:
+		// 值为 null 的字段不会写入索引，能匹配到任意词即说明字段存在
+		q := query.NewWildcardQuery(wildcardAny)
+		q.SetField(f.Field)
+		return q
+	case f.Gte != nil || f.Lte != nil:
+		inclusive := true
+		q := query.NewNumericRangeInclusiveQuery(f.Gte, f.Lte, &inclusive, &inclusive)
+		q.SetField(f.Field)
+		return q
+	default:
+		terms := make([]query.Query, 0, len(f.Values))
+		for _, v := range f.Values {
+			q := query.NewTermQuery(v)
+			q.SetField(f.Field)
+			terms = append(terms, q)
+		}
+		if len(terms) == 1 {
+			return terms[0]
+		}
+		return query.NewDisjunctionQuery(terms)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
@@ -0,0 +1,376 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/bulk"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/dynamicmapping"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/functionboostmode"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/sortorder"
+)
+
+const (
+	// decayRate 距离当前时间 Decay.Scale 的文档得分衰减为原来的一半
+	decayRate = 0.5
+	// dateFormat canal 消息中的时间为 MySQL datetime 格式
+	dateFormat = "yyyy-MM-dd HH:mm:ss||strict_date_optional_time||epoch_millis"
+)
+
+// Elasticsearch 基于 ES TypedClient 的实现
+type Elasticsearch struct {
+	client *elasticsearch.TypedClient
+	index  string
+}
+
+func NewElasticsearch(client *elasticsearch.TypedClient, index string) *Elasticsearch {
+	return &Elasticsearch{client: client, index: index}
+}
+
+var _ Index = (*Elasticsearch)(nil)
+
+func (e *Elasticsearch) Index(ctx context.Context, id string, doc Document) error {
+	_, err := e.client.Index(e.index).Id(id).Document(doc).Do(ctx)
+	return err
+}
+
+func (e *Elasticsearch) Update(ctx context.Context, id string, doc Document) error {
+	_, err := e.client.Update(e.index, id).Doc(doc).Do(ctx)
+	var esErr *types.ElasticsearchError
+	if errors.As(err, &esErr) && esErr.Status == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Delete 文档不存在时 ES 返回 404，客户端按正常响应处理
+func (e *Elasticsearch) Delete(ctx context.Context, id string) error {
+	_, err := e.client.Delete(e.index, id).Do(ctx)
+	return err
+}
+
+// Bulk 带版本号的操作通过 update 脚本执行：版本号保存在 _source 的 VersionField 中，
+// 脚本比较后决定写入、删除或跳过（noop），跳过时返回 ErrConflict
+func (e *Elasticsearch) Bulk(ctx context.Context, ops []Op) ([]error, error) {
+	req := e.client.Bulk().Index(e.index)
+	for i := range ops {
+		if err := addBulkOp(req, &ops[i]); err != nil {
+			return nil, fmt.Errorf("bulk op %s: %w", ops[i].ID, err)
+		}
+	}
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Items) != len(ops) {
+		return nil, fmt.Errorf("bulk returned %d items for %d ops", len(resp.Items), len(ops))
+	}
+
+	errs := make([]error, len(ops))
+	for i, item := range resp.Items {
+		for _, r := range item {
+			errs[i] = itemError(&ops[i], r)
+		}
+	}
+	return errs, nil
+}
+
+// versionScripts 各操作带版本号时使用的 painless 脚本，文档中的版本号更大时跳过
+var versionScripts = map[Action]string{
+	ActionIndex: `def v = ctx._source[params.field]; if (v != null && v > params.version) { ctx.op = 'noop' } ` +
+		`else { ctx._source.clear(); ctx._source.putAll(params.doc); ctx._source[params.field] = params.version }`,
+	ActionUpdate: `def v = ctx._source[params.field]; if (v != null && v > params.version) { ctx.op = 'noop' } ` +
+		`else { ctx._source.putAll(params.doc); ctx._source[params.field] = params.version }`,
+	ActionDelete: `def v = ctx._source[params.field]; if (v != null && v > params.version) { ctx.op = 'noop' } ` +
+		`else { ctx.op = 'delete' }`,
+}
+
+func addBulkOp(req *bulk.Bulk, op *Op) error {
+	id := op.ID
+	if op.Version > 0 {
+		return addVersionedOp(req, op)
+	}
+	switch op.Action {
+	case ActionIndex:
+		return req.IndexOp(types.IndexOperation{Id_: &id}, op.Doc)
+	case ActionUpdate:
+		return req.UpdateOp(types.UpdateOperation{Id_: &id}, op.Doc, nil)
+	case ActionUpsert:
+		upsert := true
+		return req.UpdateOp(types.UpdateOperation{Id_: &id}, op.Doc, &types.UpdateAction{DocAsUpsert: &upsert})
+	case ActionDelete:
+		return req.DeleteOp(types.DeleteOperation{Id_: &id})
+	}
+	return fmt.Errorf("unknown action %d", op.Action)
+}
+
+func addVersionedOp(req *bulk.Bulk, op *Op) error {
+	field := op.VersionField
+	if field == "" {
+		field = DefaultVersionField
+	}
+	script, ok := versionScripts[op.Action]
+	if op.Action == ActionUpsert {
+		script, ok = versionScripts[ActionUpdate], true
+	}
+	if !ok {
+		return fmt.Errorf("unknown action %d", op.Action)
+	}
+	doc, err := json.Marshal(op.Doc)
+	if err != nil {
+		return err
+	}
+	fieldJSON, _ := json.Marshal(field)
+	action := &types.UpdateAction{Script: &types.Script{
+		Source: &script,
+		Params: map[string]json.RawMessage{
+			"doc":     doc,
+			"field":   fieldJSON,
+			"version": json.RawMessage(strconv.FormatInt(op.Version, 10)),
+		},
+	}}
+	// 文档不存在时整篇写入和 upsert 直接创建文档，带上版本号
+	if op.Action == ActionIndex || op.Action == ActionUpsert {
+		upsert := make(Document, len(op.Doc)+1)
+		for k, v := range op.Doc {
+			upsert[k] = v
+		}
+		upsert[field] = op.Version
+		if action.Upsert, err = json.Marshal(upsert); err != nil {
+			return err
+		}
+	}
+	id := op.ID
+	retry := 3 // 同一文档被并发更新时由 ES 重新执行脚本
+	return req.UpdateOp(types.UpdateOperation{Id_: &id, RetryOnConflict: &retry}, nil, action)
+}
+
+// itemError 转换 _bulk 响应中单个操作的结果，与单条写入的行为保持一致：删除不存在的文档不算失败
+func itemError(op *Op, r types.ResponseItem) error {
+	if r.Status == http.StatusConflict {
+		return ErrConflict
+	}
+	if r.Status == http.StatusNotFound {
+		switch op.Action {
+		case ActionDelete:
+			return nil
+		case ActionUpdate:
+			return ErrNotFound
+		}
+	}
+	if r.Error == nil && r.Status < http.StatusBadRequest {
+		if op.Version > 0 && r.Result != nil && *r.Result == "noop" {
+			return ErrConflict
+		}
+		return nil
+	}
+	e := &ItemError{Status: r.Status}
+	if r.Error != nil {
+		e.Type = r.Error.Type
+		if r.Error.Reason != nil {
+			e.Reason = *r.Error.Reason
+		}
+	}
+	return e
+}
+
+func (e *Elasticsearch) Query(ctx context.Context, q *Query) (*Result, error) {
+	search := e.client.Search().
+		Index(e.index).
+		From(q.From).
+		Query(esQuery(q))
+	if q.Size > 0 {
+		search = search.Size(q.Size)
+	}
+	if len(q.Sort) > 0 {
+		sorts := make([]types.SortCombinations, 0, len(q.Sort))
+		for _, s := range q.Sort {
+			order := &sortorder.Asc
+			if s.Desc {
+				order = &sortorder.Desc
+			}
+			if s.Field == ScoreField {
+				sorts = append(sorts, types.SortOptions{Score_: &types.ScoreSort{Order: order}})
+				continue
+			}
+			sorts = append(sorts, types.SortOptions{SortOptions: map[string]types.FieldSort{s.Field: {Order: order}}})
+		}
+		search = search.Sort(sorts...)
+	}
+	if len(q.After) > 0 {
+		after := make([]types.FieldValue, 0, len(q.After))
+		for _, v := range q.After {
+			// 数值按 json.Number 传递，避免雪花 ID 转成 float64 丢失精度
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				after = append(after, json.Number(v))
+				continue
+			}
+			after = append(after, v)
+		}
+		search = search.SearchAfter(after...)
+	}
+	if q.Highlight != "" {
+		search = search.Highlight(&types.Highlight{
+			Fields: map[string]types.HighlightField{q.Highlight: {}},
+		})
+	}
+
+	resp, err := search.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Hits: make([]*Hit, 0, len(resp.Hits.Hits))}
+	if resp.Hits.Total != nil {
+		result.Total = resp.Hits.Total.Value
+	}
+	for _, hit := range resp.Hits.Hits {
+		h := &Hit{Source: hit.Source_}
+		if hit.Id_ != nil {
+			h.ID = *hit.Id_
+		}
+		if hit.Score_ != nil {
+			h.Score = float64(*hit.Score_)
+		}
+		if q.Highlight != "" {
+			h.Highlight = hit.Highlight[q.Highlight]
+		}
+		result.Hits = append(result.Hits, h)
+	}
+	return result, nil
+}
+
+func (e *Elasticsearch) Aggregate(ctx context.Context, q *Query, field string, size int) ([]Bucket, error) {
+	resp, err := e.client.Search().
+		Index(e.index).
+		Size(0).
+		Query(esQuery(q)).
+		Aggregations(map[string]types.Aggregations{
+			field: {Terms: &types.TermsAggregation{Field: &field, Size: &size}},
+		}).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]Bucket, 0, size)
+	switch agg := resp.Aggregations[field].(type) {
+	case *types.StringTermsAggregate:
+		if list, ok := agg.Buckets.([]types.StringTermsBucket); ok {
+			for _, b := range list {
+				buckets = append(buckets, Bucket{Key: fmt.Sprint(b.Key), Count: b.DocCount})
+			}
+		}
+	case *types.LongTermsAggregate:
+		if list, ok := agg.Buckets.([]types.LongTermsBucket); ok {
+			for _, b := range list {
+				buckets = append(buckets, Bucket{Key: strconv.FormatInt(b.Key, 10), Count: b.DocCount})
+			}
+		}
+	}
+	return buckets, nil
+}
+
+// Close TypedClient 不持有需要释放的资源
+func (e *Elasticsearch) Close() error {
+	return nil
+}
+
+// ESMapping 将 Mapping 转换为 ES 的显式 mapping，未声明的字段只保存在 _source 中不建索引
+// analyzer 为 Text 字段写入时使用的分词器，为空时使用 ES 默认的 standard
+func ESMapping(m Mapping, analyzer string) *types.TypeMapping {
+	properties := make(map[string]types.Property, len(m))
+	for field, typ := range m {
+		switch typ {
+		case Text:
+			p := types.NewTextProperty()
+			if analyzer != "" {
+				p.Analyzer = &analyzer
+			}
+			properties[field] = p
+		case Numeric:
+			properties[field] = types.NewDoubleNumberProperty()
+		case Long:
+			properties[field] = types.NewLongNumberProperty()
+		case Date:
+			p := types.NewDateProperty()
+			format := dateFormat
+			p.Format = &format
+			properties[field] = p
+		default:
+			properties[field] = types.NewKeywordProperty()
+		}
+	}
+	return &types.TypeMapping{
+		Dynamic:    &dynamicmapping.False,
+		Properties: properties,
+	}
+}
+
+// esQuery 将 Query 转换为 ES 的 bool 查询，设置了 Decay 时外层包一层 function_score
+func esQuery(q *Query) *types.Query {
+	boolQuery := &types.BoolQuery{}
+	if q.Match != nil {
+		match := types.MatchQuery{Query: q.Match.Text}
+		if q.Match.Analyzer != "" {
+			match.Analyzer = &q.Match.Analyzer
+		}
+		boolQuery.Must = []types.Query{{Match: map[string]types.MatchQuery{q.Match.Field: match}}}
+	}
+	for _, f := range q.Filters {
+		boolQuery.Filter = append(boolQuery.Filter, esFilter(f))
+	}
+	for _, f := range q.Excludes {
+		boolQuery.MustNot = append(boolQuery.MustNot, esFilter(f))
+	}
+	query := &types.Query{Bool: boolQuery}
+	if q.Decay == nil {
+		return query
+	}
+	origin := "now"
+	if !q.Decay.Origin.IsZero() {
+		origin = strconv.FormatInt(q.Decay.Origin.UnixMilli(), 10)
+	}
+	return &types.Query{
+		FunctionScore: &types.FunctionScoreQuery{
+			Query: query,
+			Functions: []types.FunctionScore{{
+				Gauss: map[string]interface{}{
+					q.Decay.Field: map[string]interface{}{"origin": origin, "scale": q.Decay.Scale, "decay": decayRate},
+				},
+			}},
+			BoostMode: &functionboostmode.Multiply,
+		},
+	}
+}
+
+func esFilter(f Filter) types.Query {
+	switch {
+	case f.Exists:
+		return types.Query{Exists: &types.ExistsQuery{Field: f.Field}}
+	case f.Gte != nil || f.Lte != nil:
+		r := types.NumberRangeQuery{}
+		if f.Gte != nil {
+			gte := types.Float64(*f.Gte)
+			r.Gte = &gte
+		}
+		if f.Lte != nil {
+			lte := types.Float64(*f.Lte)
+			r.Lte = &lte
+		}
+		return types.Query{Range: map[string]types.RangeQuery{f.Field: r}}
+	case len(f.Values) == 1:
+		return types.Query{Term: map[string]types.TermQuery{f.Field: {Value: f.Values[0]}}}
+	default:
+		values := make([]types.FieldValue, 0, len(f.Values))
+		for _, v := range f.Values {
+			values = append(values, v)
+		}
+		return types.Query{Terms: &types.TermsQuery{TermsQuery: map[string]types.TermsQueryField{f.Field: values}}}
+	}
+}
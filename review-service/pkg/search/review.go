@@ -0,0 +1,130 @@
+package search
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ReviewMapping 评价文档的字段类型，review-service 查询和 review-job 写入共用
+// 文档由 canal 消息转换为 ReviewDoc 后写入，字段名与 review_info 表的列名一致；
+// reply_ 开头的字段由 review-job 从 review_reply_info 表合并进来
+// 是否类的字段在文档中为 true/false，按 Keyword 索引后过滤值为 "true"、"false"
+var ReviewMapping = Mapping{
+	"id":            Long,
+	"review_id":     Long,
+	"store_id":      Long,
+	"spu_id":        Long,
+	"sku_id":        Long,
+	"order_id":      Long,
+	"user_id":       Long,
+	"status":        Keyword,
+	"has_media":     Keyword,
+	"has_reply":     Keyword,
+	"anonymous":     Keyword,
+	"is_default":    Keyword,
+	"content":       Text,
+	"score":         Numeric,
+	"service_score": Numeric,
+	"express_score": Numeric,
+	"create_at":     Date,
+	"update_at":     Date,
+	"delete_at":     Date,
+	"reply_id":      Long,
+	"reply_content": Text,
+	"reply_at":      Date,
+}
+
+// AppealMapping 申诉文档的字段类型，供运营端检索申诉
+// 文档由 canal 消息转换为 AppealDoc 后写入，字段名与 review_appeal_info 表的列名一致
+var AppealMapping = Mapping{
+	"id":        Long,
+	"appeal_id": Long,
+	"review_id": Long,
+	"store_id":  Long,
+	"status":    Keyword,
+	"reason":    Keyword,
+	"op_user":   Keyword,
+	"content":   Text,
+	"create_at": Date,
+	"update_at": Date,
+	"delete_at": Date,
+}
+
+// ReviewDoc 评价索引中的文档：整数和是否类的字段为 JSON 数值和布尔值，时间为带时区的 RFC3339，
+// tags 和媒体信息为解析后的 JSON；review-job 按此结构写入，review-service 按此结构读取
+type ReviewDoc struct {
+	ID             int64           `json:"id"`
+	CreateBy       string          `json:"create_by"`
+	UpdateBy       string          `json:"update_by"`
+	CreateAt       time.Time       `json:"create_at"`
+	UpdateAt       time.Time       `json:"update_at"`
+	DeleteAt       *time.Time      `json:"delete_at"`
+	Version        int32           `json:"version"`
+	ReviewID       int64           `json:"review_id"`
+	Content        string          `json:"content"`
+	Score          int32           `json:"score"`
+	ServiceScore   int32           `json:"service_score"`
+	ExpressScore   int32           `json:"express_score"`
+	HasMedia       bool            `json:"has_media"`
+	OrderID        int64           `json:"order_id"`
+	SkuID          int64           `json:"sku_id"`
+	SpuID          int64           `json:"spu_id"`
+	StoreID        int64           `json:"store_id"`
+	UserID         int64           `json:"user_id"`
+	Anonymous      bool            `json:"anonymous"`
+	Tags           json.RawMessage `json:"tags"`
+	PicInfo        json.RawMessage `json:"pic_info"`
+	VideoInfo      json.RawMessage `json:"video_info"`
+	Status         int32           `json:"status"`
+	IsDefault      bool            `json:"is_default"`
+	OpReason       string          `json:"op_reason"`
+	OpRemarks      string          `json:"op_remarks"`
+	OpUser         string          `json:"op_user"`
+	GoodsSnapshoot string          `json:"goods_snapshoot"`
+	ExtJSON        string          `json:"ext_json"`
+	CtrlJSON       string          `json:"ctrl_json"`
+
+	// 以下字段由 review-job 从回复表维护，Document 不包含这些字段
+	HasReply     bool       `json:"has_reply"`
+	ReplyID      int64      `json:"reply_id,omitempty"`
+	ReplyContent string     `json:"reply_content,omitempty"`
+	ReplyAt      *time.Time `json:"reply_at,omitempty"`
+}
+
+// replyFields 评价文档中由回复表维护的字段
+var replyFields = []string{"has_reply", "reply_id", "reply_content", "reply_at"}
+
+// Document 转换为写入用的文档，不包含回复字段，以 upsert 合并写入时不会覆盖已合并的回复
+func (d *ReviewDoc) Document() (Document, error) {
+	doc, err := NewDocument(d)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range replyFields {
+		delete(doc, field)
+	}
+	return doc, nil
+}
+
+// AppealDoc 申诉索引中的文档，字段类型的约定与 ReviewDoc 一致
+type AppealDoc struct {
+	ID        int64           `json:"id"`
+	CreateBy  string          `json:"create_by"`
+	UpdateBy  string          `json:"update_by"`
+	CreateAt  time.Time       `json:"create_at"`
+	UpdateAt  time.Time       `json:"update_at"`
+	DeleteAt  *time.Time      `json:"delete_at"`
+	Version   int32           `json:"version"`
+	AppealID  int64           `json:"appeal_id"`
+	ReviewID  int64           `json:"review_id"`
+	StoreID   int64           `json:"store_id"`
+	Status    int32           `json:"status"`
+	Reason    string          `json:"reason"`
+	Content   string          `json:"content"`
+	PicInfo   json.RawMessage `json:"pic_info"`
+	VideoInfo json.RawMessage `json:"video_info"`
+	OpRemarks string          `json:"op_remarks"`
+	OpUser    string          `json:"op_user"`
+	ExtJSON   string          `json:"ext_json"`
+	CtrlJSON  string          `json:"ctrl_json"`
+}
@@ -0,0 +1,212 @@
+// Package search 评价搜索索引的抽象
+// 线上使用 Elasticsearch，本地开发和集成测试使用内嵌的 Bleve，二者通过配置切换；
+// review-service 负责查询，review-job 负责根据 binlog 写入，两边共用同一个接口
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrNotFound 更新的文档不存在
+	ErrNotFound = errors.New("search: document not found")
+	// ErrConflict 写入的版本号小于索引中已有的版本号，说明是过期的数据
+	ErrConflict = errors.New("search: version conflict")
+)
+
+// Document 写入索引的文档，字段名与数据库列名一致
+type Document map[string]interface{}
+
+// NewDocument 将 ReviewDoc 等结构体按 JSON 字段名转换为 Document，整数保持为 json.Number，不会丢失精度
+func NewDocument(v interface{}) (Document, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocument(data)
+}
+
+func decodeDocument(data []byte) (Document, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	doc := Document{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ParseJSON 将 tags、媒体信息等保存 JSON 的列解析为 JSON，空字符串为 null；
+// 不是合法 JSON 的旧数据（如直接保存的图片地址）按 JSON 字符串保存
+func ParseJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	quoted, _ := json.Marshal(s)
+	return quoted
+}
+
+// JSONText ParseJSON 的逆操作，还原为数据库中保存的文本
+func JSONText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// FieldType 字段类型，决定字段如何建索引
+type FieldType int
+
+const (
+	Keyword FieldType = iota // 不分词，用于过滤、排序和聚合
+	Text                     // 全文检索，支持高亮
+	Numeric                  // 数值，支持范围过滤
+	Date                     // 时间，支持按时间衰减
+	Long                     // 整数 ID，ES 中为 long；bleve 中按 Keyword 处理，避免雪花 ID 转成 float64 丢失精度
+)
+
+// Mapping 字段名到类型的映射；ES 的索引模板和 Bleve 的索引都只索引这里声明的字段
+type Mapping map[string]FieldType
+
+// Match 全文匹配条件
+type Match struct {
+	Field    string
+	Text     string
+	Analyzer string // 为空时使用字段本身的分词器
+}
+
+// Filter 过滤条件，Values、范围和 Exists 三者取其一
+type Filter struct {
+	Field  string
+	Values []string // 字段等于其中任意一个值
+	Gte    *float64
+	Lte    *float64
+	Exists bool // 字段存在且不为 null
+}
+
+// Sort 排序字段，Field 为 "_score" 时按相关度排序
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ScoreField 按相关度排序时使用的字段名
+const ScoreField = "_score"
+
+// Decay 按时间字段对相关度做高斯衰减，越接近 Origin 得分越高
+type Decay struct {
+	Field string
+	Scale string // 如 30d
+	// Origin 衰减的原点，为零时取当前时间；按得分翻页时各页需要使用同一个原点，否则得分在翻页之间变化
+	Origin time.Time
+}
+
+// Query 查询条件
+type Query struct {
+	Match     *Match
+	Filters   []Filter // 必须满足
+	Excludes  []Filter // 必须不满足
+	Decay     *Decay
+	Sort      []Sort
+	From      int
+	Size      int
+	After     []string // 上一页最后一条的排序值，与 Sort 一一对应
+	Highlight string   // 需要高亮的字段
+}
+
+// Hit 一条命中的文档
+type Hit struct {
+	ID        string          `json:"id"`
+	Score     float64         `json:"score"`
+	Source    json.RawMessage `json:"source"`
+	Highlight []string        `json:"highlight,omitempty"`
+}
+
+// Result 查询结果
+type Result struct {
+	Total int64  `json:"total"`
+	Hits  []*Hit `json:"hits"`
+}
+
+// Bucket 聚合结果中的一个分组
+type Bucket struct {
+	Key   string
+	Count int64
+}
+
+// Action 批量写入中的操作类型
+type Action int
+
+const (
+	ActionIndex  Action = iota // 整篇写入，同 Index
+	ActionUpdate               // 局部更新，同 Update
+	ActionDelete               // 删除，同 Delete
+	ActionUpsert               // 局部更新，文档不存在时以 Doc 创建
+)
+
+// DefaultVersionField Op.VersionField 为空时保存版本号的字段
+const DefaultVersionField = "__version"
+
+// Op 批量写入中的一个操作，ActionDelete 时 Doc 为空
+type Op struct {
+	Action Action
+	ID     string
+	Doc    Document
+	// Version 大于 0 时只有不小于文档中已有的版本号才写入，否则返回 ErrConflict，
+	// 重复投递的旧消息不会覆盖新数据
+	Version int64
+	// VersionField 保存版本号的字段，为空时为 DefaultVersionField
+	// 一篇文档的不同字段来自不同的表时各自使用自己的版本号，一张表的变更不会因另一张表的版本号更大而被丢弃
+	VersionField string
+}
+
+// ItemError 批量写入中单个操作失败的原因
+type ItemError struct {
+	Status int // 与单条写入时返回的 HTTP 状态码一致
+	Type   string
+	Reason string
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("search: %d %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// Retryable 限流、服务端错误和索引被阻塞写入可以重试，mapping 不匹配等请求本身的错误重试也不会成功
+func (e *ItemError) Retryable() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= http.StatusInternalServerError || e.Blocked()
+}
+
+// Blocked 索引被阻塞写入（index.blocks.write），如重建索引切换别名期间，解除阻塞或切换后重试可以成功
+func (e *ItemError) Blocked() bool {
+	return e.Type == "cluster_block_exception"
+}
+
+// Index 搜索索引
+type Index interface {
+	// Index 写入整篇文档，已存在时覆盖
+	Index(ctx context.Context, id string, doc Document) error
+	// Update 局部更新文档中的字段，文档不存在时返回 ErrNotFound
+	Update(ctx context.Context, id string, doc Document) error
+	// Delete 删除文档，文档不存在时不报错
+	Delete(ctx context.Context, id string) error
+	// Bulk 在一个请求中按顺序执行 ops，返回与 ops 一一对应的错误，成功的操作为 nil；
+	// 请求整体失败时返回 error。更新不存在的文档对应 ErrNotFound，版本冲突对应 ErrConflict，其余失败为 *ItemError
+	Bulk(ctx context.Context, ops []Op) ([]error, error)
+	Query(ctx context.Context, q *Query) (*Result, error)
+	// Aggregate 统计满足查询条件的文档按 field 分组的数量，最多返回 size 组
+	Aggregate(ctx context.Context, q *Query, field string, size int) ([]Bucket, error)
+	Close() error
+}
@@ -0,0 +1,35 @@
+package snowflake
+
+import "time"
+
+// ID 解析后的雪花ID各组成部分
+type ID struct {
+	Timestamp    time.Time
+	DataCenterID int64
+	WorkerID     int64
+	Sequence     int64
+}
+
+// Decode 解析雪花ID，得到生成时间、数据中心、机器和序列号
+func Decode(id int64) ID {
+	return ID{
+		Timestamp:    time.UnixMilli((id >> timestampLeftShift) + twepoch),
+		DataCenterID: (id >> dataCenterIDShift) & maxDataCenterID,
+		WorkerID:     (id >> workerIDShift) & maxWorkerID,
+		Sequence:     id & sequenceMask,
+	}
+}
+
+// MinIDForTime 返回该毫秒内可能生成的最小ID，可用于按时间范围查询
+func MinIDForTime(t time.Time) int64 {
+	ms := t.UnixMilli() - twepoch
+	if ms < 0 {
+		ms = 0
+	}
+	return ms << timestampLeftShift
+}
+
+// MaxIDForTime 返回该毫秒内可能生成的最大ID
+func MaxIDForTime(t time.Time) int64 {
+	return MinIDForTime(t) | (int64(1)<<timestampLeftShift - 1)
+}
@@ -0,0 +1,83 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecodeGeneratedID(t *testing.T) {
+	sf, err := NewSnowflake(7, 3)
+	if err != nil {
+		t.Fatalf("NewSnowflake: %v", err)
+	}
+	before := time.Now().Truncate(time.Millisecond)
+	ids := make([]int64, 100)
+	for i := range ids {
+		if ids[i], err = sf.NextID(); err != nil {
+			t.Fatalf("NextID: %v", err)
+		}
+	}
+	after := time.Now()
+
+	var prev ID
+	for i, id := range ids {
+		got := Decode(id)
+		if got.WorkerID != 7 || got.DataCenterID != 3 {
+			t.Fatalf("Decode(%d) worker = %d, data center = %d, want 7, 3", id, got.WorkerID, got.DataCenterID)
+		}
+		if got.Timestamp.Before(before) || got.Timestamp.After(after) {
+			t.Fatalf("Decode(%d) timestamp = %v, want between %v and %v", id, got.Timestamp, before, after)
+		}
+		// 同一毫秒内序列号递增，进入新的毫秒后从 0 开始
+		want := int64(0)
+		if i > 0 && got.Timestamp.Equal(prev.Timestamp) {
+			want = prev.Sequence + 1
+		}
+		if got.Sequence != want {
+			t.Fatalf("Decode(%d) sequence = %d, want %d", id, got.Sequence, want)
+		}
+		if id < MinIDForTime(got.Timestamp) || id > MaxIDForTime(got.Timestamp) {
+			t.Fatalf("id %d is outside [%d, %d] of its millisecond", id, MinIDForTime(got.Timestamp), MaxIDForTime(got.Timestamp))
+		}
+		prev = got
+	}
+}
+
+func TestDecodeFields(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 123*int(time.Millisecond), time.UTC)
+	id := (at.UnixMilli()-twepoch)<<timestampLeftShift | 3<<dataCenterIDShift | 7<<workerIDShift | 42
+	got := Decode(id)
+	if !got.Timestamp.Equal(at) || got.DataCenterID != 3 || got.WorkerID != 7 || got.Sequence != 42 {
+		t.Fatalf("Decode(%d) = %+v, want %v, data center 3, worker 7, sequence 42", id, got, at)
+	}
+}
+
+func TestIDForTimeBoundaries(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 123*int(time.Millisecond), time.UTC)
+	first, last := MinIDForTime(at), MaxIDForTime(at)
+
+	if got := Decode(first); !got.Timestamp.Equal(at) || got.DataCenterID != 0 || got.WorkerID != 0 || got.Sequence != 0 {
+		t.Errorf("Decode(MinIDForTime) = %+v, want %v with all other parts 0", got, at)
+	}
+	if got := Decode(last); !got.Timestamp.Equal(at) || got.DataCenterID != maxDataCenterID || got.WorkerID != maxWorkerID || got.Sequence != sequenceMask {
+		t.Errorf("Decode(MaxIDForTime) = %+v, want %v with all other parts at their maximum", got, at)
+	}
+	// 相邻的毫秒首尾相接
+	if got := Decode(first - 1).Timestamp; !got.Equal(at.Add(-time.Millisecond)) {
+		t.Errorf("Decode(first-1) timestamp = %v, want the previous millisecond", got)
+	}
+	if got := Decode(last + 1).Timestamp; !got.Equal(at.Add(time.Millisecond)) {
+		t.Errorf("Decode(last+1) timestamp = %v, want the next millisecond", got)
+	}
+	if MaxIDForTime(at.Add(-time.Millisecond))+1 != first || last+1 != MinIDForTime(at.Add(time.Millisecond)) {
+		t.Error("ranges of adjacent milliseconds are not contiguous")
+	}
+	// 同一毫秒内的时间得到相同的范围
+	if inside := at.Add(999 * time.Microsecond); MinIDForTime(inside) != first || MaxIDForTime(inside) != last {
+		t.Errorf("range of %v = [%d, %d], want [%d, %d]", inside, MinIDForTime(inside), MaxIDForTime(inside), first, last)
+	}
+	// 起始时间之前的时间从 0 开始
+	if got := MinIDForTime(time.UnixMilli(twepoch - 1)); got != 0 {
+		t.Errorf("MinIDForTime before epoch = %d, want 0", got)
+	}
+}
@@ -0,0 +1,202 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultLeaseTTL    = 30 * time.Second
+	defaultLeasePrefix = "snowflake:worker:"
+
+	// workerID 和 dataCenterID 组合后的槽位总数
+	maxSlot = (maxDataCenterID + 1) * (maxWorkerID + 1)
+)
+
+// ErrNoWorkerID 所有 workerID 都已被占用
+var ErrNoWorkerID = errors.New("snowflake: no free worker id")
+
+// 续约：只有持有者才能延长过期时间
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// 释放：只有持有者才能删除
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Lease 从 Redis 租用的 workerID / dataCenterID
+// 后台定时续约，服务退出时释放，保证多副本之间生成的 ID 不冲突
+//
+// 租约只在 valid 之前有效：每次续约成功后延长到发出续约请求的时间 + ttl - ttl/4，
+// 保证 Redis 中的 key 过期、其他节点可能占用同一个槽位之前本节点已经停止生成 ID。
+// 租约丢失（槽位被占用或过期）后心跳继续尝试重新租用，优先原来的槽位，成功后 Snowflake 使用新的 workerID
+type Lease struct {
+	rdb    *redis.Client
+	prefix string
+	owner  string
+	ttl    time.Duration
+	margin time.Duration
+
+	mu    sync.RWMutex
+	key   string
+	slot  int64
+	valid time.Time // 租约的有效期，零值表示已丢失
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// AcquireLease 依次尝试占用一个空闲槽位，成功后启动心跳续约
+func AcquireLease(ctx context.Context, rdb *redis.Client, prefix string, ttl time.Duration) (*Lease, error) {
+	if prefix == "" {
+		prefix = defaultLeasePrefix
+	}
+	if ttl <= 0 {
+		ttl = defaultLeaseTTL
+	}
+	hostname, _ := os.Hostname()
+	l := &Lease{
+		rdb:    rdb,
+		prefix: prefix,
+		owner:  hostname + "-" + strconv.Itoa(os.Getpid()) + "-" + strconv.FormatInt(time.Now().UnixNano(), 10),
+		ttl:    ttl,
+		margin: ttl / 4,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	// 从随机位置开始，减少多副本同时启动时的争抢
+	if err := l.acquire(ctx, rand.Int63n(maxSlot)); err != nil {
+		return nil, err
+	}
+	go l.heartbeat()
+	return l, nil
+}
+
+// acquire 从 start 开始依次尝试占用一个空闲槽位
+func (l *Lease) acquire(ctx context.Context, start int64) error {
+	for i := int64(0); i < maxSlot; i++ {
+		slot := (start + i) % maxSlot
+		key := l.prefix + strconv.FormatInt(slot, 10)
+		sent := time.Now()
+		ok, err := l.rdb.SetNX(ctx, key, l.owner, l.ttl).Result()
+		if err != nil {
+			return fmt.Errorf("snowflake: acquire lease failed: %w", err)
+		}
+		if !ok {
+			continue
+		}
+		l.mu.Lock()
+		l.key, l.slot, l.valid = key, slot, sent.Add(l.ttl-l.margin)
+		l.mu.Unlock()
+		return nil
+	}
+	return ErrNoWorkerID
+}
+
+// WorkerID 租用到的 workerID，重新租用后可能变化
+func (l *Lease) WorkerID() int64 {
+	slot, _ := l.current()
+	return slot % (maxWorkerID + 1)
+}
+
+// DataCenterID 租用到的 dataCenterID，重新租用后可能变化
+func (l *Lease) DataCenterID() int64 {
+	slot, _ := l.current()
+	return slot / (maxWorkerID + 1)
+}
+
+// current 当前的槽位以及租约是否仍然有效
+func (l *Lease) current() (int64, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.slot, time.Now().Before(l.valid)
+}
+
+func (l *Lease) isLost() bool {
+	_, ok := l.current()
+	return !ok
+}
+
+// heartbeat 每 ttl/3 续约一次，租约丢失后改为重新租用
+func (l *Lease) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+			if l.isLost() {
+				l.reacquire(ctx)
+			} else {
+				l.renew(ctx)
+			}
+			cancel()
+		}
+	}
+}
+
+// renew 续约成功后延长有效期；槽位已不属于本节点时立即标记为丢失
+// Redis 出错时不改变有效期，到期后自动失效
+func (l *Lease) renew(ctx context.Context) {
+	l.mu.RLock()
+	key := l.key
+	l.mu.RUnlock()
+	sent := time.Now()
+	n, err := renewScript.Run(ctx, l.rdb, []string{key}, l.owner, l.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n == 1 {
+		l.valid = sent.Add(l.ttl - l.margin)
+	} else {
+		// 槽位已过期或被其他节点占用
+		l.valid = time.Time{}
+	}
+}
+
+// reacquire 重新租用，优先原来的槽位：key 仍属于本节点（如 Redis 短暂不可用）时直接续约，
+// 否则从原来的槽位开始占用；失败时等待下一次心跳
+func (l *Lease) reacquire(ctx context.Context) {
+	if l.renew(ctx); !l.isLost() {
+		return
+	}
+	l.mu.RLock()
+	slot := l.slot
+	l.mu.RUnlock()
+	_ = l.acquire(ctx, slot)
+}
+
+// Release 停止续约并释放槽位
+func (l *Lease) Release(ctx context.Context) error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.stop)
+		<-l.done
+		l.mu.Lock()
+		key := l.key
+		l.valid = time.Time{}
+		l.mu.Unlock()
+		err = releaseScript.Run(ctx, l.rdb, []string{key}, l.owner).Err()
+	})
+	return err
+}
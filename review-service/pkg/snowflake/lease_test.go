@@ -0,0 +1,109 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+const testLeaseTTL = 600 * time.Millisecond
+
+func newTestLease(t *testing.T) (*miniredis.Miniredis, *Lease, *Snowflake) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+	l, err := AcquireLease(context.Background(), rdb, "", testLeaseTTL)
+	if err != nil {
+		t.Fatalf("AcquireLease: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Release(context.Background()) })
+	sf, err := NewSnowflakeWithLease(l)
+	if err != nil {
+		t.Fatalf("NewSnowflakeWithLease: %v", err)
+	}
+	return mr, l, sf
+}
+
+// waitFor 在 timeout 内轮询 cond，超时返回 false
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+func TestLeaseStopsBeforeExpiry(t *testing.T) {
+	acquired := time.Now()
+	mr, l, sf := newTestLease(t)
+	slot, _ := l.current()
+	if _, err := sf.NextID(); err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	// Redis 不可用，无法续约
+	mr.Close()
+	if !waitFor(testLeaseTTL, func() bool { _, err := sf.NextID(); return errors.Is(err, ErrLeaseLost) }) {
+		t.Fatal("NextID should fail once the lease can not be renewed")
+	}
+	// 必须在 key 过期、其他节点可能占用槽位之前停止生成
+	if elapsed := time.Since(acquired); elapsed >= testLeaseTTL {
+		t.Fatalf("lease lost after %v, want before ttl %v", elapsed, testLeaseTTL)
+	}
+
+	// Redis 恢复后 key 仍属于本节点，继续使用原来的槽位
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("restart miniredis: %v", err)
+	}
+	if !waitFor(2*testLeaseTTL, func() bool { _, err := sf.NextID(); return err == nil }) {
+		t.Fatal("lease should be re-acquired after redis recovers")
+	}
+	if got, _ := l.current(); got != slot {
+		t.Fatalf("slot after recovery = %d, want %d", got, slot)
+	}
+}
+
+func TestLeaseTakeover(t *testing.T) {
+	mr, l, sf := newTestLease(t)
+	slot, _ := l.current()
+	oldKey := l.key
+
+	// key 过期后被其他节点占用
+	mr.FastForward(testLeaseTTL)
+	if mr.Exists(oldKey) {
+		t.Fatal("lease key should expire")
+	}
+	if err := mr.Set(oldKey, "other"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if !waitFor(testLeaseTTL, func() bool { _, err := sf.NextID(); return errors.Is(err, ErrLeaseLost) }) {
+		t.Fatal("NextID should fail after the slot is taken over")
+	}
+
+	// 重新租用到另一个槽位
+	if !waitFor(2*testLeaseTTL, func() bool { _, err := sf.NextID(); return err == nil }) {
+		t.Fatal("lease should be re-acquired on another slot")
+	}
+	got, _ := l.current()
+	if got == slot {
+		t.Fatalf("re-acquired slot %d is still held by another node", got)
+	}
+	if v, _ := mr.Get(oldKey); v != "other" {
+		t.Fatalf("taken over key = %q, want other", v)
+	}
+	id, err := sf.NextID()
+	if err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+	if w := (id >> workerIDShift) & maxWorkerID; w != got%(maxWorkerID+1) {
+		t.Fatalf("worker id in %d = %d, want %d", id, w, got%(maxWorkerID+1))
+	}
+}
@@ -2,6 +2,7 @@ package snowflake
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -21,6 +22,16 @@ const (
 
 	// 起始时间戳 (可以自己定义，比如项目上线时间)
 	twepoch = int64(1672531200000) // 2023-01-01 00:00:00
+
+	// 默认可容忍的时钟回拨时间，在此范围内等待时钟追上
+	defaultMaxBackwards = 10 * time.Millisecond
+)
+
+var (
+	// ErrClockBackwards 时钟回拨超过容忍范围
+	ErrClockBackwards = errors.New("snowflake: clock moved backwards")
+	// ErrLeaseLost workerID 租约已丢失，继续生成可能与其他节点冲突
+	ErrLeaseLost = errors.New("snowflake: worker id lease lost")
 )
 
 type Snowflake struct {
@@ -29,33 +40,79 @@ type Snowflake struct {
 	workerID     int64
 	dataCenterID int64
 	sequence     int64
+	maxBackwards time.Duration
+	lease        *Lease
+}
+
+// Option 雪花算法生成器配置项
+type Option func(*Snowflake)
+
+// WithMaxBackwards 设置可容忍的时钟回拨时间，超过后 NextID 返回 ErrClockBackwards
+func WithMaxBackwards(d time.Duration) Option {
+	return func(s *Snowflake) {
+		s.maxBackwards = d
+	}
+}
+
+// WithLease 绑定 workerID 租约，租约失效期间 NextID 返回 ErrLeaseLost，重新租用后恢复
+func WithLease(l *Lease) Option {
+	return func(s *Snowflake) {
+		s.lease = l
+	}
 }
 
 // NewSnowflake 创建一个新的Snowflake节点
-func NewSnowflake(workerID, dataCenterID int64) (*Snowflake, error) {
+func NewSnowflake(workerID, dataCenterID int64, opts ...Option) (*Snowflake, error) {
 	if workerID < 0 || workerID > maxWorkerID {
 		return nil, errors.New("workerID out of range")
 	}
 	if dataCenterID < 0 || dataCenterID > maxDataCenterID {
 		return nil, errors.New("dataCenterID out of range")
 	}
-	return &Snowflake{
+	s := &Snowflake{
 		workerID:     workerID,
 		dataCenterID: dataCenterID,
 		lastStamp:    -1,
 		sequence:     0,
-	}, nil
+		maxBackwards: defaultMaxBackwards,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// NewSnowflakeWithLease 使用租约中的 workerID 和 dataCenterID 创建节点
+func NewSnowflakeWithLease(l *Lease, opts ...Option) (*Snowflake, error) {
+	return NewSnowflake(l.WorkerID(), l.DataCenterID(), append(opts, WithLease(l))...)
 }
 
 // NextID 生成下一个唯一ID
-func (s *Snowflake) NextID() int64 {
+func (s *Snowflake) NextID() (int64, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	timestamp := time.Now().UnixNano() / 1e6 // 毫秒
+	if s.lease != nil {
+		// 租约可能在后台重新租用到了新的槽位
+		slot, ok := s.lease.current()
+		if !ok {
+			return 0, ErrLeaseLost
+		}
+		s.workerID, s.dataCenterID = slot%(maxWorkerID+1), slot/(maxWorkerID+1)
+	}
+
+	timestamp := currentMillis()
 	if timestamp < s.lastStamp {
-		// 处理时钟回拨
-		timestamp = s.lastStamp
+		// 处理时钟回拨：容忍范围内等待时钟追上，否则拒绝生成
+		offset := time.Duration(s.lastStamp-timestamp) * time.Millisecond
+		if offset > s.maxBackwards {
+			return 0, fmt.Errorf("%w: %v", ErrClockBackwards, offset)
+		}
+		time.Sleep(offset)
+		timestamp = currentMillis()
+		if timestamp < s.lastStamp {
+			return 0, fmt.Errorf("%w: %v", ErrClockBackwards, time.Duration(s.lastStamp-timestamp)*time.Millisecond)
+		}
 	}
 
 	if s.lastStamp == timestamp {
@@ -64,7 +121,7 @@ func (s *Snowflake) NextID() int64 {
 		if s.sequence == 0 {
 			// 序列号溢出，等待下一毫秒
 			for timestamp <= s.lastStamp {
-				timestamp = time.Now().UnixNano() / 1e6
+				timestamp = currentMillis()
 			}
 		}
 	} else {
@@ -77,5 +134,9 @@ func (s *Snowflake) NextID() int64 {
 	return ((timestamp - twepoch) << timestampLeftShift) |
 		(s.dataCenterID << dataCenterIDShift) |
 		(s.workerID << workerIDShift) |
-		s.sequence
+		s.sequence, nil
+}
+
+func currentMillis() int64 {
+	return time.Now().UnixNano() / 1e6 // 毫秒
 }